@@ -487,3 +487,73 @@ func Benchmark_Decode_LargeSlice_EscapedString_GoJson(b *testing.B) {
 		}
 	}
 }
+
+func Benchmark_Decode_LargeString_Unmarshal_EncodingJson(b *testing.B) {
+	b.SetBytes(int64(len(StringFixture)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		result := StringPayload{}
+		if err := json.Unmarshal(StringFixture, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_Decode_LargeString_Unmarshal_JsonIter(b *testing.B) {
+	b.SetBytes(int64(len(StringFixture)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		result := StringPayload{}
+		if err := jsoniter.Unmarshal(StringFixture, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_Decode_LargeString_Unmarshal_SegmentioJson(b *testing.B) {
+	b.SetBytes(int64(len(StringFixture)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		result := StringPayload{}
+		if err := segmentiojson.Unmarshal(StringFixture, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_Decode_LargeString_Unmarshal_GoJson(b *testing.B) {
+	b.SetBytes(int64(len(StringFixture)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		result := StringPayload{}
+		if err := gojson.Unmarshal(StringFixture, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_Decode_LargeString_Stream_EncodingJson(b *testing.B) {
+	reader := bytes.NewReader(StringFixture)
+	b.SetBytes(int64(len(StringFixture)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		result := StringPayload{}
+		reader.Reset(StringFixture)
+		if err := json.NewDecoder(reader).Decode(&result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_Decode_LargeString_Stream_GoJson(b *testing.B) {
+	reader := bytes.NewReader(StringFixture)
+	b.SetBytes(int64(len(StringFixture)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		result := StringPayload{}
+		reader.Reset(StringFixture)
+		if err := gojson.NewDecoder(reader).Decode(&result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -466,6 +466,73 @@ func Benchmark_Encode_LargeStruct_GoJsonNoEscape(b *testing.B) {
 	}
 }
 
+func Benchmark_Encode_LargeString_EncodingJson(b *testing.B) {
+	v := NewStringPayload()
+	b.SetBytes(int64(len(StringFixture)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_Encode_LargeString_JsonIter(b *testing.B) {
+	v := NewStringPayload()
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	b.SetBytes(int64(len(StringFixture)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_Encode_LargeString_SegmentioJson(b *testing.B) {
+	v := NewStringPayload()
+	b.SetBytes(int64(len(StringFixture)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := segmentiojson.Marshal(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_Encode_LargeString_GoJson(b *testing.B) {
+	v := NewStringPayload()
+	b.SetBytes(int64(len(StringFixture)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := gojson.Marshal(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_Encode_LargeString_GoJsonDisableHTMLEscape(b *testing.B) {
+	v := NewStringPayload()
+	b.SetBytes(int64(len(StringFixture)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := gojson.MarshalWithOption(v, gojson.DisableHTMLEscape()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_Encode_LargeString_GoJsonDisableNormalizeUTF8(b *testing.B) {
+	v := NewStringPayload()
+	b.SetBytes(int64(len(StringFixture)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := gojson.MarshalWithOption(v, gojson.DisableNormalizeUTF8()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func Benchmark_Encode_LargeStructCached_EncodingJson(b *testing.B) {
 	cached := NewLargePayload()
 	b.ReportAllocs()
@@ -0,0 +1,48 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// StringArticle is the string heavy value such as the post of the blog.
+type StringArticle struct {
+	ID      int      `json:"id"`
+	Title   string   `json:"title"`
+	Summary string   `json:"summary"`
+	Body    string   `json:"body"`
+	Tags    []string `json:"tags"`
+}
+
+type StringPayload struct {
+	Articles []*StringArticle `json:"articles"`
+}
+
+const stringPayloadSentence = "The quick brown fox jumps over the lazy dog while the encoder scans long plain text. "
+
+// NewStringPayload creates the payload that consists of the long strings.
+// Most of the bytes don't need to be escaped, and a few quotes, new lines and HTML characters are placed in them.
+func NewStringPayload() *StringPayload {
+	articles := make([]*StringArticle, 0, 100)
+	for i := 0; i < 100; i++ {
+		articles = append(articles, &StringArticle{
+			ID:      i,
+			Title:   "Article " + strconv.Itoa(i) + ": how to scan the strings 8 bytes at a time",
+			Summary: strings.Repeat(stringPayloadSentence, 4),
+			Body: strings.Repeat(stringPayloadSentence, 20) +
+				"\"quoted\" <b>bold</b> & more\n" +
+				strings.Repeat(stringPayloadSentence, 20),
+			Tags: []string{"performance", "string", "swar", "encoding", "decoding"},
+		})
+	}
+	return &StringPayload{Articles: articles}
+}
+
+var StringFixture = func() []byte {
+	b, err := json.Marshal(NewStringPayload())
+	if err != nil {
+		panic(err)
+	}
+	return b
+}()
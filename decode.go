@@ -1,6 +1,7 @@
 package json
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"github.com/goccy/go-json/internal/decoder"
 	"github.com/goccy/go-json/internal/errors"
 	"github.com/goccy/go-json/internal/runtime"
+	"github.com/goccy/go-json/internal/scanner"
 )
 
 type Decoder struct {
@@ -34,23 +36,26 @@ func unmarshal(data []byte, v interface{}, optFuncs ...DecodeOptionFunc) error {
 	if err := validateType(header.typ, uintptr(header.ptr)); err != nil {
 		return err
 	}
-	dec, err := decoder.CompileToGetDecoder(header.typ)
-	if err != nil {
-		return err
-	}
 	ctx := decoder.TakeRuntimeContext()
 	ctx.Buf = src
 	ctx.Option.Flags = 0
 	for _, optFunc := range optFuncs {
 		optFunc(ctx.Option)
 	}
+	dec, err := decoder.CompileToGetDecoderWithOption(header.typ, ctx.Option)
+	if err != nil {
+		decoder.ReleaseRuntimeContext(ctx)
+		return err
+	}
 	cursor, err := dec.Decode(ctx, 0, 0, header.ptr)
 	if err != nil {
+		err = takeErrors(ctx, src, err)
 		decoder.ReleaseRuntimeContext(ctx)
 		return err
 	}
+	err = takeErrors(ctx, src, validateEndBuf(src, cursor))
 	decoder.ReleaseRuntimeContext(ctx)
-	return validateEndBuf(src, cursor)
+	return err
 }
 
 func unmarshalContext(ctx context.Context, data []byte, v interface{}, optFuncs ...DecodeOptionFunc) error {
@@ -62,10 +67,6 @@ func unmarshalContext(ctx context.Context, data []byte, v interface{}, optFuncs
 	if err := validateType(header.typ, uintptr(header.ptr)); err != nil {
 		return err
 	}
-	dec, err := decoder.CompileToGetDecoder(header.typ)
-	if err != nil {
-		return err
-	}
 	rctx := decoder.TakeRuntimeContext()
 	rctx.Buf = src
 	rctx.Option.Flags = 0
@@ -74,22 +75,41 @@ func unmarshalContext(ctx context.Context, data []byte, v interface{}, optFuncs
 	for _, optFunc := range optFuncs {
 		optFunc(rctx.Option)
 	}
+	dec, err := decoder.CompileToGetDecoderWithOption(header.typ, rctx.Option)
+	if err != nil {
+		decoder.ReleaseRuntimeContext(rctx)
+		return err
+	}
 	cursor, err := dec.Decode(rctx, 0, 0, header.ptr)
 	if err != nil {
+		err = takeErrors(rctx, src, err)
 		decoder.ReleaseRuntimeContext(rctx)
 		return err
 	}
+	err = takeErrors(rctx, src, validateEndBuf(src, cursor))
 	decoder.ReleaseRuntimeContext(rctx)
-	return validateEndBuf(src, cursor)
+	return err
 }
 
 var (
 	pathDecoder = decoder.NewPathDecoder()
 )
 
-func extractFromPath(path *Path, data []byte, optFuncs ...DecodeOptionFunc) ([][]byte, error) {
-	if path.path.RootSelectorOnly {
-		return [][]byte{data}, nil
+func extractFromPath(path *decoder.Path, data []byte, optFuncs ...DecodeOptionFunc) ([][]byte, error) {
+	if path.RFC9535 && len(optFuncs) > 0 {
+		// the values are selected by RFC 9535 evaluation that doesn't decode them, so no option can be applied.
+		return nil, fmt.Errorf("json: decode options are not supported by the path created with PathRFC9535 option")
+	}
+	if path.RootSelectorOnly {
+		// the document isn't scanned to select the root, so it is validated here.
+		if err := scanner.Validate(data); err != nil {
+			return nil, err
+		}
+		return [][]byte{bytes.Trim(data, " \t\r\n")}, nil
+	}
+	if path.RFC9535 {
+		contents, _, err := extractWithPaths(path, data)
+		return contents, err
 	}
 	src := make([]byte, len(data)+1) // append nul byte to the end
 	copy(src, data)
@@ -98,7 +118,7 @@ func extractFromPath(path *Path, data []byte, optFuncs ...DecodeOptionFunc) ([][
 	ctx.Buf = src
 	ctx.Option.Flags = 0
 	ctx.Option.Flags |= decoder.PathOption
-	ctx.Option.Path = path.path
+	ctx.Option.Path = path
 	for _, optFunc := range optFuncs {
 		optFunc(ctx.Option)
 	}
@@ -114,6 +134,44 @@ func extractFromPath(path *Path, data []byte, optFuncs ...DecodeOptionFunc) ([][
 	return paths, nil
 }
 
+func extractWithPaths(path *decoder.Path, data []byte) ([][]byte, []string, error) {
+	src := make([]byte, len(data)+1) // append nul byte to the end
+	copy(src, data)
+
+	contents, paths, cursor, err := path.ExtractWithPaths(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validateEndBuf(src, cursor); err != nil {
+		return nil, nil, err
+	}
+	return contents, paths, nil
+}
+
+// extractStreamFromPath calls fn with each value selected by path while reading r.
+func extractStreamFromPath(path *decoder.Path, r io.Reader, fn func([]byte) error) error {
+	s := decoder.NewStream(r)
+	if err := path.ExtractStream(s, fn); err != nil {
+		return s.LocateError(err)
+	}
+	return nil
+}
+
+// editFromPath applies the edit to the copy of data, and validates the rest of data.
+func editFromPath(data []byte, edit func(src []byte) ([]byte, int64, error)) ([]byte, error) {
+	src := make([]byte, len(data)+1) // append nul byte to the end
+	copy(src, data)
+
+	edited, cursor, err := edit(src)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateEndBuf(src, cursor); err != nil {
+		return nil, err
+	}
+	return edited, nil
+}
+
 func unmarshalNoEscape(data []byte, v interface{}, optFuncs ...DecodeOptionFunc) error {
 	src := make([]byte, len(data)+1) // append nul byte to the end
 	copy(src, data)
@@ -123,24 +181,26 @@ func unmarshalNoEscape(data []byte, v interface{}, optFuncs ...DecodeOptionFunc)
 	if err := validateType(header.typ, uintptr(header.ptr)); err != nil {
 		return err
 	}
-	dec, err := decoder.CompileToGetDecoder(header.typ)
-	if err != nil {
-		return err
-	}
-
 	ctx := decoder.TakeRuntimeContext()
 	ctx.Buf = src
 	ctx.Option.Flags = 0
 	for _, optFunc := range optFuncs {
 		optFunc(ctx.Option)
 	}
+	dec, err := decoder.CompileToGetDecoderWithOption(header.typ, ctx.Option)
+	if err != nil {
+		decoder.ReleaseRuntimeContext(ctx)
+		return err
+	}
 	cursor, err := dec.Decode(ctx, 0, 0, noescape(header.ptr))
 	if err != nil {
+		err = takeErrors(ctx, src, err)
 		decoder.ReleaseRuntimeContext(ctx)
 		return err
 	}
+	err = takeErrors(ctx, src, validateEndBuf(src, cursor))
 	decoder.ReleaseRuntimeContext(ctx)
-	return validateEndBuf(src, cursor)
+	return err
 }
 
 func validateEndBuf(src []byte, cursor int64) error {
@@ -152,11 +212,43 @@ func validateEndBuf(src []byte, cursor int64) error {
 		case nul:
 			return nil
 		}
-		return errors.ErrSyntax(
+		err := errors.ErrSyntax(
 			fmt.Sprintf("invalid character '%c' after top-level value", src[cursor]),
 			cursor+1,
 		)
+		// Offset points after the offending character, so locate the character itself.
+		return newSource(src).LocateAt(err, cursor)
+	}
+}
+
+// locateError sets the line and column of err in src that ends with nul byte.
+func locateError(src []byte, err error) error {
+	if err == nil {
+		return nil
 	}
+	return newSource(src).Locate(err)
+}
+
+// takeErrors returns err with the errors collected by DecodeCollectErrors option.
+// With the option, any error is returned as *UnmarshalErrors even if it is the only one.
+// The collected errors are removed from ctx.
+func takeErrors(ctx *decoder.RuntimeContext, src []byte, err error) error {
+	errs := ctx.Option.Errors
+	if ctx.Option.Flags&decoder.CollectErrorsOption == 0 || (len(errs) == 0 && err == nil) {
+		return locateError(src, err)
+	}
+	ctx.Option.Errors = nil
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, e := range errs {
+		_ = locateError(src, e)
+	}
+	return &UnmarshalErrors{Errors: errs}
+}
+
+func newSource(src []byte) *errors.Source {
+	return &errors.Source{Buf: src[:len(src)-1], Line: 1}
 }
 
 //nolint:staticcheck
@@ -219,19 +311,19 @@ func (d *Decoder) DecodeWithOption(v interface{}, optFuncs ...DecodeOptionFunc)
 		return err
 	}
 
-	dec, err := decoder.CompileToGetDecoder(typ)
+	s := d.s
+	for _, optFunc := range optFuncs {
+		optFunc(s.Option)
+	}
+	dec, err := decoder.CompileToGetDecoderWithOption(typ, s.Option)
 	if err != nil {
 		return err
 	}
-	if err := d.s.PrepareForDecode(); err != nil {
+	if err := s.PrepareForDecode(); err != nil {
 		return err
 	}
-	s := d.s
-	for _, optFunc := range optFuncs {
-		optFunc(s.Option)
-	}
 	if err := dec.DecodeStream(s, 0, header.ptr); err != nil {
-		return err
+		return s.LocateError(err)
 	}
 	s.Reset()
 	return nil
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"io"
 	"math"
 	"math/big"
 	"net"
@@ -15,6 +16,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 	"unsafe"
 
@@ -289,6 +291,1073 @@ func Test_Decoder_DisallowUnknownFields(t *testing.T) {
 	}
 }
 
+func Test_DecodeUseNumber(t *testing.T) {
+	t.Run("unmarshal", func(t *testing.T) {
+		var v map[string]interface{}
+		assertErr(t, json.UnmarshalWithOption([]byte(`{"a": 3.14, "b": [1, {"c": 2}]}`), &v, json.DecodeUseNumber()))
+		assertEq(t, "json.Number", json.Number("3.14"), v["a"])
+		b := v["b"].([]interface{})
+		assertEq(t, "json.Number", json.Number("1"), b[0])
+		assertEq(t, "json.Number", json.Number("2"), b[1].(map[string]interface{})["c"])
+	})
+	t.Run("stream", func(t *testing.T) {
+		dec := json.NewDecoder(strings.NewReader(`{"a": 3.14}`))
+		var v interface{}
+		assertErr(t, dec.DecodeWithOption(&v, json.DecodeUseNumber()))
+		assertEq(t, "json.Number", json.Number("3.14"), v.(map[string]interface{})["a"])
+	})
+}
+
+func Test_DecodeDisallowUnknownFields(t *testing.T) {
+	type T struct {
+		A int `json:"a"`
+		B struct {
+			C int `json:"c"`
+		} `json:"b"`
+	}
+	type LargeT struct {
+		A, B, C, D, E, F, G, H, I, J, K, L, M, N, O, P, Q int
+	}
+	tests := []struct {
+		name     string
+		data     string
+		v        interface{}
+		expected string
+	}{
+		{
+			name:     "top level",
+			data:     `{"a": 1, "x": 1}`,
+			v:        &T{},
+			expected: `json: unknown field "x"`,
+		},
+		{
+			name:     "nested",
+			data:     `{"a": 1, "b": {"c": 1, "d": 2}}`,
+			v:        &T{},
+			expected: `json: unknown field "d"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := json.UnmarshalWithOption([]byte(test.data), test.v, json.DecodeDisallowUnknownFields())
+			if err == nil {
+				t.Fatal("expected unknown field error")
+			}
+			assertEq(t, "unknown field error", test.expected, err.Error())
+
+			err = json.NewDecoder(strings.NewReader(test.data)).DecodeWithOption(test.v, json.DecodeDisallowUnknownFields())
+			if err == nil {
+				t.Fatal("expected unknown field error")
+			}
+			assertEq(t, "unknown field error", test.expected, err.Error())
+		})
+	}
+	t.Run("escaped key", func(t *testing.T) {
+		for _, v := range []interface{}{&T{}, &LargeT{}} {
+			err := json.UnmarshalWithOption([]byte(`{"a": 1, "\u0078y": 1}`), v, json.DecodeDisallowUnknownFields())
+			if err == nil {
+				t.Fatal("expected unknown field error")
+			}
+			assertEq(t, "unknown field error", `json: unknown field "xy"`, err.Error())
+		}
+	})
+	t.Run("known fields", func(t *testing.T) {
+		var v T
+		assertErr(t, json.UnmarshalWithOption([]byte(`{"a": 1, "B": {"C": 2}}`), &v, json.DecodeDisallowUnknownFields()))
+		assertEq(t, "a", 1, v.A)
+		assertEq(t, "c", 2, v.B.C)
+	})
+}
+
+func Test_DecodeCaseSensitive(t *testing.T) {
+	type Embedded struct {
+		Name string
+	}
+	type T struct {
+		ID int `json:"id"`
+		Embedded
+	}
+	type Strict struct {
+		ID   int    `json:"id,strictcase"`
+		Name string `json:"name"`
+	}
+	type LargeT struct {
+		A, B, C, D, E, F, G, H, I, J int
+		ID                           int `json:"id"`
+	}
+	type FallbackT struct {
+		A, B, C, D, E, F, G, H, I, J, K, L, M, N, O, P int
+		ID                                             int `json:"id"`
+	}
+	type Distinct struct {
+		Upper int `json:"ID"`
+		Lower int `json:"id"`
+	}
+	decode := func(t *testing.T, data string, v interface{}, opts ...json.DecodeOptionFunc) {
+		t.Helper()
+		assertErr(t, json.UnmarshalWithOption([]byte(data), v, opts...))
+	}
+	decodeStream := func(t *testing.T, data string, v interface{}, opts ...json.DecodeOptionFunc) {
+		t.Helper()
+		assertErr(t, json.NewDecoder(strings.NewReader(data)).DecodeWithOption(v, opts...))
+	}
+	for name, decode := range map[string]func(*testing.T, string, interface{}, ...json.DecodeOptionFunc){
+		"buffer": decode,
+		"stream": decodeStream,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Run("option", func(t *testing.T) {
+				var v T
+				decode(t, `{"ID":1,"id":2,"NAME":"a","Name":"b"}`, &v, json.DecodeCaseSensitive())
+				assertEq(t, "id", 2, v.ID)
+				assertEq(t, "name", "b", v.Name)
+			})
+			t.Run("large", func(t *testing.T) {
+				var v LargeT
+				decode(t, `{"ID":1,"j":1,"id":2,"J":3}`, &v, json.DecodeCaseSensitive())
+				assertEq(t, "id", 2, v.ID)
+				assertEq(t, "j", 3, v.J)
+
+				var folded LargeT
+				decode(t, `{"id":2,"ID":1,"j":3}`, &folded)
+				assertEq(t, "folded id", 1, folded.ID)
+				assertEq(t, "folded j", 3, folded.J)
+			})
+			t.Run("fallback", func(t *testing.T) {
+				var v FallbackT
+				decode(t, `{"ID":1,"p":1,"id":2,"P":3}`, &v, json.DecodeCaseSensitive())
+				assertEq(t, "id", 2, v.ID)
+				assertEq(t, "p", 3, v.P)
+			})
+			t.Run("distinct fields", func(t *testing.T) {
+				var v Distinct
+				decode(t, `{"ID":1,"id":2}`, &v, json.DecodeCaseSensitive())
+				assertEq(t, "upper", 1, v.Upper)
+				assertEq(t, "lower", 2, v.Lower)
+			})
+			t.Run("strictcase tag", func(t *testing.T) {
+				var v Strict
+				decode(t, `{"ID":1,"Id":2,"NAME":"a"}`, &v)
+				assertEq(t, "id", 0, v.ID)
+				assertEq(t, "name", "a", v.Name)
+				decode(t, `{"id":3}`, &v)
+				assertEq(t, "exact id", 3, v.ID)
+			})
+		})
+	}
+	t.Run("disallow unknown fields", func(t *testing.T) {
+		var v T
+		err := json.UnmarshalWithOption([]byte(`{"Id":1}`), &v, json.DecodeCaseSensitive(), json.DecodeDisallowUnknownFields())
+		if err == nil {
+			t.Fatal("expected unknown field error")
+		}
+		assertEq(t, "unknown field error", `json: unknown field "Id"`, err.Error())
+	})
+}
+
+func Test_DecodeDisallowDuplicateKeys(t *testing.T) {
+	type T struct {
+		ID   int `json:"id"`
+		Name string
+	}
+	type LargeT struct {
+		A, B, C, D, E, F, G, H, I, J, K, L, M, N, O, P int
+		ID                                             int `json:"id"`
+	}
+	type Distinct struct {
+		Upper int `json:"ID"`
+		Lower int `json:"id"`
+	}
+	decode := func(data string, v interface{}, opts ...json.DecodeOptionFunc) error {
+		return json.UnmarshalWithOption([]byte(data), v, opts...)
+	}
+	decodeStream := func(data string, v interface{}, opts ...json.DecodeOptionFunc) error {
+		return json.NewDecoder(strings.NewReader(data)).DecodeWithOption(v, opts...)
+	}
+	assertDuplicateKey := func(t *testing.T, err error, key string, offset int64) {
+		t.Helper()
+		var dupErr *json.DuplicateKeyError
+		if !errors.As(err, &dupErr) {
+			t.Fatalf("expected DuplicateKeyError but got %v", err)
+		}
+		assertEq(t, "key", key, dupErr.Key)
+		assertEq(t, "offset", offset, dupErr.Offset)
+	}
+	for name, decode := range map[string]func(string, interface{}, ...json.DecodeOptionFunc) error{
+		"buffer": decode,
+		"stream": decodeStream,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Run("struct", func(t *testing.T) {
+				var v T
+				err := decode(`{"id":1,"Name":"a", "id":2}`, &v, json.DecodeDisallowDuplicateKeys())
+				assertDuplicateKey(t, err, "id", 20)
+
+				err = decode(`{"ID":1,"id":2}`, &v, json.DecodeDisallowDuplicateKeys())
+				assertDuplicateKey(t, err, "id", 8)
+
+				err = decode(`{"ID":1,"id":2}`, &v, json.DecodeDisallowDuplicateKeys(), json.DecodeFieldPriorityFirstWin())
+				assertDuplicateKey(t, err, "id", 8)
+
+				var ok T
+				assertErr(t, decode(`{"id":1,"Name":"a","x":1,"x":2}`, &ok, json.DecodeDisallowDuplicateKeys()))
+				assertEq(t, "id", 1, ok.ID)
+				assertErr(t, decode(`{"id":1,"id":2}`, &ok))
+				assertEq(t, "last wins", 2, ok.ID)
+			})
+			t.Run("large struct", func(t *testing.T) {
+				var v LargeT
+				assertErr(t, decode(`{"A":1,"P":2,"id":3}`, &v, json.DecodeDisallowDuplicateKeys()))
+				err := decode(`{"A":1,"P":2,"id":3,"p":4}`, &v, json.DecodeDisallowDuplicateKeys())
+				assertDuplicateKey(t, err, "P", 20)
+			})
+			t.Run("case sensitive", func(t *testing.T) {
+				var v Distinct
+				assertErr(t, decode(`{"ID":1,"id":2}`, &v, json.DecodeDisallowDuplicateKeys(), json.DecodeCaseSensitive()))
+				assertEq(t, "upper", 1, v.Upper)
+				assertEq(t, "lower", 2, v.Lower)
+				err := decode(`{"ID":1,"id":2,"ID":3}`, &v, json.DecodeDisallowDuplicateKeys(), json.DecodeCaseSensitive())
+				assertDuplicateKey(t, err, "ID", 15)
+			})
+			t.Run("map", func(t *testing.T) {
+				var v map[string]int
+				err := decode(`{"a":1,"b":2,"a":3}`, &v, json.DecodeDisallowDuplicateKeys())
+				assertDuplicateKey(t, err, "a", 13)
+
+				var ints map[int]bool
+				err = decode(`{"1":true,"1":false}`, &ints, json.DecodeDisallowDuplicateKeys())
+				assertDuplicateKey(t, err, "1", 10)
+
+				// the keys of the existing map are not duplicates.
+				m := map[string]int{"a": 1}
+				assertErr(t, decode(`{"a":2}`, &m, json.DecodeDisallowDuplicateKeys()))
+				assertEq(t, "merged", 2, m["a"])
+			})
+			t.Run("interface", func(t *testing.T) {
+				var v interface{}
+				err := decode(`[{"a":1},{"a":{"b":1,"b":2}}]`, &v, json.DecodeDisallowDuplicateKeys())
+				assertDuplicateKey(t, err, "b", 21)
+				assertErr(t, decode(`{"a":1,"b":{"a":2}}`, &v, json.DecodeDisallowDuplicateKeys()))
+			})
+			t.Run("ordered map", func(t *testing.T) {
+				var v json.OrderedMap[string, int]
+				err := decode(`{"a":1,"a":2}`, &v, json.DecodeDisallowDuplicateKeys())
+				assertDuplicateKey(t, err, "a", 7)
+			})
+		})
+	}
+	t.Run("location", func(t *testing.T) {
+		var v map[string]int
+		err := json.UnmarshalWithOption([]byte("{\n  \"a\": 1,\n  \"a\": 2\n}"), &v, json.DecodeDisallowDuplicateKeys())
+		var dupErr *json.DuplicateKeyError
+		if !errors.As(err, &dupErr) {
+			t.Fatalf("expected DuplicateKeyError but got %v", err)
+		}
+		assertEq(t, "line", 3, dupErr.Line)
+		assertEq(t, "column", 3, dupErr.Column)
+		assertEq(t, "error", `json: duplicate key "a"`, err.Error())
+	})
+}
+
+func Test_UnknownFields(t *testing.T) {
+	type T struct {
+		ID    int                        `json:"id"`
+		Extra map[string]json.RawMessage `json:",unknown"`
+		Name  string                     `json:"name"`
+	}
+	type Embedded struct {
+		Extra map[string]interface{} `json:",unknown"`
+	}
+	type Outer struct {
+		A int
+		Embedded
+		B string
+	}
+	decode := func(data string, v interface{}) error {
+		return json.Unmarshal([]byte(data), v)
+	}
+	decodeStream := func(data string, v interface{}) error {
+		return json.NewDecoder(strings.NewReader(data)).Decode(v)
+	}
+	for name, decode := range map[string]func(string, interface{}) error{
+		"buffer": decode,
+		"stream": decodeStream,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Run("round trip", func(t *testing.T) {
+				var v T
+				assertErr(t, decode(`{"z":[1, 2],"id":1,"aA":{"b":null},"Na":true,"name":"x","Extra":1}`, &v))
+				assertEq(t, "id", 1, v.ID)
+				assertEq(t, "name", "x", v.Name)
+				assertEq(t, "len", 4, len(v.Extra))
+				assertEq(t, "raw", `[1, 2]`, string(v.Extra["z"]))
+				assertEq(t, "unescaped", `{"b":null}`, string(v.Extra["aA"]))
+				assertEq(t, "prefix of field", `true`, string(v.Extra["Na"]))
+				assertEq(t, "field name", `1`, string(v.Extra["Extra"]))
+
+				got, err := json.Marshal(v)
+				assertErr(t, err)
+				assertEq(t, "marshal", `{"id":1,"name":"x","Extra":1,"Na":true,"aA":{"b":null},"z":[1,2]}`, string(got))
+			})
+			t.Run("embedded", func(t *testing.T) {
+				var v Outer
+				assertErr(t, decode(`{"A":1,"x":"y","B":"b"}`, &v))
+				assertEq(t, "extra", "y", v.Extra["x"])
+
+				got, err := json.Marshal(&v)
+				assertErr(t, err)
+				assertEq(t, "marshal", `{"A":1,"B":"b","x":"y"}`, string(got))
+			})
+			t.Run("disallow unknown fields", func(t *testing.T) {
+				var v T
+				assertErr(t, json.UnmarshalWithOption([]byte(`{"id":1,"x":2}`), &v, json.DecodeDisallowUnknownFields()))
+				assertEq(t, "extra", `2`, string(v.Extra["x"]))
+			})
+		})
+	}
+	t.Run("empty", func(t *testing.T) {
+		got, err := json.Marshal(T{ID: 1})
+		assertErr(t, err)
+		assertEq(t, "nil", `{"id":1,"name":""}`, string(got))
+
+		got, err = json.Marshal(Embedded{Extra: map[string]interface{}{}})
+		assertErr(t, err)
+		assertEq(t, "only unknown", `{}`, string(got))
+	})
+	t.Run("indent", func(t *testing.T) {
+		v := Outer{A: 1, Embedded: Embedded{Extra: map[string]interface{}{"y": []int{1}, "x": nil}}}
+		got, err := json.MarshalIndent(v, "", "  ")
+		assertErr(t, err)
+		expected := `{
+  "A": 1,
+  "B": "",
+  "x": null,
+  "y": [
+    1
+  ]
+}`
+
+		assertEq(t, "indent", expected, string(got))
+	})
+	t.Run("keys of fields", func(t *testing.T) {
+		// the entries that have the keys of the fields are not written, so the keys are unique.
+		extra := map[string]json.RawMessage{"id": json.RawMessage(`2`), "name": json.RawMessage(`"y"`), "z": json.RawMessage(`3`)}
+		got, err := json.Marshal(T{ID: 1, Extra: extra})
+		assertErr(t, err)
+		assertEq(t, "marshal", `{"id":1,"name":"","z":3}`, string(got))
+		assertEq(t, "map", 3, len(extra))
+
+		got, err = json.Marshal(Outer{A: 1, Embedded: Embedded{Extra: map[string]interface{}{"A": 2, "B": "c"}}})
+		assertErr(t, err)
+		assertEq(t, "embedded", `{"A":1,"B":""}`, string(got))
+
+		got, err = json.MarshalIndent(&Outer{A: 1, Embedded: Embedded{Extra: map[string]interface{}{"A": 2, "x": 3}}}, "", " ")
+		assertErr(t, err)
+		assertEq(t, "indent", "{\n \"A\": 1,\n \"B\": \"\",\n \"x\": 3\n}", string(got))
+	})
+	t.Run("invalid type", func(t *testing.T) {
+		type Invalid struct {
+			Extra map[int]int `json:",unknown"`
+		}
+		var v Invalid
+		if err := json.Unmarshal([]byte(`{}`), &v); err == nil {
+			t.Fatal("expected error")
+		}
+		if _, err := json.Marshal(v); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+func Test_InlineFields(t *testing.T) {
+	type Meta struct {
+		Kind    string `json:"kind"`
+		Version int    `json:"version,omitempty"`
+	}
+	type Info struct {
+		Note string `json:"note"`
+	}
+	type T struct {
+		ID    int               `json:"id"`
+		Meta  Meta              `json:",inline"`
+		Info  *Info             `json:"ignored,inline"`
+		Extra map[string]string `json:",inline"`
+	}
+	type U struct {
+		Meta Meta   `json:",inline"`
+		Kind string `json:"kind"`
+	}
+	decode := func(data string, v interface{}) error {
+		return json.Unmarshal([]byte(data), v)
+	}
+	decodeStream := func(data string, v interface{}) error {
+		return json.NewDecoder(strings.NewReader(data)).Decode(v)
+	}
+	for name, decode := range map[string]func(string, interface{}) error{
+		"buffer": decode,
+		"stream": decodeStream,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var v T
+			assertErr(t, decode(`{"id":1,"kind":"a","version":2,"x":"y","note":"n","Meta":"m"}`, &v))
+			assertEq(t, "id", 1, v.ID)
+			assertEq(t, "kind", "a", v.Meta.Kind)
+			assertEq(t, "version", 2, v.Meta.Version)
+			assertEq(t, "note", "n", v.Info.Note)
+			assertEq(t, "extra", "y", v.Extra["x"])
+			assertEq(t, "field name", "m", v.Extra["Meta"])
+
+			var u U
+			assertErr(t, decode(`{"kind":"a"}`, &u))
+			assertEq(t, "outer field", "a", u.Kind)
+			assertEq(t, "inner field", "", u.Meta.Kind)
+		})
+	}
+	t.Run("marshal", func(t *testing.T) {
+		got, err := json.Marshal(T{ID: 1, Meta: Meta{Kind: "a"}, Extra: map[string]string{"x": "y"}})
+		assertErr(t, err)
+		assertEq(t, "value", `{"id":1,"kind":"a","x":"y"}`, string(got))
+
+		got, err = json.Marshal(&T{Meta: Meta{Kind: "a", Version: 3}, Info: &Info{Note: "n"}})
+		assertErr(t, err)
+		assertEq(t, "pointer", `{"id":0,"kind":"a","version":3,"note":"n"}`, string(got))
+
+		got, err = json.Marshal(U{Meta: Meta{Kind: "a", Version: 1}, Kind: "b"})
+		assertErr(t, err)
+		assertEq(t, "conflict", `{"version":1,"kind":"b"}`, string(got))
+	})
+	t.Run("invalid type", func(t *testing.T) {
+		type V struct {
+			A int `json:",inline"`
+		}
+		if _, err := json.Marshal(V{}); err == nil {
+			t.Fatal("expected error")
+		}
+		var v V
+		if err := json.Unmarshal([]byte(`{}`), &v); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func Test_FormatOption(t *testing.T) {
+	type T struct {
+		Unix      time.Time      `json:"unix,format:unix"`
+		UnixMilli time.Time      `json:"unixmilli,format:unixmilli"`
+		Hex       []byte         `json:"hex,format:hex"`
+		Base64URL []byte         `json:"base64url,format:base64url"`
+		Units     time.Duration  `json:"units,format:units"`
+		Ptr       *time.Time     `json:"ptr,format:unix"`
+		OmitEmpty *time.Duration `json:"omitempty,format:units,omitempty"`
+		Empty     []byte         `json:"empty,format:hex,omitempty"`
+	}
+	tm := time.Unix(1700000000, 123456789)
+	d := 90 * time.Minute
+	v := T{
+		Unix:      tm,
+		UnixMilli: tm,
+		Hex:       []byte{0xde, 0xad, 0xbe, 0xef},
+		Base64URL: []byte{0xfb, 0xff},
+		Units:     d,
+		Ptr:       &tm,
+	}
+	expected := `{"unix":1700000000,"unixmilli":1700000000123,"hex":"deadbeef","base64url":"-_8=","units":"1h30m0s","ptr":1700000000}`
+	t.Run("marshal", func(t *testing.T) {
+		got, err := json.Marshal(v)
+		assertErr(t, err)
+		assertEq(t, "value", expected, string(got))
+
+		got, err = json.Marshal(&T{OmitEmpty: &d})
+		assertErr(t, err)
+		assertEq(t, "zero", `{"unix":-62135596800,"unixmilli":-62135596800000,"hex":null,"base64url":null,"units":"0s","ptr":null,"omitempty":"1h30m0s"}`, string(got))
+
+		type One struct {
+			Ptr *time.Time `json:"ptr,format:unixmilli"`
+		}
+		got, err = json.Marshal([]One{{Ptr: &tm}, {}})
+		assertErr(t, err)
+		assertEq(t, "only one pointer field", `[{"ptr":1700000000123},{"ptr":null}]`, string(got))
+
+		got, err = json.Marshal(One{Ptr: &tm})
+		assertErr(t, err)
+		assertEq(t, "not indirect", `{"ptr":1700000000123}`, string(got))
+
+		type OmitZero struct {
+			Units time.Duration `json:"units,format:units,omitzero"`
+			Unix  time.Time     `json:"unix,format:unix,omitzero"`
+		}
+		got, err = json.Marshal(OmitZero{})
+		assertErr(t, err)
+		assertEq(t, "omitzero", `{}`, string(got))
+
+		// the format doesn't change the empty value of omitempty, and the struct is never empty.
+		type OmitEmptyStruct struct {
+			Plain time.Time     `json:"plain,omitempty"`
+			Unix  time.Time     `json:"unix,format:unix,omitempty"`
+			Both  time.Time     `json:"both,format:unix,omitempty,omitzero"`
+			Units time.Duration `json:"units,format:units,omitempty"`
+		}
+		got, err = json.Marshal(OmitEmptyStruct{})
+		assertErr(t, err)
+		assertEq(t, "omitempty struct", `{"plain":"0001-01-01T00:00:00Z","unix":-62135596800}`, string(got))
+	})
+	decode := func(data string, v interface{}) error {
+		return json.Unmarshal([]byte(data), v)
+	}
+	decodeStream := func(data string, v interface{}) error {
+		return json.NewDecoder(strings.NewReader(data)).Decode(v)
+	}
+	for name, decode := range map[string]func(string, interface{}) error{
+		"buffer": decode,
+		"stream": decodeStream,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var got T
+			assertErr(t, decode(`{"unix":1700000000,"unixmilli":1700000000123,"hex":"DEADbeef","base64url":"-_8","units":"1h30m","ptr":1700000000,"omitempty":"2s"}`, &got))
+			assertEq(t, "unix", time.Unix(1700000000, 0).UTC(), got.Unix)
+			assertEq(t, "unixmilli", time.Unix(1700000000, 123000000).UTC(), got.UnixMilli)
+			assertEq(t, "hex", string(v.Hex), string(got.Hex))
+			assertEq(t, "base64url", string(v.Base64URL), string(got.Base64URL))
+			assertEq(t, "units", d, got.Units)
+			assertEq(t, "ptr", time.Unix(1700000000, 0).UTC(), *got.Ptr)
+			assertEq(t, "omitempty", 2*time.Second, *got.OmitEmpty)
+
+			if err := decode(`{"units":"1x"}`, &got); err == nil {
+				t.Fatal("expected error")
+			}
+			if err := decode(`{"hex":"xyz"}`, &got); err == nil {
+				t.Fatal("expected error")
+			}
+
+			assertErr(t, decode(`{"unix":1700000000.5,"unixmilli":-1700000000123.25,"ptr":17e8}`, &got))
+			assertEq(t, "fractional unix", time.Unix(1700000000, 500000000).UTC(), got.Unix)
+			assertEq(t, "fractional unixmilli", time.Unix(-1700000000, -123250000).UTC(), got.UnixMilli)
+			assertEq(t, "exponent", time.Unix(1700000000, 0).UTC(), *got.Ptr)
+
+			for _, tc := range []struct {
+				data    string
+				err     string
+				pointer string
+			}{
+				{`{"hex":"xyz"}`, `json: cannot unmarshal string "xyz" into Go struct field T.Hex of type []uint8`, "/hex"},
+				{`{"base64url":"-_8*"}`, `json: cannot unmarshal string "-_8*" into Go struct field T.Base64URL of type []uint8`, "/base64url"},
+				{`{"unix":1.}`, `json: cannot unmarshal number 1. into Go struct field T.Unix of type time.Time`, "/unix"},
+				{`{"unix":"2020"}`, `json: cannot unmarshal string into Go struct field T.Unix of type time.Time`, "/unix"},
+				{`{"unix": true}`, `json: cannot unmarshal bool into Go struct field T.Unix of type time.Time`, "/unix"},
+				{`{"unixmilli":[1]}`, `json: cannot unmarshal array into Go struct field T.UnixMilli of type time.Time`, "/unixmilli"},
+				{`{"ptr":{}}`, `json: cannot unmarshal object into Go struct field T.Ptr of type time.Time`, "/ptr"},
+			} {
+				var v T
+				err := decode(tc.data, &v)
+				var typeErr *json.UnmarshalTypeError
+				if !errors.As(err, &typeErr) {
+					t.Fatalf("unexpected error for %s: %v", tc.data, err)
+				}
+				assertEq(t, "error", tc.err, err.Error())
+				assertEq(t, "pointer", tc.pointer, typeErr.Pointer)
+				assertEq(t, "unix", time.Time{}, v.Unix)
+			}
+		})
+	}
+	t.Run("invalid format", func(t *testing.T) {
+		type V struct {
+			A int `json:",format:unix"`
+		}
+		if _, err := json.Marshal(V{}); err == nil {
+			t.Fatal("expected error")
+		}
+		var v V
+		if err := json.Unmarshal([]byte(`{}`), &v); err == nil {
+			t.Fatal("expected error")
+		}
+		type W struct {
+			A time.Time `json:",format:rfc1123"`
+		}
+		if _, err := json.Marshal(W{}); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+type unmarshalFuncRegistered struct {
+	V int
+}
+
+func TestUnmarshalFunc(t *testing.T) {
+	celsius := json.UnmarshalFunc(func(data []byte, v *marshalFuncCelsius) error {
+		if string(data) == "null" {
+			*v = -273.15
+			return nil
+		}
+		s, err := strconv.Unquote(string(data))
+		if err != nil {
+			return err
+		}
+		f, err := strconv.ParseFloat(strings.TrimSuffix(s, "C"), 64)
+		if err != nil {
+			return err
+		}
+		*v = marshalFuncCelsius(f)
+		return nil
+	})
+	type T struct {
+		A marshalFuncCelsius            `json:"a"`
+		B *marshalFuncCelsius           `json:"b"`
+		C marshalFuncCelsius            `json:"c"`
+		D map[string]marshalFuncCelsius `json:"d"`
+		E []*marshalFuncCelsius         `json:"e"`
+	}
+	const data = `{"a":"1.5C","b":"-3C","c":null,"d":{"x":"2C"},"e":["4C",null]}`
+	decode := func(data string, v interface{}, optFuncs ...json.DecodeOptionFunc) error {
+		return json.UnmarshalWithOption([]byte(data), v, optFuncs...)
+	}
+	decodeStream := func(data string, v interface{}, optFuncs ...json.DecodeOptionFunc) error {
+		return json.NewDecoder(strings.NewReader(data)).DecodeWithOption(v, optFuncs...)
+	}
+	for name, decode := range map[string]func(string, interface{}, ...json.DecodeOptionFunc) error{
+		"buffer": decode,
+		"stream": decodeStream,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var v T
+			assertErr(t, decode(data, &v, celsius))
+			assertEq(t, "a", marshalFuncCelsius(1.5), v.A)
+			assertEq(t, "b", marshalFuncCelsius(-3), *v.B)
+			assertEq(t, "c", marshalFuncCelsius(-273.15), v.C)
+			assertEq(t, "d", marshalFuncCelsius(2), v.D["x"])
+			assertEq(t, "e length", 2, len(v.E))
+			assertEq(t, "e", marshalFuncCelsius(4), *v.E[0])
+			if v.E[1] != nil {
+				t.Fatal("expected nil")
+			}
+
+			var c marshalFuncCelsius
+			assertErr(t, decode(`"7C"`, &c, celsius))
+			assertEq(t, "top level", marshalFuncCelsius(7), c)
+
+			var iface interface{} = &c
+			assertErr(t, decode(`"8C"`, &iface, celsius))
+			assertEq(t, "interface", marshalFuncCelsius(8), c)
+
+			if err := decode(`"7C"`, &c); err == nil {
+				t.Fatal("expected error without the function")
+			}
+			if err := decode(`{"a":"xC"}`, &v, celsius); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+	t.Run("precedence", func(t *testing.T) {
+		other := json.UnmarshalFunc(func(data []byte, v *marshalFuncCelsius) error {
+			*v = 100
+			return nil
+		})
+		var c marshalFuncCelsius
+		assertErr(t, json.UnmarshalWithOption([]byte(`"1C"`), &c, celsius, other))
+		assertEq(t, "last func", marshalFuncCelsius(100), c)
+
+		var tm time.Time
+		assertErr(t, json.UnmarshalWithOption([]byte(`1700000000`), &tm, json.UnmarshalFunc(func(data []byte, v *time.Time) error {
+			sec, err := strconv.ParseInt(string(data), 10, 64)
+			if err != nil {
+				return err
+			}
+			*v = time.Unix(sec, 0)
+			return nil
+		})))
+		assertEq(t, "over method", int64(1700000000), tm.Unix())
+	})
+	t.Run("function for each call", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			value := marshalFuncCelsius(i)
+			fn := json.UnmarshalFunc(func(data []byte, v *marshalFuncCelsius) error {
+				*v = value
+				return nil
+			})
+			var v T
+			assertErr(t, json.UnmarshalWithOption([]byte(`{"a":"xC"}`), &v, fn))
+			assertEq(t, "value", value, v.A)
+		}
+	})
+	t.Run("typed", func(t *testing.T) {
+		got, err := json.UnmarshalAs[[]marshalFuncCelsius]([]byte(`["1C","2C"]`), celsius)
+		assertErr(t, err)
+		assertEq(t, "values", "[1 2]", fmt.Sprint(got))
+	})
+	t.Run("register", func(t *testing.T) {
+		var v []unmarshalFuncRegistered
+		assertErr(t, json.Unmarshal([]byte(`[{"V":1}]`), &v))
+		assertEq(t, "before register", 1, v[0].V)
+		json.RegisterUnmarshalFunc(func(data []byte, v *unmarshalFuncRegistered) error {
+			n, err := strconv.Atoi(string(data))
+			if err != nil {
+				return err
+			}
+			v.V = n
+			return nil
+		})
+		assertErr(t, json.Unmarshal([]byte(`[2]`), &v))
+		assertEq(t, "after register", 2, v[0].V)
+	})
+}
+
+// unmarshalFromSum sums the numbers of the array.
+type unmarshalFromSum struct {
+	Sum   int
+	Count int
+}
+
+func (v *unmarshalFromSum) UnmarshalJSONFrom(d *json.TokenDecoder) error {
+	tok, err := d.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("unexpected token %v", tok)
+	}
+	for d.More() {
+		var n int
+		if err := d.Decode(&n); err != nil {
+			return err
+		}
+		v.Sum += n
+		v.Count++
+	}
+	if _, err := d.Token(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v *unmarshalFromSum) UnmarshalJSON([]byte) error {
+	return fmt.Errorf("UnmarshalJSON is called")
+}
+
+// unmarshalFromPartial reads only the first token of the value.
+type unmarshalFromPartial struct{}
+
+func (*unmarshalFromPartial) UnmarshalJSONFrom(d *json.TokenDecoder) error {
+	_, err := d.Token()
+	return err
+}
+
+// unmarshalFromRaw keeps the value as it is.
+type unmarshalFromRaw struct {
+	Raw string
+}
+
+func (v *unmarshalFromRaw) UnmarshalJSONFrom(d *json.TokenDecoder) error {
+	b, err := d.ReadValue()
+	if err != nil {
+		return err
+	}
+	v.Raw = string(b)
+	if _, err := d.Token(); err != io.EOF {
+		return fmt.Errorf("expected EOF after the value but got %v", err)
+	}
+	return nil
+}
+
+// unmarshalFromTokens keeps the tokens of the value.
+type unmarshalFromTokens struct {
+	Tokens []json.Token
+}
+
+func (v *unmarshalFromTokens) UnmarshalJSONFrom(d *json.TokenDecoder) error {
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		v.Tokens = append(v.Tokens, tok)
+	}
+}
+
+// unmarshalFromValues decodes the members of object by Decode.
+type unmarshalFromValues struct {
+	Values map[string]interface{}
+}
+
+func (v *unmarshalFromValues) UnmarshalJSONFrom(d *json.TokenDecoder) error {
+	if _, err := d.Token(); err != nil {
+		return err
+	}
+	v.Values = map[string]interface{}{}
+	for d.More() {
+		key, err := d.Token()
+		if err != nil {
+			return err
+		}
+		var value interface{}
+		if err := d.Decode(&value); err != nil {
+			return err
+		}
+		v.Values[key.(string)] = value
+	}
+	_, err := d.Token()
+	return err
+}
+
+func TestUnmarshalerFrom(t *testing.T) {
+	type T struct {
+		A unmarshalFromSum  `json:"a"`
+		B *unmarshalFromSum `json:"b"`
+		C unmarshalFromRaw  `json:"c"`
+		D []unmarshalFromSum
+		E string `json:"e"`
+	}
+	const data = `{"a":[1, 2, 3],"b": [4],"c": {"x":[1,{}]} ,"D":[[],[5,6],null],"e":"end"}`
+	decode := func(data string, v interface{}) error {
+		return json.Unmarshal([]byte(data), v)
+	}
+	decodeStream := func(data string, v interface{}) error {
+		return json.NewDecoder(iotest.OneByteReader(strings.NewReader(data))).Decode(v)
+	}
+	for name, decode := range map[string]func(string, interface{}) error{
+		"buffer": decode,
+		"stream": decodeStream,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var v T
+			assertErr(t, decode(data, &v))
+			assertEq(t, "a", unmarshalFromSum{Sum: 6, Count: 3}, v.A)
+			assertEq(t, "b", unmarshalFromSum{Sum: 4, Count: 1}, *v.B)
+			assertEq(t, "c", `{"x":[1,{}]}`, v.C.Raw)
+			assertEq(t, "d length", 3, len(v.D))
+			assertEq(t, "d", unmarshalFromSum{Sum: 11, Count: 2}, v.D[1])
+			assertEq(t, "e", "end", v.E)
+
+			var sum unmarshalFromSum
+			assertErr(t, decode(`[10,20]`, &sum))
+			assertEq(t, "top level", unmarshalFromSum{Sum: 30, Count: 2}, sum)
+
+			if err := decode(`{"a":[1,"x"]}`, &v); err == nil {
+				t.Fatal("expected error")
+			}
+			if err := decode(`{"a":{}}`, &v); err == nil {
+				t.Fatal("expected error")
+			}
+			var partial struct {
+				A unmarshalFromPartial `json:"a"`
+			}
+			if err := decode(`{"a":[1]}`, &partial); err == nil {
+				t.Fatal("expected error")
+			}
+
+			var tokens struct {
+				A unmarshalFromTokens `json:"a"`
+			}
+			assertErr(t, decode(`{"a":{"x":[1,{"y":null}],"z":true}}`, &tokens))
+			assertEq(t, "tokens", `[{ x [ 1 { y <nil> } ] z true }]`, fmt.Sprint(tokens.A.Tokens))
+			var values struct {
+				A unmarshalFromValues `json:"a"`
+			}
+			assertErr(t, decode(`{"a":{"x":[1],"y":"z"}}`, &values))
+			assertEq(t, "values", `map[x:[1] y:z]`, fmt.Sprint(values.A.Values))
+			var raw struct {
+				A unmarshalFromRaw `json:"a"`
+			}
+			for _, in := range []string{`{"a" 1}`, `[1 2]`, `{"a":1,}`, `{"a":1 "b":2}`, `[1,,2]`, `{,}`, `[1,]`, `{"a":1]`, `[}`, `{1:2}`} {
+				data := `{"a":` + in + `}`
+				if err := decode(data, &tokens); err == nil {
+					t.Fatalf("expected error for tokens of %s", in)
+				}
+				if err := decode(data, &raw); err == nil {
+					t.Fatalf("expected error for raw value of %s", in)
+				}
+				if in[0] == '{' {
+					if err := decode(data, &values); err == nil {
+						t.Fatalf("expected error for values of %s", in)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_DecodeCollectErrors(t *testing.T) {
+	type Item struct {
+		Name  string `json:"name"`
+		Price int    `json:"price"`
+	}
+	type Request struct {
+		ID    int            `json:"id"`
+		Items []Item         `json:"items"`
+		Tags  [2]string      `json:"tags"`
+		Attrs map[string]int `json:"attrs"`
+	}
+	src := `{
+  "id": "x",
+  "items": [{"name": "a", "price": 1}, {"name": 2, "price": "3"}],
+  "tags": ["a", 1],
+  "attrs": {"ok": 1, "ng": "2"}
+}`
+	t.Run("collect", func(t *testing.T) {
+		var v Request
+		err := json.UnmarshalWithOption([]byte(src), &v, json.DecodeCollectErrors())
+		var errs *json.UnmarshalErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("expected UnmarshalErrors but got %v", err)
+		}
+		var pointers []string
+		for _, err := range errs.Unwrap() {
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				t.Fatalf("expected UnmarshalTypeError but got %v", err)
+			}
+			if typeErr.Line == 0 {
+				t.Fatalf("expected the position of the error: %v", err)
+			}
+			pointers = append(pointers, typeErr.Pointer)
+		}
+		assertEq(t, "pointers", "[/id /items/1/name /items/1/price /tags/1 /attrs/ng]", fmt.Sprint(pointers))
+		assertEq(t, "items", 2, len(v.Items))
+		assertEq(t, "item", Item{Name: "a", Price: 1}, v.Items[0])
+		assertEq(t, "tags", "a", v.Tags[0])
+		assertEq(t, "attrs", "map[ok:1]", fmt.Sprint(v.Attrs))
+		if !strings.HasPrefix(err.Error(), "/id: json: cannot unmarshal") {
+			t.Fatalf("unexpected error message: %s", err.Error())
+		}
+	})
+	t.Run("syntax error", func(t *testing.T) {
+		var v Request
+		err := json.UnmarshalWithOption([]byte(`{"id": "x", "items": [}`), &v, json.DecodeCollectErrors())
+		var errs *json.UnmarshalErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("expected UnmarshalErrors but got %v", err)
+		}
+		assertEq(t, "errors", 2, len(errs.Errors))
+		var syntaxErr *json.SyntaxError
+		if !errors.As(errs.Errors[1], &syntaxErr) {
+			t.Fatalf("expected SyntaxError but got %v", errs.Errors[1])
+		}
+	})
+	t.Run("single error", func(t *testing.T) {
+		for _, src := range []string{`{"id": 1, "items": [}`, `{"id": "x"}`} {
+			var v Request
+			err := json.UnmarshalWithOption([]byte(src), &v, json.DecodeCollectErrors())
+			var errs *json.UnmarshalErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("expected UnmarshalErrors but got %v", err)
+			}
+			assertEq(t, "errors", 1, len(errs.Errors))
+		}
+	})
+	t.Run("without option", func(t *testing.T) {
+		var v Request
+		err := json.Unmarshal([]byte(src), &v)
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			t.Fatalf("expected UnmarshalTypeError but got %v", err)
+		}
+		assertEq(t, "pointer", "/id", typeErr.Pointer)
+	})
+	t.Run("no errors", func(t *testing.T) {
+		var v Request
+		assertErr(t, json.UnmarshalWithOption([]byte(`{"id": 1}`), &v, json.DecodeCollectErrors()))
+		assertEq(t, "id", 1, v.ID)
+	})
+}
+
+func Test_DecodeErrorPosition(t *testing.T) {
+	type T struct {
+		A int
+		B []string
+	}
+	tests := []struct {
+		name    string
+		src     string
+		line    int
+		column  int
+		snippet string
+	}{
+		{
+			name:    "syntax error",
+			src:     "{\n  \"A\": 1,\n  \"B\": [\"x\",, \"y\"]\n}",
+			line:    3,
+			column:  13,
+			snippet: "3 |   \"B\": [\"x\",, \"y\"]\n  |             ^",
+		},
+		{
+			name:    "type error",
+			src:     "{\n  \"A\": true\n}",
+			line:    2,
+			column:  8,
+			snippet: "2 |   \"A\": true\n  |        ^",
+		},
+		{
+			name:    "multibyte characters",
+			src:     "{\"B\": [\"あいう\", 1]}",
+			line:    1,
+			column:  21,
+			snippet: "1 | {\"B\": [\"あいう\", 1]}\n  |               ^",
+		},
+	}
+	locate := func(t *testing.T, err error) (int, int, string) {
+		t.Helper()
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return syntaxErr.Line, syntaxErr.Column, syntaxErr.Snippet()
+		}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return typeErr.Line, typeErr.Column, typeErr.Snippet()
+		}
+		t.Fatalf("unexpected error: %v", err)
+		return 0, 0, ""
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var v T
+			line, column, snippet := locate(t, json.Unmarshal([]byte(test.src), &v))
+			assertEq(t, "line", test.line, line)
+			assertEq(t, "column", test.column, column)
+			assertEq(t, "snippet", test.snippet, snippet)
+		})
+		t.Run(test.name+" with stream", func(t *testing.T) {
+			// the leading lines are discarded from the stream buffer before the error occurs.
+			const padding = 1000
+			var v T
+			line, column, _ := locate(t, json.NewDecoder(strings.NewReader(strings.Repeat(" \n", padding)+test.src)).Decode(&v))
+			assertEq(t, "line", test.line+padding, line)
+			assertEq(t, "column", test.column, column)
+		})
+	}
+	t.Run("after top-level value", func(t *testing.T) {
+		var v T
+		line, column, _ := locate(t, json.Unmarshal([]byte("{}\n  x"), &v))
+		assertEq(t, "line", 2, line)
+		assertEq(t, "column", 3, column)
+	})
+	t.Run("after values with stream", func(t *testing.T) {
+		// the buffer is replaced several times while decoding the preceding values.
+		const values = 500
+		dec := json.NewDecoder(strings.NewReader(strings.Repeat("{\"A\": 1}\n", values) + "{\"A\": true}"))
+		for i := 0; i < values; i++ {
+			var v T
+			assertErr(t, dec.Decode(&v))
+		}
+		var v T
+		line, column, _ := locate(t, dec.Decode(&v))
+		assertEq(t, "line", values+1, line)
+		assertEq(t, "column", 7, column)
+	})
+	t.Run("long line", func(t *testing.T) {
+		var v T
+		src := `{"B": [` + strings.Repeat(`"x", `, 100) + `1]}`
+		_, column, snippet := locate(t, json.Unmarshal([]byte(src), &v))
+		assertEq(t, "column", len(src)-2, column)
+		if !strings.HasPrefix(snippet, "1 | ...") {
+			t.Fatalf("expected truncated snippet but got %q", snippet)
+		}
+	})
+}
+
 func Test_Decoder_EmptyObjectWithSpace(t *testing.T) {
 	dec := json.NewDecoder(strings.NewReader(`{"obj":{ }}`))
 	var v struct {
@@ -773,28 +1842,28 @@ type DoublePtr struct {
 
 var unmarshalTests = []unmarshalTest{
 	// basic types
-	{in: `true`, ptr: new(bool), out: true},                                                                                                                       // 0
-	{in: `1`, ptr: new(int), out: 1},                                                                                                                              // 1
-	{in: `1.2`, ptr: new(float64), out: 1.2},                                                                                                                      // 2
-	{in: `-5`, ptr: new(int16), out: int16(-5)},                                                                                                                   // 3
-	{in: `2`, ptr: new(json.Number), out: json.Number("2"), useNumber: true},                                                                                      // 4
-	{in: `2`, ptr: new(json.Number), out: json.Number("2")},                                                                                                       // 5
-	{in: `2`, ptr: new(interface{}), out: float64(2.0)},                                                                                                           // 6
-	{in: `2`, ptr: new(interface{}), out: json.Number("2"), useNumber: true},                                                                                      // 7
-	{in: `"a\u1234"`, ptr: new(string), out: "a\u1234"},                                                                                                           // 8
-	{in: `"http:\/\/"`, ptr: new(string), out: "http://"},                                                                                                         // 9
-	{in: `"g-clef: \uD834\uDD1E"`, ptr: new(string), out: "g-clef: \U0001D11E"},                                                                                   // 10
-	{in: `"invalid: \uD834x\uDD1E"`, ptr: new(string), out: "invalid: \uFFFDx\uFFFD"},                                                                             // 11
-	{in: "null", ptr: new(interface{}), out: nil},                                                                                                                 // 12
-	{in: `{"X": [1,2,3], "Y": 4}`, ptr: new(T), out: T{Y: 4}, err: &json.UnmarshalTypeError{"array", reflect.TypeOf(""), 7, "T", "X"}},                            // 13
-	{in: `{"X": 23}`, ptr: new(T), out: T{}, err: &json.UnmarshalTypeError{"number", reflect.TypeOf(""), 8, "T", "X"}}, {in: `{"x": 1}`, ptr: new(tx), out: tx{}}, // 14
+	{in: `true`, ptr: new(bool), out: true},                                           // 0
+	{in: `1`, ptr: new(int), out: 1},                                                  // 1
+	{in: `1.2`, ptr: new(float64), out: 1.2},                                          // 2
+	{in: `-5`, ptr: new(int16), out: int16(-5)},                                       // 3
+	{in: `2`, ptr: new(json.Number), out: json.Number("2"), useNumber: true},          // 4
+	{in: `2`, ptr: new(json.Number), out: json.Number("2")},                           // 5
+	{in: `2`, ptr: new(interface{}), out: float64(2.0)},                               // 6
+	{in: `2`, ptr: new(interface{}), out: json.Number("2"), useNumber: true},          // 7
+	{in: `"a\u1234"`, ptr: new(string), out: "a\u1234"},                               // 8
+	{in: `"http:\/\/"`, ptr: new(string), out: "http://"},                             // 9
+	{in: `"g-clef: \uD834\uDD1E"`, ptr: new(string), out: "g-clef: \U0001D11E"},       // 10
+	{in: `"invalid: \uD834x\uDD1E"`, ptr: new(string), out: "invalid: \uFFFDx\uFFFD"}, // 11
+	{in: "null", ptr: new(interface{}), out: nil},                                     // 12
+	{in: `{"X": [1,2,3], "Y": 4}`, ptr: new(T), out: T{Y: 4}, err: &json.UnmarshalTypeError{Value: "array", Type: reflect.TypeOf(""), Offset: 7, Struct: "T", Field: "X"}},                            // 13
+	{in: `{"X": 23}`, ptr: new(T), out: T{}, err: &json.UnmarshalTypeError{Value: "number", Type: reflect.TypeOf(""), Offset: 8, Struct: "T", Field: "X"}}, {in: `{"x": 1}`, ptr: new(tx), out: tx{}}, // 14
 	{in: `{"x": 1}`, ptr: new(tx), out: tx{}}, // 15, 16
-	{in: `{"x": 1}`, ptr: new(tx), err: fmt.Errorf("json: unknown field \"x\""), disallowUnknownFields: true},                           // 17
-	{in: `{"S": 23}`, ptr: new(W), out: W{}, err: &json.UnmarshalTypeError{"number", reflect.TypeOf(SS("")), 0, "W", "S"}},              // 18
-	{in: `{"F1":1,"F2":2,"F3":3}`, ptr: new(V), out: V{F1: float64(1), F2: int32(2), F3: json.Number("3")}},                             // 19
-	{in: `{"F1":1,"F2":2,"F3":3}`, ptr: new(V), out: V{F1: json.Number("1"), F2: int32(2), F3: json.Number("3")}, useNumber: true},      // 20
-	{in: `{"k1":1,"k2":"s","k3":[1,2.0,3e-3],"k4":{"kk1":"s","kk2":2}}`, ptr: new(interface{}), out: ifaceNumAsFloat64},                 // 21
-	{in: `{"k1":1,"k2":"s","k3":[1,2.0,3e-3],"k4":{"kk1":"s","kk2":2}}`, ptr: new(interface{}), out: ifaceNumAsNumber, useNumber: true}, // 22
+	{in: `{"x": 1}`, ptr: new(tx), err: fmt.Errorf("json: unknown field \"x\""), disallowUnknownFields: true},                                                  // 17
+	{in: `{"S": 23}`, ptr: new(W), out: W{}, err: &json.UnmarshalTypeError{Value: "number", Type: reflect.TypeOf(SS("")), Offset: 0, Struct: "W", Field: "S"}}, // 18
+	{in: `{"F1":1,"F2":2,"F3":3}`, ptr: new(V), out: V{F1: float64(1), F2: int32(2), F3: json.Number("3")}},                                                    // 19
+	{in: `{"F1":1,"F2":2,"F3":3}`, ptr: new(V), out: V{F1: json.Number("1"), F2: int32(2), F3: json.Number("3")}, useNumber: true},                             // 20
+	{in: `{"k1":1,"k2":"s","k3":[1,2.0,3e-3],"k4":{"kk1":"s","kk2":2}}`, ptr: new(interface{}), out: ifaceNumAsFloat64},                                        // 21
+	{in: `{"k1":1,"k2":"s","k3":[1,2.0,3e-3],"k4":{"kk1":"s","kk2":2}}`, ptr: new(interface{}), out: ifaceNumAsNumber, useNumber: true},                        // 22
 
 	// raw values with whitespace
 	{in: "\n true ", ptr: new(bool), out: true},                  // 23
@@ -2067,6 +3136,33 @@ func TestRefUnmarshal(t *testing.T) {
 	}
 }
 
+func TestDecodeStringSpecialCharOffsets(t *testing.T) {
+	// the special character is placed around the boundaries of the 8 bytes chunks.
+	specials := []string{`\"`, `\\`, `\n`, `\u0041`, `\ud83d\ude00`, "\u00e9", "\u2028", "\x01"}
+	for _, special := range specials {
+		for n := 0; n < 20; n++ {
+			for _, tail := range []string{"", "abcdefghijklmnopq", `abc\"def\tghij<klm`} {
+				src := `"` + strings.Repeat("a", n) + special + tail + `"`
+				var expected string
+				if special == "\x01" {
+					// encoding/json rejects the control character, but go-json accepts it as is.
+					expected = src[1 : len(src)-1]
+					expected = strings.ReplaceAll(expected, `\"`, `"`)
+					expected = strings.ReplaceAll(expected, `\t`, "\t")
+				} else {
+					assertErr(t, stdjson.Unmarshal([]byte(src), &expected))
+				}
+				var got string
+				assertErr(t, json.Unmarshal([]byte(src), &got))
+				assertEq(t, fmt.Sprintf("buffer %q", src), expected, got)
+				got = ""
+				assertErr(t, json.NewDecoder(iotest.OneByteReader(strings.NewReader(src))).Decode(&got))
+				assertEq(t, fmt.Sprintf("stream %q", src), expected, got)
+			}
+		}
+	}
+}
+
 // Test that the empty string doesn't panic decoding when ,string is specified
 // Issue 3450
 func TestEmptyString(t *testing.T) {
@@ -2735,8 +3831,9 @@ func TestUnmarshalErrorAfterMultipleJSON(t *testing.T) {
 				break
 			}
 		}
-		if !reflect.DeepEqual(err, tt.err) {
-			t.Errorf("#%d: got %#v, want %#v", i, err, tt.err)
+		want := json.LocateError(tt.in, tt.err)
+		if !reflect.DeepEqual(err, want) {
+			t.Errorf("#%d: got %#v, want %#v", i, err, want)
 		}
 	}
 }
@@ -20,6 +20,7 @@ type Encoder struct {
 	enabledHTMLEscape bool
 	prefix            string
 	indentStr         string
+	flushThreshold    int
 }
 
 // NewEncoder returns a new encoder that writes to w.
@@ -59,13 +60,10 @@ func (e *Encoder) EncodeContext(ctx context.Context, v interface{}, optFuncs ...
 }
 
 func (e *Encoder) encodeWithOption(ctx *encoder.RuntimeContext, v interface{}, optFuncs ...EncodeOptionFunc) error {
-	if e.enabledHTMLEscape {
-		ctx.Option.Flag |= encoder.HTMLEscapeOption
-	}
-	ctx.Option.Flag |= encoder.NormalizeUTF8Option
-	ctx.Option.DebugOut = os.Stdout
-	for _, optFunc := range optFuncs {
-		optFunc(ctx.Option)
+	e.setupOption(ctx, optFuncs...)
+	if e.flushThreshold > 0 {
+		ctx.FlushWriter = e.w
+		ctx.FlushThreshold = e.flushThreshold
 	}
 	var (
 		buf []byte
@@ -76,9 +74,28 @@ func (e *Encoder) encodeWithOption(ctx *encoder.RuntimeContext, v interface{}, o
 	} else {
 		buf, err = encode(ctx, v)
 	}
+	// the runtime context is shared with Marshal through the pool.
+	ctx.FlushWriter = nil
+	ctx.FlushThreshold = 0
 	if err != nil {
 		return err
 	}
+	return e.write(buf)
+}
+
+func (e *Encoder) setupOption(ctx *encoder.RuntimeContext, optFuncs ...EncodeOptionFunc) {
+	if e.enabledHTMLEscape {
+		ctx.Option.Flag |= encoder.HTMLEscapeOption
+	}
+	ctx.Option.Flag |= encoder.NormalizeUTF8Option
+	ctx.Option.DebugOut = os.Stdout
+	for _, optFunc := range optFuncs {
+		optFunc(ctx.Option)
+	}
+}
+
+// write writes the encoded buffer that ends with the comma for the next value.
+func (e *Encoder) write(buf []byte) error {
 	if e.enabledIndent {
 		buf = buf[:len(buf)-2]
 	} else {
@@ -99,6 +116,22 @@ func (e *Encoder) SetEscapeHTML(on bool) {
 	e.enabledHTMLEscape = on
 }
 
+// SetFlushThreshold makes the encoder write the encoded bytes to the stream
+// when the buffer grows to n bytes, instead of buffering the whole value.
+// The buffer is checked between the elements of arrays, slices and maps,
+// so the memory used for encoding a huge value stays around n bytes plus the size of the largest element.
+// The elements of the sorted maps are flushed only after the whole map has been encoded,
+// so use UnorderedMap option to flush the huge maps incrementally.
+//
+// If the encoding fails after the buffer has been flushed, the stream has the incomplete JSON value.
+// Calling SetFlushThreshold(0) disables the flushing, which is the default.
+func (e *Encoder) SetFlushThreshold(n int) {
+	if n < 0 {
+		n = 0
+	}
+	e.flushThreshold = n
+}
+
 // SetIndent instructs the encoder to format each subsequent encoded value as if indented by the package-level function Indent(dst, src, prefix, indent).
 // Calling SetIndent("", "") disables indentation.
 func (e *Encoder) SetIndent(prefix, indent string) {
@@ -1282,6 +1282,45 @@ func TestEncodeBytekind(t *testing.T) {
 	}
 }
 
+func TestEncodeStringEscapeOffsets(t *testing.T) {
+	// the character that needs to be escaped is placed around the boundaries of the 8 bytes chunks.
+	specials := []string{`"`, `\`, "\n", "\x01", "\x1f", "<", ">", "&", "\x7f", "\u00e9", "\u2028", "\xff"}
+	for _, special := range specials {
+		for n := 0; n < 20; n++ {
+			for _, tail := range []string{"", "abcdefghijklmnopq", "abc\"def\x02ghij<klm"} {
+				s := strings.Repeat("a", n) + special + tail
+				expected, err := stdjson.Marshal(s)
+				assertErr(t, err)
+				var noHTML bytes.Buffer
+				enc := stdjson.NewEncoder(&noHTML)
+				enc.SetEscapeHTML(false)
+				assertErr(t, enc.Encode(s))
+				expectedNoHTML := strings.TrimSuffix(noHTML.String(), "\n")
+				// the invalid byte is replaced with the escaped U+FFFD, not the raw one.
+				expected = bytes.ReplaceAll(expected, []byte("\ufffd"), []byte(`\ufffd`))
+				expectedNoHTML = strings.ReplaceAll(expectedNoHTML, "\ufffd", `\ufffd`)
+
+				got, err := json.Marshal(s)
+				assertErr(t, err)
+				assertEq(t, fmt.Sprintf("html %q", s), string(expected), string(got))
+				got, err = json.MarshalWithOption(s, json.DisableHTMLEscape())
+				assertErr(t, err)
+				assertEq(t, fmt.Sprintf("no html %q", s), expectedNoHTML, string(got))
+				if special == "\u2028" || special == "\xff" {
+					// they are kept as is without normalizing.
+					continue
+				}
+				got, err = json.MarshalWithOption(s, json.DisableNormalizeUTF8())
+				assertErr(t, err)
+				assertEq(t, fmt.Sprintf("html without normalizing %q", s), string(expected), string(got))
+				got, err = json.MarshalWithOption(s, json.DisableNormalizeUTF8(), json.DisableHTMLEscape())
+				assertErr(t, err)
+				assertEq(t, fmt.Sprintf("no html without normalizing %q", s), expectedNoHTML, string(got))
+			}
+		}
+	}
+}
+
 // golang.org/issue/8582
 func TestEncodePointerString(t *testing.T) {
 	type stringPointer struct {
@@ -1381,6 +1420,20 @@ func TestHTMLEscape(t *testing.T) {
 	if !bytes.Equal(b.Bytes(), want.Bytes()) {
 		t.Errorf("HTMLEscape(&b, []byte(m)) = %s; want %s", b.Bytes(), want.Bytes())
 	}
+
+	// the input is kept except for the escaped characters.
+	b.Reset()
+	json.HTMLEscape(&b, []byte(`{"b": 1.50E+02, "a" : ["<\u0041>"]}`))
+	assertEq(t, "preserve", `{"b": 1.50E+02, "a" : ["\u003c\u0041\u003e"]}`, b.String())
+
+	// the invalid input is escaped as same as encoding/json.
+	for _, src := range []string{`{"a":"<"`, `<a href="x">&amp;</a>` + "\xe2\x80\xa8\xff\xe2\x80", `{"a":1,}`} {
+		b.Reset()
+		want.Reset()
+		json.HTMLEscape(&b, []byte(src))
+		stdjson.HTMLEscape(&want, []byte(src))
+		assertEq(t, "invalid", want.String(), b.String())
+	}
 }
 
 type BugA struct {
@@ -1772,6 +1825,180 @@ func TestOmitEmpty(t *testing.T) {
 	}
 }
 
+type omitZeroValue struct {
+	v int
+}
+
+func (v omitZeroValue) IsZero() bool {
+	return v.v < 0
+}
+
+type omitZeroPtrValue struct {
+	V int
+}
+
+func (v *omitZeroPtrValue) IsZero() bool {
+	return v.V == 42
+}
+
+type OmitZeros struct {
+	Sr string `json:"sr"`
+	So string `json:"so,omitzero"`
+
+	Slr []string `json:"slr,omitzero"`
+	Sle []string `json:"sle,omitzero"`
+	Slb []string `json:"slb,omitempty,omitzero"`
+
+	Mr map[string]interface{} `json:"mr,omitzero"`
+	Me map[string]interface{} `json:"me,omitzero"`
+
+	Bytes []byte `json:"bytes,omitzero"`
+
+	Ar [2]int `json:"ar,omitzero"`
+	Ao [2]int `json:"ao,omitzero"`
+
+	Str struct{ A int } `json:"str,omitzero"`
+	Sto struct{ A int } `json:"sto,omitzero"`
+
+	Time    time.Time  `json:"time,omitzero"`
+	TimePtr *time.Time `json:"timePtr,omitzero"`
+
+	Value    omitZeroValue     `json:"value,omitzero"`
+	Valueo   omitZeroValue     `json:"valueo,omitzero"`
+	PtrValue omitZeroPtrValue  `json:"ptrValue,omitzero"`
+	Ptro     *omitZeroPtrValue `json:"ptro,omitzero"`
+	Ptrz     *omitZeroPtrValue `json:"ptrz,omitzero"`
+}
+
+func TestOmitZero(t *testing.T) {
+	t.Run("fields", func(t *testing.T) {
+		v := OmitZeros{
+			Sle:      []string{},
+			Slb:      []string{},
+			Me:       map[string]interface{}{},
+			Ar:       [2]int{0, 1},
+			Str:      struct{ A int }{A: 1},
+			Value:    omitZeroValue{v: -1},
+			Valueo:   omitZeroValue{v: 1},
+			PtrValue: omitZeroPtrValue{V: 42},
+			Ptrz:     &omitZeroPtrValue{V: 42},
+		}
+		expected, err := stdjson.Marshal(v)
+		assertErr(t, err)
+		got, err := json.Marshal(v)
+		assertErr(t, err)
+		assertEq(t, "omitzero", string(expected), string(got))
+		assertEq(t, "omitzero", `{"sr":"","sle":[],"me":{},"ar":[0,1],"str":{"A":1},"valueo":{}}`, string(got))
+
+		expected, err = stdjson.MarshalIndent(&v, "", "  ")
+		assertErr(t, err)
+		got, err = json.MarshalIndent(&v, "", "  ")
+		assertErr(t, err)
+		assertEq(t, "omitzero indent", string(expected), string(got))
+	})
+	t.Run("head and end", func(t *testing.T) {
+		type T struct {
+			A time.Time `json:"a,omitzero"`
+			B [2]int    `json:"b,omitzero"`
+			C []byte    `json:"c,omitzero"`
+		}
+		for _, v := range []T{
+			{},
+			{A: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{B: [2]int{1, 2}},
+			{C: []byte{}},
+		} {
+			expected, err := stdjson.Marshal(v)
+			assertErr(t, err)
+			got, err := json.Marshal(v)
+			assertErr(t, err)
+			assertEq(t, "omitzero value", string(expected), string(got))
+
+			got, err = json.Marshal(&v)
+			assertErr(t, err)
+			assertEq(t, "omitzero pointer", string(expected), string(got))
+		}
+	})
+	t.Run("only field", func(t *testing.T) {
+		type T struct {
+			A time.Time `json:"a,omitzero"`
+		}
+		got, err := json.Marshal(&T{})
+		assertErr(t, err)
+		assertEq(t, "omitzero", `{}`, string(got))
+
+		got, err = json.Marshal([]*T{nil, {}})
+		assertErr(t, err)
+		assertEq(t, "omitzero", `[null,{}]`, string(got))
+	})
+	t.Run("fast path", func(t *testing.T) {
+		type T struct {
+			A int     `json:"a,omitzero"`
+			B string  `json:"b,omitzero"`
+			C *int    `json:"c,omitzero"`
+			D float64 `json:"d,omitzero"`
+			E bool    `json:"e,omitzero"`
+		}
+		got, err := json.Marshal(T{})
+		assertErr(t, err)
+		assertEq(t, "omitzero", `{}`, string(got))
+	})
+	t.Run("zero value", func(t *testing.T) {
+		type inner struct {
+			F float64
+			S string
+			P *int
+			I interface{}
+		}
+		type T struct {
+			A [2]float64  `json:"a,omitzero"`
+			B inner       `json:"b,omitzero"`
+			C [1]inner    `json:"c,omitzero"`
+			E [0]int      `json:"e,omitzero"`
+			F struct{}    `json:"f,omitzero"`
+			G [2]string   `json:"g,omitempty,omitzero"`
+			H int8        `json:"h,omitzero"`
+			I interface{} `json:"i,omitzero"`
+		}
+		negZero := math.Copysign(0, -1)
+		one := 1
+		for _, v := range []T{
+			{},
+			{A: [2]float64{0, negZero}},
+			{B: inner{F: negZero}},
+			{B: inner{S: "abc"[:0]}},
+			{B: inner{P: &one}},
+			{B: inner{I: 0}},
+			{C: [1]inner{{S: "a"}}},
+			{G: [2]string{"", "a"}},
+			{H: -1},
+			{I: 0},
+		} {
+			expected, err := stdjson.Marshal(v)
+			assertErr(t, err)
+			got, err := json.Marshal(v)
+			assertErr(t, err)
+			assertEq(t, "omitzero", string(expected), string(got))
+		}
+	})
+	t.Run("struct of", func(t *testing.T) {
+		// the type created at runtime is out of the range of the types in the binary.
+		typ := reflect.StructOf([]reflect.StructField{
+			{Name: "A", Type: reflect.TypeOf([2]int{}), Tag: `json:"a,omitzero"`},
+			{Name: "B", Type: reflect.TypeOf(struct{ X int }{}), Tag: `json:"b,omitzero"`},
+		})
+		v := reflect.New(typ).Elem()
+		got, err := json.Marshal(v.Interface())
+		assertErr(t, err)
+		assertEq(t, "omitzero", `{}`, string(got))
+
+		v.Field(0).Index(1).SetInt(1)
+		got, err = json.Marshal(v.Interface())
+		assertErr(t, err)
+		assertEq(t, "omitzero", `{"a":[0,1]}`, string(got))
+	})
+}
+
 type testNullStr string
 
 func (v *testNullStr) MarshalJSON() ([]byte, error) {
@@ -2635,6 +2862,301 @@ func TestCustomMarshalForMapKey(t *testing.T) {
 	assertEq(t, "custom map key", string(expected), string(got))
 }
 
+type marshalFuncCelsius float64
+
+type marshalFuncTags map[string]string
+
+type marshalFuncMethod struct {
+	V int
+}
+
+func (marshalFuncMethod) MarshalJSON() ([]byte, error) {
+	return []byte(`"method"`), nil
+}
+
+type marshalFuncPoint struct {
+	X int
+}
+
+type marshalFuncRegistered struct {
+	V int
+}
+
+func TestMarshalFunc(t *testing.T) {
+	celsius := json.MarshalFunc(func(v marshalFuncCelsius) ([]byte, error) {
+		return []byte(fmt.Sprintf(`"%.1fC"`, float64(v))), nil
+	})
+	tags := json.MarshalFunc(func(v marshalFuncTags) ([]byte, error) {
+		return []byte(fmt.Sprintf(`{ "count" : %d }`, len(v))), nil
+	})
+	t.Run("top level", func(t *testing.T) {
+		c := marshalFuncCelsius(21.5)
+		b, err := json.MarshalWithOption(c, celsius)
+		assertErr(t, err)
+		assertEq(t, "value", `"21.5C"`, string(b))
+		b, err = json.MarshalWithOption(&c, celsius)
+		assertErr(t, err)
+		assertEq(t, "pointer", `"21.5C"`, string(b))
+		b, err = json.MarshalWithOption((*marshalFuncCelsius)(nil), celsius)
+		assertErr(t, err)
+		assertEq(t, "nil pointer", `null`, string(b))
+		b, err = json.MarshalWithOption(marshalFuncTags{"a": "b"}, tags)
+		assertErr(t, err)
+		assertEq(t, "pointer shaped", `{"count":1}`, string(b))
+	})
+	t.Run("struct field", func(t *testing.T) {
+		c := marshalFuncCelsius(-3)
+		type T struct {
+			A marshalFuncCelsius            `json:"a"`
+			B *marshalFuncCelsius           `json:"b"`
+			C *marshalFuncCelsius           `json:"c"`
+			D marshalFuncCelsius            `json:"d,omitempty"`
+			E *marshalFuncCelsius           `json:"e,omitempty"`
+			F marshalFuncTags               `json:"f"`
+			G map[string]marshalFuncCelsius `json:"g"`
+			H []*marshalFuncCelsius         `json:"h"`
+			I interface{}                   `json:"i"`
+		}
+		v := T{
+			A: 1,
+			B: &c,
+			F: marshalFuncTags{},
+			G: map[string]marshalFuncCelsius{"x": 2},
+			H: []*marshalFuncCelsius{&c, nil},
+			I: marshalFuncCelsius(4),
+		}
+		expected := `{"a":"1.0C","b":"-3.0C","c":null,"f":{"count":0},"g":{"x":"2.0C"},"h":["-3.0C",null],"i":"4.0C"}`
+		b, err := json.MarshalWithOption(v, celsius, tags)
+		assertErr(t, err)
+		assertEq(t, "value", expected, string(b))
+		b, err = json.MarshalWithOption(&v, celsius, tags)
+		assertErr(t, err)
+		assertEq(t, "pointer", expected, string(b))
+	})
+	t.Run("omitzero", func(t *testing.T) {
+		type T struct {
+			A marshalFuncCelsius `json:"a,omitzero"`
+			B marshalFuncTags    `json:"b,omitzero"`
+		}
+		b, err := json.MarshalWithOption(T{}, celsius, tags)
+		assertErr(t, err)
+		assertEq(t, "zero", `{}`, string(b))
+		b, err = json.MarshalWithOption(T{A: 1, B: marshalFuncTags{}}, celsius, tags)
+		assertErr(t, err)
+		assertEq(t, "not zero", `{"a":"1.0C","b":{"count":0}}`, string(b))
+	})
+	t.Run("omitempty", func(t *testing.T) {
+		point := json.MarshalFunc(func(v marshalFuncPoint) ([]byte, error) {
+			return []byte(fmt.Sprintf(`"%d"`, v.X)), nil
+		})
+		type T struct {
+			A marshalFuncPoint      `json:"a,omitempty"`
+			B [1]marshalFuncCelsius `json:"b,omitempty"`
+			C marshalFuncCelsius    `json:"c,omitempty"`
+			D marshalFuncPoint      `json:"d,omitempty,omitzero"`
+		}
+		// the struct and the array that has elements are never empty like the fields without MarshalFunc.
+		b, err := json.MarshalWithOption(T{}, celsius, point)
+		assertErr(t, err)
+		assertEq(t, "zero", `{"a":"0","b":["0.0C"]}`, string(b))
+	})
+	t.Run("only one pointer field", func(t *testing.T) {
+		type T struct {
+			A *marshalFuncCelsius `json:"a"`
+		}
+		c := marshalFuncCelsius(5)
+		b, err := json.MarshalWithOption(T{A: &c}, celsius)
+		assertErr(t, err)
+		assertEq(t, "value", `{"a":"5.0C"}`, string(b))
+		b, err = json.MarshalWithOption(&T{A: &c}, celsius)
+		assertErr(t, err)
+		assertEq(t, "pointer", `{"a":"5.0C"}`, string(b))
+		b, err = json.MarshalWithOption([]T{{A: &c}, {}}, celsius)
+		assertErr(t, err)
+		assertEq(t, "slice", `[{"a":"5.0C"},{"a":null}]`, string(b))
+	})
+	t.Run("indent and color", func(t *testing.T) {
+		v := map[string]marshalFuncTags{"a": {"b": "c"}}
+		b, err := json.MarshalIndentWithOption(v, "", "  ", tags)
+		assertErr(t, err)
+		assertEq(t, "indent", "{\n  \"a\": {\n    \"count\": 1\n  }\n}", string(b))
+		b, err = json.MarshalWithOption(v, tags, json.Colorize(&json.ColorScheme{}))
+		assertErr(t, err)
+		assertEq(t, "color", `{"a":{"count":1}}`, string(b))
+	})
+	t.Run("precedence", func(t *testing.T) {
+		method := json.MarshalFunc(func(v marshalFuncMethod) ([]byte, error) {
+			return []byte(`"func"`), nil
+		})
+		other := json.MarshalFunc(func(v marshalFuncMethod) ([]byte, error) {
+			return []byte(`"other"`), nil
+		})
+		b, err := json.Marshal(marshalFuncMethod{})
+		assertErr(t, err)
+		assertEq(t, "method", `"method"`, string(b))
+		b, err = json.MarshalWithOption(marshalFuncMethod{}, method)
+		assertErr(t, err)
+		assertEq(t, "func", `"func"`, string(b))
+		b, err = json.MarshalWithOption(marshalFuncMethod{}, method, other)
+		assertErr(t, err)
+		assertEq(t, "last func", `"other"`, string(b))
+		b, err = json.Marshal(marshalFuncMethod{})
+		assertErr(t, err)
+		assertEq(t, "method after func", `"method"`, string(b))
+	})
+	t.Run("error", func(t *testing.T) {
+		errFunc := json.MarshalFunc(func(v marshalFuncCelsius) ([]byte, error) {
+			return nil, fmt.Errorf("cannot marshal")
+		})
+		_, err := json.MarshalWithOption(marshalFuncCelsius(0), errFunc)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		assertEq(t, "error", "json: error calling MarshalFunc for type json_test.marshalFuncCelsius: cannot marshal", err.Error())
+		invalid := json.MarshalFunc(func(v marshalFuncCelsius) ([]byte, error) {
+			return []byte(`{`), nil
+		})
+		if _, err := json.MarshalWithOption(marshalFuncCelsius(0), invalid); err == nil {
+			t.Fatal("expected error")
+		}
+		if _, err := json.MarshalIndentWithOption(marshalFuncCelsius(0), "", " ", invalid); err == nil {
+			t.Fatal("expected error")
+		} else if !strings.Contains(err.Error(), "error calling MarshalFunc") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	t.Run("function for each call", func(t *testing.T) {
+		type T struct {
+			A marshalFuncCelsius `json:"a"`
+		}
+		for i := 0; i < 3; i++ {
+			unit := fmt.Sprint(i)
+			fn := json.MarshalFunc(func(v marshalFuncCelsius) ([]byte, error) {
+				return []byte(strconv.Quote(unit)), nil
+			})
+			b, err := json.MarshalWithOption(T{}, fn, tags)
+			assertErr(t, err)
+			assertEq(t, "value", fmt.Sprintf(`{"a":"%d"}`, i), string(b))
+		}
+	})
+	t.Run("map key", func(t *testing.T) {
+		type Key string
+		upper := json.MarshalFunc(func(v Key) ([]byte, error) {
+			return []byte(strconv.Quote(strings.ToUpper(string(v)))), nil
+		})
+		b, err := json.MarshalWithOption(map[Key]Key{"a": "b"}, upper)
+		assertErr(t, err)
+		assertEq(t, "map key", `{"a":"B"}`, string(b))
+	})
+	t.Run("register", func(t *testing.T) {
+		v := []marshalFuncRegistered{{V: 1}}
+		b, err := json.Marshal(v)
+		assertErr(t, err)
+		assertEq(t, "before register", `[{"V":1}]`, string(b))
+		json.RegisterMarshalFunc(func(v marshalFuncRegistered) ([]byte, error) {
+			return []byte(fmt.Sprint(v.V)), nil
+		})
+		b, err = json.Marshal(v)
+		assertErr(t, err)
+		assertEq(t, "after register", `[1]`, string(b))
+		b, err = json.MarshalWithOption(v, json.MarshalFunc(func(v marshalFuncRegistered) ([]byte, error) {
+			return []byte(`"option"`), nil
+		}))
+		assertErr(t, err)
+		assertEq(t, "option", `["option"]`, string(b))
+	})
+}
+
+type appendJSONPoint struct {
+	X, Y int
+}
+
+func (p appendJSONPoint) AppendJSON(b []byte) ([]byte, error) {
+	b = append(b, '[')
+	b = strconv.AppendInt(b, int64(p.X), 10)
+	b = append(b, ',')
+	b = strconv.AppendInt(b, int64(p.Y), 10)
+	return append(b, ']'), nil
+}
+
+func (p appendJSONPoint) MarshalJSON() ([]byte, error) {
+	return []byte(`"marshal json"`), nil
+}
+
+type appendJSONPtrName string
+
+func (n *appendJSONPtrName) AppendJSON(b []byte) ([]byte, error) {
+	if *n == "" {
+		return nil, fmt.Errorf("empty name")
+	}
+	return strconv.AppendQuote(b, strings.ToUpper(string(*n))), nil
+}
+
+type appendJSONRaw string
+
+func (r appendJSONRaw) AppendJSON(b []byte) ([]byte, error) {
+	return append(b, r...), nil
+}
+
+func TestMarshalerTo(t *testing.T) {
+	t.Run("value", func(t *testing.T) {
+		p := appendJSONPoint{X: 1, Y: -2}
+		b, err := json.Marshal(p)
+		assertErr(t, err)
+		assertEq(t, "value", `[1,-2]`, string(b))
+		b, err = json.Marshal(&p)
+		assertErr(t, err)
+		assertEq(t, "pointer", `[1,-2]`, string(b))
+		b, err = json.Marshal((*appendJSONPoint)(nil))
+		assertErr(t, err)
+		assertEq(t, "nil", `null`, string(b))
+	})
+	t.Run("fields and elements", func(t *testing.T) {
+		name := appendJSONPtrName("b")
+		type T struct {
+			A appendJSONPoint             `json:"a"`
+			B *appendJSONPtrName          `json:"b"`
+			C *appendJSONPoint            `json:"c,omitempty"`
+			D []appendJSONPoint           `json:"d"`
+			E map[string]*appendJSONPoint `json:"e"`
+			F interface{}                 `json:"f"`
+		}
+		v := &T{
+			A: appendJSONPoint{X: 1},
+			B: &name,
+			D: []appendJSONPoint{{X: 2}, {Y: 3}},
+			E: map[string]*appendJSONPoint{"x": {X: 4}, "y": nil},
+			F: appendJSONPoint{X: 5},
+		}
+		b, err := json.Marshal(v)
+		assertErr(t, err)
+		assertEq(t, "compact", `{"a":[1,0],"b":"B","d":[[2,0],[0,3]],"e":{"x":[4,0],"y":null},"f":[5,0]}`, string(b))
+		b, err = json.MarshalIndent(v, "", " ")
+		assertErr(t, err)
+		expected, err := stdjson.MarshalIndent(json.RawMessage(`{"a":[1,0],"b":"B","d":[[2,0],[0,3]],"e":{"x":[4,0],"y":null},"f":[5,0]}`), "", " ")
+		assertErr(t, err)
+		assertEq(t, "indent", string(expected), string(b))
+	})
+	t.Run("error", func(t *testing.T) {
+		name := appendJSONPtrName("")
+		if _, err := json.Marshal(&name); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+	t.Run("validate", func(t *testing.T) {
+		v := []appendJSONRaw{`{"a": 1}`}
+		b, err := json.Marshal(v)
+		assertErr(t, err)
+		assertEq(t, "trusted", `[{"a": 1}]`, string(b))
+		b, err = json.MarshalWithOption(v, json.ValidateAppendJSON())
+		assertErr(t, err)
+		assertEq(t, "valid", `[{"a": 1}]`, string(b))
+		if _, err := json.MarshalWithOption([]appendJSONRaw{`{"a":}`}, json.ValidateAppendJSON()); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
 func TestIssue391(t *testing.T) {
 	type A struct {
 		X string `json:"x,omitempty"`
@@ -32,10 +32,31 @@ type UnmarshalFieldError = errors.UnmarshalFieldError
 // not appropriate for a value of a specific Go type.
 type UnmarshalTypeError = errors.UnmarshalTypeError
 
+// UnmarshalErrors is returned by Unmarshal with DecodeCollectErrors option
+// when one or more values could not be decoded.
+type UnmarshalErrors = errors.UnmarshalErrors
+
 // An UnsupportedTypeError is returned by Marshal when attempting
 // to encode an unsupported value type.
 type UnsupportedTypeError = errors.UnsupportedTypeError
 
 type UnsupportedValueError = errors.UnsupportedValueError
 
+// A LineError describes an error that occurred on a specific line of JSON Lines input.
+type LineError = errors.LineError
+
 type PathError = errors.PathError
+
+// A PatchError describes an operation of JSON Patch that could not be applied.
+// It wraps the PathError that describes the reason.
+type PatchError = errors.PatchError
+
+// A PointerError describes an invalid JSON Pointer or a JSON Pointer that does not reference any value.
+type PointerError = errors.PointerError
+
+// A DuplicateKeyError is returned by the decoding with DecodeDisallowDuplicateKeys option
+// when an object has the same key more than once.
+type DuplicateKeyError = errors.DuplicateKeyError
+
+// A NodeError describes an operation that is not applicable to the Node.
+type NodeError = errors.NodeError
@@ -8,3 +8,8 @@ var (
 	NewSyntaxError    = errors.ErrSyntax
 	NewMarshalerError = errors.ErrMarshaler
 )
+
+// LocateError sets the line and column of err located in src as the decoder does.
+func LocateError(src string, err error) error {
+	return (&errors.Source{Buf: []byte(src), Line: 1}).Locate(err)
+}
@@ -87,6 +87,14 @@ func (t OpType) CodeType() CodeType {
     return CodeMapValue
   case OpMapEnd:
     return CodeMapEnd
+  case OpOrderedMap, OpOrderedMapPtr:
+    return CodeSliceHead
+  case OpOrderedMapKey:
+    return CodeSliceElem
+  case OpOrderedMapValue:
+    return CodeMapValue
+  case OpOrderedMapEnd:
+    return CodeMapEnd
   }
 
   return CodeOp
@@ -256,6 +264,35 @@ func (t OpType) FieldToOmitEmptyField() OpType {
 			})
 		}
 	}
+	// omitzero fields are always encoded by the generic struct operations.
+	// The order keeps the Head => PtrHead offset used by HeadToPtrHead.
+	for _, op := range []string{"StructHeadOmitZero", "StructFieldOmitZero", "StructPtrHeadOmitZero"} {
+		opTypes = append(opTypes, opType{
+			Op:   op,
+			Code: "StructField",
+		})
+	}
+	// the node of document tree is encoded by the dedicated operations.
+	for _, op := range []string{"Node", "NodePtr"} {
+		opTypes = append(opTypes, createOpType(op, "Op"))
+	}
+	// the entries of OrderedMap are iterated like slice elements.
+	for _, op := range []string{"OrderedMap", "OrderedMapPtr", "OrderedMapKey", "OrderedMapValue", "OrderedMapEnd"} {
+		opTypes = append(opTypes, createOpType(op, "Op"))
+	}
+	// the map field that is inlined in the struct.
+	for _, op := range []string{"InlineMap", "InlineMapEnd"} {
+		opTypes = append(opTypes, createOpType(op, "Op"))
+	}
+	// the value of the field that has the format option.
+	for _, op := range []string{"TimeUnix", "TimeUnixMilli", "BytesHex", "BytesBase64URL", "DurationUnits"} {
+		opTypes = append(opTypes, createOpType(op, "Op"))
+		opTypes = append(opTypes, createOpType(op+"Ptr", "Op"))
+	}
+	// the value of the type that has the function given by MarshalFunc option.
+	for _, op := range []string{"MarshalFunc", "MarshalFuncPtr"} {
+		opTypes = append(opTypes, createOpType(op, "Op"))
+	}
 	var b bytes.Buffer
 	if err := tmpl.Execute(&b, struct {
 		CodeTypes []string
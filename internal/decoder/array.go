@@ -61,7 +61,7 @@ func (d *arrayDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) er
 			for {
 				if idx < d.alen {
 					if err := d.valueDecoder.DecodeStream(s, depth, unsafe.Pointer(uintptr(p)+uintptr(idx)*d.size)); err != nil {
-						return err
+						return withPointerIndex(err, idx)
 					}
 				} else {
 					if err := s.skipValue(depth); err != nil {
@@ -136,9 +136,17 @@ func (d *arrayDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsafe
 			}
 			for {
 				if idx < d.alen {
+					mark := ctx.Option.errorMark()
 					c, err := d.valueDecoder.Decode(ctx, cursor, depth, unsafe.Pointer(uintptr(p)+uintptr(idx)*d.size))
+					ctx.Option.addPointerIndex(mark, idx)
 					if err != nil {
-						return 0, err
+						err = withPointerIndex(err, idx)
+						if !ctx.Option.collectError(err) {
+							return 0, err
+						}
+						if c, err = skipValue(buf, cursor, depth); err != nil {
+							return 0, err
+						}
 					}
 					cursor = c
 				} else {
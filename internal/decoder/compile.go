@@ -10,6 +10,7 @@ import (
 	"unicode"
 	"unsafe"
 
+	"github.com/goccy/go-json/internal/errors"
 	"github.com/goccy/go-json/internal/runtime"
 )
 
@@ -25,7 +26,7 @@ func CompileToGetDecoder(typ *runtime.Type) (Decoder, error) {
 		return *dec, nil
 	}
 
-	dec, err := compileHead(typ, map[uintptr]Decoder{})
+	dec, err := compileHead(typ, newCompileContext(loadRegisteredUnmarshalFuncs()))
 	if err != nil {
 		return nil, err
 	}
@@ -75,7 +76,7 @@ func compileToGetDecoderSlowPath(typeptr uintptr, typ *runtime.Type) (Decoder, e
 		return dec, nil
 	}
 
-	dec, err := compileHead(typ, map[uintptr]Decoder{})
+	dec, err := compileHead(typ, newCompileContext(loadRegisteredUnmarshalFuncs()))
 	if err != nil {
 		return nil, err
 	}
@@ -83,18 +84,37 @@ func compileToGetDecoderSlowPath(typeptr uintptr, typ *runtime.Type) (Decoder, e
 	return dec, nil
 }
 
-func compileHead(typ *runtime.Type, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
+// compileContext is the state shared while compiling the decoder of a type.
+type compileContext struct {
+	structTypeToDecoder map[uintptr]Decoder
+	unmarshalFuncs      map[*runtime.Type]UnmarshalFunc
+}
+
+func newCompileContext(unmarshalFuncs map[*runtime.Type]UnmarshalFunc) *compileContext {
+	return &compileContext{
+		structTypeToDecoder: map[uintptr]Decoder{},
+		unmarshalFuncs:      unmarshalFuncs,
+	}
+}
+
+func compileHead(typ *runtime.Type, ctx *compileContext) (Decoder, error) {
 	switch {
+	case runtime.PtrTo(typ).Implements(unmarshalJSONFromType):
+		return newUnmarshalFromDecoder(runtime.PtrTo(typ), "", ""), nil
 	case implementsUnmarshalJSONType(runtime.PtrTo(typ)):
 		return newUnmarshalJSONDecoder(runtime.PtrTo(typ), "", ""), nil
 	case runtime.PtrTo(typ).Implements(unmarshalTextType):
 		return newUnmarshalTextDecoder(runtime.PtrTo(typ), "", ""), nil
 	}
-	return compile(typ.Elem(), "", "", structTypeToDecoder)
+	return compile(typ.Elem(), "", "", ctx)
 }
 
-func compile(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
+func compile(typ *runtime.Type, structName, fieldName string, ctx *compileContext) (Decoder, error) {
 	switch {
+	case ctx.unmarshalFuncs[typ] != nil:
+		return newUnmarshalFuncDecoder(typ, structName, fieldName), nil
+	case runtime.PtrTo(typ).Implements(unmarshalJSONFromType):
+		return newUnmarshalFromDecoder(runtime.PtrTo(typ), structName, fieldName), nil
 	case implementsUnmarshalJSONType(runtime.PtrTo(typ)):
 		return newUnmarshalJSONDecoder(runtime.PtrTo(typ), structName, fieldName), nil
 	case runtime.PtrTo(typ).Implements(unmarshalTextType):
@@ -103,19 +123,25 @@ func compile(typ *runtime.Type, structName, fieldName string, structTypeToDecode
 
 	switch typ.Kind() {
 	case reflect.Ptr:
-		return compilePtr(typ, structName, fieldName, structTypeToDecoder)
+		return compilePtr(typ, structName, fieldName, ctx)
 	case reflect.Struct:
-		return compileStruct(typ, structName, fieldName, structTypeToDecoder)
+		if typ == runtime.Type2RType(nodeType) {
+			return newNodeDecoder(structName, fieldName), nil
+		}
+		if layout := runtime.ToOrderedMapType(typ); layout != nil {
+			return compileOrderedMap(layout, structName, fieldName, ctx)
+		}
+		return compileStruct(typ, structName, fieldName, ctx)
 	case reflect.Slice:
 		elem := typ.Elem()
 		if elem.Kind() == reflect.Uint8 {
 			return compileBytes(elem, structName, fieldName)
 		}
-		return compileSlice(typ, structName, fieldName, structTypeToDecoder)
+		return compileSlice(typ, structName, fieldName, ctx)
 	case reflect.Array:
-		return compileArray(typ, structName, fieldName, structTypeToDecoder)
+		return compileArray(typ, structName, fieldName, ctx)
 	case reflect.Map:
-		return compileMap(typ, structName, fieldName, structTypeToDecoder)
+		return compileMap(typ, structName, fieldName, ctx)
 	case reflect.Interface:
 		return compileInterface(typ, structName, fieldName)
 	case reflect.Uintptr:
@@ -156,6 +182,8 @@ func compile(typ *runtime.Type, structName, fieldName string, structTypeToDecode
 
 func isStringTagSupportedType(typ *runtime.Type) bool {
 	switch {
+	case runtime.PtrTo(typ).Implements(unmarshalJSONFromType):
+		return false
 	case implementsUnmarshalJSONType(runtime.PtrTo(typ)):
 		return false
 	case runtime.PtrTo(typ).Implements(unmarshalTextType):
@@ -176,14 +204,15 @@ func isStringTagSupportedType(typ *runtime.Type) bool {
 	return true
 }
 
-func compileMapKey(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
+func compileMapKey(typ *runtime.Type, structName, fieldName string, ctx *compileContext) (Decoder, error) {
 	if runtime.PtrTo(typ).Implements(unmarshalTextType) {
 		return newUnmarshalTextDecoder(runtime.PtrTo(typ), structName, fieldName), nil
 	}
 	if typ.Kind() == reflect.String {
 		return newStringDecoder(structName, fieldName), nil
 	}
-	dec, err := compile(typ, structName, fieldName, structTypeToDecoder)
+	// the keys are decoded as usual, because the functions are called with JSON values, not object keys.
+	dec, err := compile(typ, structName, fieldName, newCompileContext(nil))
 	if err != nil {
 		return nil, err
 	}
@@ -201,8 +230,8 @@ func compileMapKey(typ *runtime.Type, structName, fieldName string, structTypeTo
 	}
 }
 
-func compilePtr(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
-	dec, err := compile(typ.Elem(), structName, fieldName, structTypeToDecoder)
+func compilePtr(typ *runtime.Type, structName, fieldName string, ctx *compileContext) (Decoder, error) {
+	dec, err := compile(typ.Elem(), structName, fieldName, ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -298,36 +327,48 @@ func compileBytes(typ *runtime.Type, structName, fieldName string) (Decoder, err
 	return newBytesDecoder(typ, structName, fieldName), nil
 }
 
-func compileSlice(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
+func compileSlice(typ *runtime.Type, structName, fieldName string, ctx *compileContext) (Decoder, error) {
 	elem := typ.Elem()
-	decoder, err := compile(elem, structName, fieldName, structTypeToDecoder)
+	decoder, err := compile(elem, structName, fieldName, ctx)
 	if err != nil {
 		return nil, err
 	}
 	return newSliceDecoder(decoder, elem, elem.Size(), structName, fieldName), nil
 }
 
-func compileArray(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
+func compileArray(typ *runtime.Type, structName, fieldName string, ctx *compileContext) (Decoder, error) {
 	elem := typ.Elem()
-	decoder, err := compile(elem, structName, fieldName, structTypeToDecoder)
+	decoder, err := compile(elem, structName, fieldName, ctx)
 	if err != nil {
 		return nil, err
 	}
 	return newArrayDecoder(decoder, elem, typ.Len(), structName, fieldName), nil
 }
 
-func compileMap(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
-	keyDec, err := compileMapKey(typ.Key(), structName, fieldName, structTypeToDecoder)
+func compileMap(typ *runtime.Type, structName, fieldName string, ctx *compileContext) (Decoder, error) {
+	keyDec, err := compileMapKey(typ.Key(), structName, fieldName, ctx)
 	if err != nil {
 		return nil, err
 	}
-	valueDec, err := compile(typ.Elem(), structName, fieldName, structTypeToDecoder)
+	valueDec, err := compile(typ.Elem(), structName, fieldName, ctx)
 	if err != nil {
 		return nil, err
 	}
 	return newMapDecoder(typ, typ.Key(), keyDec, typ.Elem(), valueDec, structName, fieldName), nil
 }
 
+func compileOrderedMap(layout *runtime.OrderedMapType, structName, fieldName string, ctx *compileContext) (Decoder, error) {
+	keyDec, err := compileMapKey(layout.Key, structName, fieldName, ctx)
+	if err != nil {
+		return nil, err
+	}
+	valueDec, err := compile(layout.Value, structName, fieldName, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newOrderedMapDecoder(layout, keyDec, valueDec, structName, fieldName), nil
+}
+
 func compileInterface(typ *runtime.Type, structName, fieldName string) (Decoder, error) {
 	return newInterfaceDecoder(typ, structName, fieldName), nil
 }
@@ -349,18 +390,20 @@ func typeToStructTags(typ *runtime.Type) runtime.StructTags {
 	return tags
 }
 
-func compileStruct(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
+func compileStruct(typ *runtime.Type, structName, fieldName string, ctx *compileContext) (Decoder, error) {
 	fieldNum := typ.NumField()
 	fieldMap := map[string]*structFieldSet{}
 	typeptr := uintptr(unsafe.Pointer(typ))
-	if dec, exists := structTypeToDecoder[typeptr]; exists {
+	if dec, exists := ctx.structTypeToDecoder[typeptr]; exists {
 		return dec, nil
 	}
 	structDec := newStructDecoder(structName, fieldName, fieldMap)
-	structTypeToDecoder[typeptr] = structDec
+	ctx.structTypeToDecoder[typeptr] = structDec
 	structName = typ.Name()
 	tags := typeToStructTags(typ)
 	allFields := []*structFieldSet{}
+	// the unknown fields of the embedded struct are used if the struct doesn't have them.
+	var embeddedUnknownFields *unknownFieldsDecoder
 	for i := 0; i < fieldNum; i++ {
 		field := typ.Field(i)
 		if runtime.IsIgnoredStructField(field) {
@@ -368,26 +411,57 @@ func compileStruct(typ *runtime.Type, structName, fieldName string, structTypeTo
 		}
 		isUnexportedField := unicode.IsLower([]rune(field.Name)[0])
 		tag := runtime.StructTagFromField(field)
-		dec, err := compile(runtime.Type2RType(field.Type), structName, field.Name, structTypeToDecoder)
-		if err != nil {
-			return nil, err
+		format, ok := tag.FieldFormat()
+		if !ok {
+			return nil, errors.ErrFieldFormat(field, tag.Format)
+		}
+		var dec Decoder
+		if format != runtime.FormatNone {
+			dec = compileFormat(tag, format, structName)
+		} else {
+			d, err := compile(runtime.Type2RType(field.Type), structName, field.Name, ctx)
+			if err != nil {
+				return nil, err
+			}
+			dec = d
+		}
+		if tag.IsInlineMap() {
+			mapDec, ok := dec.(*mapDecoder)
+			if !ok || !runtime.IsUnknownFieldsType(field.Type) {
+				if tag.IsUnknown {
+					return nil, errors.ErrUnknownFieldsType(field)
+				}
+				return nil, errors.ErrInlineFieldType(field)
+			}
+			if structDec.unknownFields == nil {
+				structDec.unknownFields = newUnknownFieldsDecoder(mapDec, field.Offset)
+			}
+			continue
 		}
-		if field.Anonymous && !tag.IsTaggedKey {
+		if tag.IsInline && !tag.IsInlineStruct() {
+			return nil, errors.ErrInlineFieldType(field)
+		}
+		if (field.Anonymous && !tag.IsTaggedKey) || tag.IsInlineStruct() {
 			if stDec, ok := dec.(*structDecoder); ok {
 				if runtime.Type2RType(field.Type) == typ {
 					// recursive definition
 					continue
 				}
+				if stDec.unknownFields != nil && embeddedUnknownFields == nil {
+					embeddedUnknownFields = newUnknownFieldsDecoder(stDec.unknownFields.mapDecoder, field.Offset+stDec.unknownFields.offset)
+				}
 				for k, v := range stDec.fieldMap {
 					if tags.ExistsKey(k) {
 						continue
 					}
 					fieldSet := &structFieldSet{
-						dec:         v.dec,
-						offset:      field.Offset + v.offset,
-						isTaggedKey: v.isTaggedKey,
-						key:         k,
-						keyLen:      int64(len(k)),
+						dec:          v.dec,
+						offset:       field.Offset + v.offset,
+						isTaggedKey:  v.isTaggedKey,
+						isStrictCase: v.isStrictCase,
+						isFoldedKey:  v.isFoldedKey || k != v.key,
+						key:          k,
+						keyLen:       int64(len(k)),
 					}
 					allFields = append(allFields, fieldSet)
 				}
@@ -410,32 +484,36 @@ func compileStruct(typ *runtime.Type, structName, fieldName string, structTypeTo
 							continue
 						}
 						fieldSet := &structFieldSet{
-							dec:         newAnonymousFieldDecoder(pdec.typ, v.offset, v.dec),
-							offset:      field.Offset,
-							isTaggedKey: v.isTaggedKey,
-							key:         k,
-							keyLen:      int64(len(k)),
-							err:         fieldSetErr,
+							dec:          newAnonymousFieldDecoder(pdec.typ, v.offset, v.dec),
+							offset:       field.Offset,
+							isTaggedKey:  v.isTaggedKey,
+							isStrictCase: v.isStrictCase,
+							isFoldedKey:  v.isFoldedKey || k != v.key,
+							key:          k,
+							keyLen:       int64(len(k)),
+							err:          fieldSetErr,
 						}
 						allFields = append(allFields, fieldSet)
 					}
 				} else {
 					fieldSet := &structFieldSet{
-						dec:         pdec,
-						offset:      field.Offset,
-						isTaggedKey: tag.IsTaggedKey,
-						key:         field.Name,
-						keyLen:      int64(len(field.Name)),
+						dec:          pdec,
+						offset:       field.Offset,
+						isTaggedKey:  tag.IsTaggedKey,
+						isStrictCase: tag.IsStrictCase,
+						key:          field.Name,
+						keyLen:       int64(len(field.Name)),
 					}
 					allFields = append(allFields, fieldSet)
 				}
 			} else {
 				fieldSet := &structFieldSet{
-					dec:         dec,
-					offset:      field.Offset,
-					isTaggedKey: tag.IsTaggedKey,
-					key:         field.Name,
-					keyLen:      int64(len(field.Name)),
+					dec:          dec,
+					offset:       field.Offset,
+					isTaggedKey:  tag.IsTaggedKey,
+					isStrictCase: tag.IsStrictCase,
+					key:          field.Name,
+					keyLen:       int64(len(field.Name)),
 				}
 				allFields = append(allFields, fieldSet)
 			}
@@ -450,24 +528,31 @@ func compileStruct(typ *runtime.Type, structName, fieldName string, structTypeTo
 				key = field.Name
 			}
 			fieldSet := &structFieldSet{
-				dec:         dec,
-				offset:      field.Offset,
-				isTaggedKey: tag.IsTaggedKey,
-				key:         key,
-				keyLen:      int64(len(key)),
+				dec:          dec,
+				offset:       field.Offset,
+				isTaggedKey:  tag.IsTaggedKey,
+				isStrictCase: tag.IsStrictCase,
+				key:          key,
+				keyLen:       int64(len(key)),
 			}
 			allFields = append(allFields, fieldSet)
 		}
 	}
 	for _, set := range filterDuplicatedFields(allFields) {
 		fieldMap[set.key] = set
+		if set.isStrictCase {
+			continue
+		}
 		lower := strings.ToLower(set.key)
 		if _, exists := fieldMap[lower]; !exists {
 			// first win
 			fieldMap[lower] = set
 		}
 	}
-	delete(structTypeToDecoder, typeptr)
+	if structDec.unknownFields == nil {
+		structDec.unknownFields = embeddedUnknownFields
+	}
+	delete(ctx.structTypeToDecoder, typeptr)
 	structDec.tryOptimize()
 	return structDec, nil
 }
@@ -132,7 +132,7 @@ func CompileToGetDecoderNoRace(typ *runtime.Type) (Decoder, error) {
 		return dec, nil
 	}
 
-	dec, err := compileHead(typ, map[uintptr]Decoder{})
+	dec, err := compileHead(typ, newCompileContext(nil))
 	if err != nil {
 		return nil, err
 	}
@@ -157,7 +157,7 @@ func CompileToGetDecoderRace(typ *runtime.Type) (Decoder, error) {
 	}
 	decMu_test.RUnlock()
 
-	dec, err := compileHead(typ, map[uintptr]Decoder{})
+	dec, err := compileHead(typ, newCompileContext(nil))
 	if err != nil {
 		return nil, err
 	}
@@ -194,7 +194,7 @@ func CompileToGetDecoderAtomic(typ *runtime.Type) (Decoder, error) {
 		return *dec, nil
 	}
 
-	dec, err := compileHead(typ, map[uintptr]Decoder{})
+	dec, err := compileHead(typ, newCompileContext(nil))
 	if err != nil {
 		return nil, err
 	}
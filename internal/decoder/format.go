@@ -0,0 +1,347 @@
+package decoder
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/errors"
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+// compileFormat compiles the decoder for the field that has the format option.
+// If the field is the pointer, the value is allocated by the pointer decoder.
+func compileFormat(field *runtime.StructTag, format runtime.FieldFormat, structName string) Decoder {
+	typ := runtime.Type2RType(field.Field.Type)
+	isPtr := false
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+		isPtr = true
+	}
+	fieldName := field.Field.Name
+	var dec Decoder
+	switch format {
+	case runtime.FormatUnix:
+		dec = newUnixTimeDecoder(typ, time.Second, structName, fieldName)
+	case runtime.FormatUnixMilli:
+		dec = newUnixTimeDecoder(typ, time.Millisecond, structName, fieldName)
+	case runtime.FormatHex:
+		dec = newFormatBytesDecoder(typ, decodeHex, structName, fieldName)
+	case runtime.FormatBase64URL:
+		dec = newFormatBytesDecoder(typ, decodeBase64URL, structName, fieldName)
+	case runtime.FormatUnits:
+		dec = newDurationDecoder(typ, structName, fieldName)
+	}
+	if isPtr {
+		return newPtrDecoder(dec, typ, structName, fieldName)
+	}
+	return dec
+}
+
+// unixTimeDecoder decodes the number of units since the Unix epoch to time.Time in UTC.
+// The fraction of the unit is decoded up to nanoseconds.
+type unixTimeDecoder struct {
+	typ          *runtime.Type
+	unit         time.Duration
+	floatDecoder *floatDecoder
+	structName   string
+	fieldName    string
+}
+
+func newUnixTimeDecoder(typ *runtime.Type, unit time.Duration, structName, fieldName string) *unixTimeDecoder {
+	return &unixTimeDecoder{
+		typ:          typ,
+		unit:         unit,
+		floatDecoder: newFloatDecoder(structName, fieldName, nil),
+		structName:   structName,
+		fieldName:    fieldName,
+	}
+}
+
+func (d *unixTimeDecoder) typeError(bytes []byte, offset int64) *errors.UnmarshalTypeError {
+	return &errors.UnmarshalTypeError{
+		Value:  fmt.Sprintf("number %s", bytes),
+		Type:   runtime.RType2Type(d.typ),
+		Struct: d.structName,
+		Field:  d.fieldName,
+		Offset: offset,
+	}
+}
+
+func (d *unixTimeDecoder) errUnmarshalType(typeName string, offset int64) *errors.UnmarshalTypeError {
+	return &errors.UnmarshalTypeError{
+		Value:  typeName,
+		Type:   runtime.RType2Type(d.typ),
+		Struct: d.structName,
+		Field:  d.fieldName,
+		Offset: offset,
+	}
+}
+
+// nonNumberKind returns the kind name of the value that begins with c if it is neither number nor null.
+func nonNumberKind(c byte) string {
+	switch c {
+	case '"':
+		return "string"
+	case '[':
+		return "array"
+	case '{':
+		return "object"
+	case 't', 'f':
+		return "bool"
+	}
+	return ""
+}
+
+// parse parses the number such as 1700000000 or 1700000000.5 without the loss of float64.
+func (d *unixTimeDecoder) parse(bytes []byte) (time.Time, bool) {
+	s := *(*string)(unsafe.Pointer(&bytes))
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		v := math.Trunc(f)
+		if v < math.MinInt64 || v >= math.MaxInt64 {
+			return time.Time{}, false
+		}
+		return d.toTime(int64(v), int64((f-v)*1e9)), true
+	}
+	intPart, frac := s, ""
+	if idx := strings.IndexByte(s, '.'); idx >= 0 {
+		intPart, frac = s[:idx], s[idx+1:]
+		if frac == "" {
+			return time.Time{}, false
+		}
+	}
+	v, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	for i := 0; i < len(frac); i++ {
+		if frac[i] < '0' || '9' < frac[i] {
+			return time.Time{}, false
+		}
+	}
+	var fracNano int64
+	for i := 0; i < 9; i++ {
+		fracNano *= 10
+		if i < len(frac) {
+			fracNano += int64(frac[i] - '0')
+		}
+	}
+	if intPart[0] == '-' {
+		fracNano = -fracNano
+	}
+	return d.toTime(v, fracNano), true
+}
+
+// toTime converts v and the fraction of the unit in nanoseconds ( 1e-9 unit ) to time.Time.
+func (d *unixTimeDecoder) toTime(v, fracNano int64) time.Time {
+	perSec := int64(time.Second / d.unit)
+	sec, nsec := v/perSec, (v%perSec)*int64(d.unit)+fracNano*int64(d.unit)/int64(time.Second)
+	return time.Unix(sec, nsec).UTC()
+}
+
+func (d *unixTimeDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) error {
+	if kind := nonNumberKind(s.skipWhiteSpace()); kind != "" {
+		return d.errUnmarshalType(kind, s.totalOffset())
+	}
+	bytes, err := d.floatDecoder.decodeStreamByte(s)
+	if err != nil {
+		return err
+	}
+	if bytes == nil {
+		return nil
+	}
+	t, ok := d.parse(bytes)
+	if !ok {
+		return d.typeError(bytes, s.totalOffset())
+	}
+	*(*time.Time)(p) = t
+	s.reset()
+	return nil
+}
+
+func (d *unixTimeDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsafe.Pointer) (int64, error) {
+	buf := ctx.Buf
+	cursor = skipWhiteSpace(buf, cursor)
+	if kind := nonNumberKind(buf[cursor]); kind != "" {
+		return 0, d.errUnmarshalType(kind, cursor)
+	}
+	bytes, c, err := d.floatDecoder.decodeByte(buf, cursor)
+	if err != nil {
+		return 0, err
+	}
+	if bytes == nil {
+		return c, nil
+	}
+	if !validEndNumberChar[buf[c]] {
+		return 0, errors.ErrUnexpectedEndOfJSON("number", c)
+	}
+	t, ok := d.parse(bytes)
+	if !ok {
+		return 0, d.typeError(bytes, c)
+	}
+	*(*time.Time)(p) = t
+	return c, nil
+}
+
+func (d *unixTimeDecoder) DecodePath(ctx *RuntimeContext, cursor, depth int64) ([][]byte, int64, error) {
+	return nil, 0, fmt.Errorf("json: unix time decoder does not support decode path")
+}
+
+func decodeHex(src []byte) ([]byte, error) {
+	dst := make([]byte, hex.DecodedLen(len(src)))
+	n, err := hex.Decode(dst, src)
+	return dst[:n], err
+}
+
+// decodeBase64URL decodes both of the padded and the unpadded URL-safe base64 encoding.
+func decodeBase64URL(src []byte) ([]byte, error) {
+	enc := base64.URLEncoding
+	if len(src)%4 != 0 {
+		enc = base64.RawURLEncoding
+	}
+	dst := make([]byte, enc.DecodedLen(len(src)))
+	n, err := enc.Decode(dst, src)
+	return dst[:n], err
+}
+
+// formatBytesDecoder decodes the string that is encoded by the format to []byte.
+type formatBytesDecoder struct {
+	typ           *runtime.Type
+	decode        func([]byte) ([]byte, error)
+	stringDecoder *stringDecoder
+	structName    string
+	fieldName     string
+}
+
+func newFormatBytesDecoder(typ *runtime.Type, decode func([]byte) ([]byte, error), structName, fieldName string) *formatBytesDecoder {
+	return &formatBytesDecoder{
+		typ:           typ,
+		decode:        decode,
+		stringDecoder: newStringDecoder(structName, fieldName),
+		structName:    structName,
+		fieldName:     fieldName,
+	}
+}
+
+func (d *formatBytesDecoder) typeError(bytes []byte, offset int64) *errors.UnmarshalTypeError {
+	return &errors.UnmarshalTypeError{
+		Value:  fmt.Sprintf("string %q", bytes),
+		Type:   runtime.RType2Type(d.typ),
+		Struct: d.structName,
+		Field:  d.fieldName,
+		Offset: offset,
+	}
+}
+
+func (d *formatBytesDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) error {
+	bytes, err := d.stringDecoder.decodeStreamByte(s)
+	if err != nil {
+		return err
+	}
+	if bytes == nil {
+		s.reset()
+		return nil
+	}
+	b, err := d.decode(bytes)
+	if err != nil {
+		return d.typeError(bytes, s.totalOffset())
+	}
+	*(*[]byte)(p) = b
+	s.reset()
+	return nil
+}
+
+func (d *formatBytesDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsafe.Pointer) (int64, error) {
+	bytes, c, err := d.stringDecoder.decodeByte(ctx.Buf, cursor)
+	if err != nil {
+		return 0, err
+	}
+	if bytes == nil {
+		return c, nil
+	}
+	b, err := d.decode(bytes)
+	if err != nil {
+		return 0, d.typeError(bytes, c)
+	}
+	*(*[]byte)(p) = b
+	return c, nil
+}
+
+func (d *formatBytesDecoder) DecodePath(ctx *RuntimeContext, cursor, depth int64) ([][]byte, int64, error) {
+	return nil, 0, fmt.Errorf("json: []byte decoder does not support decode path")
+}
+
+// durationDecoder decodes the string such as "1h30m" to time.Duration.
+type durationDecoder struct {
+	typ           *runtime.Type
+	stringDecoder *stringDecoder
+	structName    string
+	fieldName     string
+}
+
+func newDurationDecoder(typ *runtime.Type, structName, fieldName string) *durationDecoder {
+	return &durationDecoder{
+		typ:           typ,
+		stringDecoder: newStringDecoder(structName, fieldName),
+		structName:    structName,
+		fieldName:     fieldName,
+	}
+}
+
+func (d *durationDecoder) typeError(bytes []byte, offset int64) *errors.UnmarshalTypeError {
+	return &errors.UnmarshalTypeError{
+		Value:  fmt.Sprintf("string %q", bytes),
+		Type:   runtime.RType2Type(d.typ),
+		Struct: d.structName,
+		Field:  d.fieldName,
+		Offset: offset,
+	}
+}
+
+func (d *durationDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) error {
+	bytes, err := d.stringDecoder.decodeStreamByte(s)
+	if err != nil {
+		return err
+	}
+	if bytes == nil {
+		s.reset()
+		return nil
+	}
+	v, err := time.ParseDuration(string(bytes))
+	if err != nil {
+		return d.typeError(bytes, s.totalOffset())
+	}
+	*(*time.Duration)(p) = v
+	s.reset()
+	return nil
+}
+
+func (d *durationDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsafe.Pointer) (int64, error) {
+	bytes, c, err := d.stringDecoder.decodeByte(ctx.Buf, cursor)
+	if err != nil {
+		return 0, err
+	}
+	if bytes == nil {
+		return c, nil
+	}
+	v, err := time.ParseDuration(string(bytes))
+	if err != nil {
+		return 0, d.typeError(bytes, c)
+	}
+	*(*time.Duration)(p) = v
+	return c, nil
+}
+
+func (d *durationDecoder) DecodePath(ctx *RuntimeContext, cursor, depth int64) ([][]byte, int64, error) {
+	return nil, 0, fmt.Errorf("json: duration decoder does not support decode path")
+}
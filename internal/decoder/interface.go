@@ -86,7 +86,7 @@ func newInterfaceDecoder(typ *runtime.Type, structName, fieldName string) *inter
 }
 
 func (d *interfaceDecoder) numDecoder(s *Stream) Decoder {
-	if s.UseNumber {
+	if s.UseNumber || (s.Option.Flags&UseNumberOption) != 0 {
 		return d.numberDecoder
 	}
 	return d.floatDecoder
@@ -293,6 +293,9 @@ func (d *interfaceDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer
 	}))
 	rv := reflect.ValueOf(runtimeInterfaceValue)
 	if rv.NumMethod() > 0 && rv.CanInterface() {
+		if u, ok := rv.Interface().(unmarshalerFrom); ok {
+			return decodeStreamUnmarshalerFrom(s, depth, u)
+		}
 		if u, ok := rv.Interface().(unmarshalerContext); ok {
 			return decodeStreamUnmarshalerContext(s, depth, u)
 		}
@@ -328,7 +331,7 @@ func (d *interfaceDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer
 		*(*interface{})(p) = nil
 		return nil
 	}
-	decoder, err := CompileToGetDecoder(typ)
+	decoder, err := CompileToGetDecoderWithOption(typ, s.Option)
 	if err != nil {
 		return err
 	}
@@ -353,6 +356,9 @@ func (d *interfaceDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p un
 	}))
 	rv := reflect.ValueOf(runtimeInterfaceValue)
 	if rv.NumMethod() > 0 && rv.CanInterface() {
+		if u, ok := rv.Interface().(unmarshalerFrom); ok {
+			return decodeUnmarshalerFrom(ctx, buf, cursor, depth, u)
+		}
 		if u, ok := rv.Interface().(unmarshalerContext); ok {
 			return decodeUnmarshalerContext(ctx, buf, cursor, depth, u)
 		}
@@ -393,7 +399,7 @@ func (d *interfaceDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p un
 		**(**interface{})(unsafe.Pointer(&p)) = nil
 		return cursor, nil
 	}
-	decoder, err := CompileToGetDecoder(typ)
+	decoder, err := CompileToGetDecoderWithOption(typ, ctx.Option)
 	if err != nil {
 		return 0, err
 	}
@@ -423,6 +429,9 @@ func (d *interfaceDecoder) decodeEmptyInterface(ctx *RuntimeContext, cursor, dep
 		**(**interface{})(unsafe.Pointer(&p)) = v
 		return cursor, nil
 	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		if (ctx.Option.Flags & UseNumberOption) != 0 {
+			return d.numberDecoder.Decode(ctx, cursor, depth, p)
+		}
 		return d.floatDecoder.Decode(ctx, cursor, depth, p)
 	case '"':
 		var v string
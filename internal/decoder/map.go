@@ -15,6 +15,7 @@ type mapDecoder struct {
 	canUseAssignFaststrType bool
 	keyDecoder              Decoder
 	valueDecoder            Decoder
+	keySet                  keySet
 	structName              string
 	fieldName               string
 }
@@ -27,6 +28,7 @@ func newMapDecoder(mapType *runtime.Type, keyType *runtime.Type, keyDec Decoder,
 		canUseAssignFaststrType: canUseAssignFaststrType(keyType, valueType),
 		valueType:               valueType,
 		valueDecoder:            valueDec,
+		keySet:                  newKeySet(keyType),
 		structName:              structName,
 		fieldName:               fieldName,
 	}
@@ -66,6 +68,39 @@ func (d *mapDecoder) mapassign(t *runtime.Type, m, k, v unsafe.Pointer) {
 	}
 }
 
+// keySet builds the set of the keys of an object to detect the duplicate keys for DisallowDuplicateKeysOption.
+// The set is the map[K]struct{}, so the keys are compared in the same way as the decoded map.
+type keySet struct {
+	typ         *runtime.Type
+	isStringKey bool
+}
+
+func newKeySet(keyType *runtime.Type) keySet {
+	return keySet{
+		typ:         runtime.Type2RType(reflect.MapOf(runtime.RType2Type(keyType), reflect.TypeOf(struct{}{}))),
+		isStringKey: keyType.Kind() == reflect.String,
+	}
+}
+
+// newSet returns the empty set if the duplicate keys are disallowed. Otherwise it returns nil.
+func (s keySet) newSet(opt *Option) unsafe.Pointer {
+	if opt.Flags&DisallowDuplicateKeysOption == 0 {
+		return nil
+	}
+	return makemap(s.typ, 0)
+}
+
+// add adds the key k to the set m and reports whether k has been already added.
+func (s keySet) add(m, k unsafe.Pointer) bool {
+	n := maplen(m)
+	if s.isStringKey {
+		mapassign_faststr(s.typ, m, *(*string)(k))
+	} else {
+		mapassignptr(s.typ, m, k)
+	}
+	return maplen(m) == n
+}
+
 func (d *mapDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) error {
 	depth++
 	if depth > maxDecodeNestingDepth {
@@ -93,11 +128,20 @@ func (d *mapDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) erro
 		s.cursor++
 		return nil
 	}
+	seenKeys := d.keySet.newSet(s.Option)
 	for {
 		k := unsafe_New(d.keyType)
+		var keyOffset int64
+		if seenKeys != nil {
+			s.skipWhiteSpace()
+			keyOffset = s.totalOffset()
+		}
 		if err := d.keyDecoder.DecodeStream(s, depth, k); err != nil {
 			return err
 		}
+		if seenKeys != nil && d.keySet.add(seenKeys, k) {
+			return errors.ErrDuplicateKey(mapKeyToken(d.keyType, k), keyOffset)
+		}
 		s.skipWhiteSpace()
 		if !s.equalChar(':') {
 			return errors.ErrExpected("colon after object key", s.totalOffset())
@@ -105,7 +149,7 @@ func (d *mapDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) erro
 		s.cursor++
 		v := unsafe_New(d.valueType)
 		if err := d.valueDecoder.DecodeStream(s, depth, v); err != nil {
-			return err
+			return withPointerToken(err, mapKeyToken(d.keyType, k))
 		}
 		d.mapassign(d.mapType, mapValue, k, v)
 		s.skipWhiteSpace()
@@ -156,23 +200,39 @@ func (d *mapDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsafe.P
 		cursor++
 		return cursor, nil
 	}
+	seenKeys := d.keySet.newSet(ctx.Option)
 	for {
 		k := unsafe_New(d.keyType)
 		keyCursor, err := d.keyDecoder.Decode(ctx, cursor, depth, k)
 		if err != nil {
 			return 0, err
 		}
+		if seenKeys != nil && d.keySet.add(seenKeys, k) {
+			return 0, errors.ErrDuplicateKey(mapKeyToken(d.keyType, k), skipWhiteSpace(buf, cursor))
+		}
 		cursor = skipWhiteSpace(buf, keyCursor)
 		if buf[cursor] != ':' {
 			return 0, errors.ErrExpected("colon after object key", cursor)
 		}
 		cursor++
 		v := unsafe_New(d.valueType)
+		mark := ctx.Option.errorMark()
 		valueCursor, err := d.valueDecoder.Decode(ctx, cursor, depth, v)
+		if ctx.Option.collectedSince(mark) {
+			// the key token is only built when errors are collected in the value.
+			ctx.Option.addPointerToken(mark, mapKeyToken(d.keyType, k))
+		}
 		if err != nil {
-			return 0, err
+			err = withPointerToken(err, mapKeyToken(d.keyType, k))
+			if !ctx.Option.collectError(err) {
+				return 0, err
+			}
+			if valueCursor, err = skipValue(buf, cursor, depth); err != nil {
+				return 0, err
+			}
+		} else {
+			d.mapassign(d.mapType, mapValue, k, v)
 		}
-		d.mapassign(d.mapType, mapValue, k, v)
 		cursor = skipWhiteSpace(buf, valueCursor)
 		if buf[cursor] == '}' {
 			**(**unsafe.Pointer)(unsafe.Pointer(&p)) = mapValue
@@ -225,6 +285,17 @@ func (d *mapDecoder) DecodePath(ctx *RuntimeContext, cursor, depth int64) ([][]b
 			Field:  d.fieldName,
 		}
 	}
+	if node, ok := ctx.Option.Path.node.(*PathUnionNode); ok {
+		c, end, err := scanRawPathObject(buf, keyDecoder, cursor, depth)
+		if err != nil {
+			return nil, 0, err
+		}
+		paths, err := decodePathUnion(ctx, node, c, d.valueDecoder, depth)
+		if err != nil {
+			return nil, 0, err
+		}
+		return paths, end, nil
+	}
 	ret := [][]byte{}
 	for {
 		key, keyCursor, err := keyDecoder.decodeByte(buf, cursor)
@@ -0,0 +1,273 @@
+package decoder
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/dom"
+	"github.com/goccy/go-json/internal/errors"
+)
+
+var nodeType = reflect.TypeOf(dom.Node{})
+
+// nodeDecoder decodes any JSON value to the ordered document tree.
+// The members of object keep the order and the duplicate names, and the numbers keep the literals.
+type nodeDecoder struct {
+	stringDecoder *stringDecoder
+	structName    string
+	fieldName     string
+}
+
+func newNodeDecoder(structName, fieldName string) *nodeDecoder {
+	return &nodeDecoder{
+		stringDecoder: newStringDecoder(structName, fieldName),
+		structName:    structName,
+		fieldName:     fieldName,
+	}
+}
+
+func (d *nodeDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) error {
+	return d.decodeStream(s, depth, (*dom.Node)(p))
+}
+
+func (d *nodeDecoder) decodeStream(s *Stream, depth int64, n *dom.Node) error {
+	switch s.skipWhiteSpace() {
+	case '{':
+		return d.decodeStreamObject(s, depth+1, n)
+	case '[':
+		return d.decodeStreamArray(s, depth+1, n)
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		start := s.totalOffset()
+		literal := string(floatBytes(s))
+		if !dom.IsNumber(literal) {
+			return errors.ErrSyntax(fmt.Sprintf("invalid number literal %q", literal), start)
+		}
+		*n = dom.NumberOf(literal)
+		return nil
+	case '"':
+		v, err := d.stringDecoder.decodeStreamByte(s)
+		if err != nil {
+			return err
+		}
+		*n = dom.StringOf(string(v))
+		return nil
+	case 't':
+		if err := trueBytes(s); err != nil {
+			return err
+		}
+		*n = dom.BoolOf(true)
+		return nil
+	case 'f':
+		if err := falseBytes(s); err != nil {
+			return err
+		}
+		*n = dom.BoolOf(false)
+		return nil
+	case 'n':
+		if err := nullBytes(s); err != nil {
+			return err
+		}
+		*n = dom.Node{}
+		return nil
+	}
+	return errors.ErrInvalidBeginningOfValue(s.char(), s.totalOffset())
+}
+
+func (d *nodeDecoder) decodeStreamObject(s *Stream, depth int64, n *dom.Node) error {
+	if depth > maxDecodeNestingDepth {
+		return errors.ErrExceededMaxDepth(s.char(), s.cursor)
+	}
+	s.cursor++
+	members := []dom.Member{}
+	if s.skipWhiteSpace() == '}' {
+		s.cursor++
+		*n = dom.ObjectOf(members)
+		return nil
+	}
+	for {
+		if s.skipWhiteSpace() != '"' {
+			return errors.ErrExpected("object key", s.totalOffset())
+		}
+		key, err := d.stringDecoder.decodeStreamByte(s)
+		if err != nil {
+			return err
+		}
+		m := dom.Member{Key: string(key), Value: &dom.Node{}}
+		if s.skipWhiteSpace() != ':' {
+			return errors.ErrExpected("colon after object key", s.totalOffset())
+		}
+		s.cursor++
+		if err := d.decodeStream(s, depth, m.Value); err != nil {
+			return err
+		}
+		members = append(members, m)
+		switch s.skipWhiteSpace() {
+		case '}':
+			s.cursor++
+			*n = dom.ObjectOf(members)
+			return nil
+		case ',':
+			s.cursor++
+		default:
+			return errors.ErrExpected("comma after object value", s.totalOffset())
+		}
+	}
+}
+
+func (d *nodeDecoder) decodeStreamArray(s *Stream, depth int64, n *dom.Node) error {
+	if depth > maxDecodeNestingDepth {
+		return errors.ErrExceededMaxDepth(s.char(), s.cursor)
+	}
+	s.cursor++
+	elems := []*dom.Node{}
+	if s.skipWhiteSpace() == ']' {
+		s.cursor++
+		*n = dom.ArrayOf(elems)
+		return nil
+	}
+	for {
+		elem := &dom.Node{}
+		if err := d.decodeStream(s, depth, elem); err != nil {
+			return err
+		}
+		elems = append(elems, elem)
+		switch s.skipWhiteSpace() {
+		case ']':
+			s.cursor++
+			*n = dom.ArrayOf(elems)
+			return nil
+		case ',':
+			s.cursor++
+		default:
+			return errors.ErrInvalidCharacter(s.char(), "slice", s.totalOffset())
+		}
+	}
+}
+
+func (d *nodeDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsafe.Pointer) (int64, error) {
+	return d.decode(ctx.Buf, cursor, depth, (*dom.Node)(p))
+}
+
+func (d *nodeDecoder) decode(buf []byte, cursor, depth int64, n *dom.Node) (int64, error) {
+	cursor = skipWhiteSpace(buf, cursor)
+	switch buf[cursor] {
+	case '{':
+		return d.decodeObject(buf, cursor, depth+1, n)
+	case '[':
+		return d.decodeArray(buf, cursor, depth+1, n)
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		start := cursor
+		cursor++
+		for floatTable[buf[cursor]] {
+			cursor++
+		}
+		literal := string(buf[start:cursor])
+		if !dom.IsNumber(literal) {
+			return 0, errors.ErrSyntax(fmt.Sprintf("invalid number literal %q", literal), start)
+		}
+		*n = dom.NumberOf(literal)
+		return cursor, nil
+	case '"':
+		v, c, err := d.stringDecoder.decodeByte(buf, cursor)
+		if err != nil {
+			return 0, err
+		}
+		*n = dom.StringOf(string(v))
+		return c, nil
+	case 't':
+		if err := validateTrue(buf, cursor); err != nil {
+			return 0, err
+		}
+		*n = dom.BoolOf(true)
+		return cursor + 4, nil
+	case 'f':
+		if err := validateFalse(buf, cursor); err != nil {
+			return 0, err
+		}
+		*n = dom.BoolOf(false)
+		return cursor + 5, nil
+	case 'n':
+		if err := validateNull(buf, cursor); err != nil {
+			return 0, err
+		}
+		*n = dom.Node{}
+		return cursor + 4, nil
+	}
+	return 0, errors.ErrInvalidBeginningOfValue(buf[cursor], cursor)
+}
+
+func (d *nodeDecoder) decodeObject(buf []byte, cursor, depth int64, n *dom.Node) (int64, error) {
+	if depth > maxDecodeNestingDepth {
+		return 0, errors.ErrExceededMaxDepth(buf[cursor], cursor)
+	}
+	cursor = skipWhiteSpace(buf, cursor+1)
+	members := []dom.Member{}
+	if buf[cursor] == '}' {
+		*n = dom.ObjectOf(members)
+		return cursor + 1, nil
+	}
+	for {
+		if buf[cursor] != '"' {
+			return 0, errors.ErrExpected("object key", cursor)
+		}
+		key, keyCursor, err := d.stringDecoder.decodeByte(buf, cursor)
+		if err != nil {
+			return 0, err
+		}
+		cursor = skipWhiteSpace(buf, keyCursor)
+		if buf[cursor] != ':' {
+			return 0, errors.ErrExpected("colon after object key", cursor)
+		}
+		m := dom.Member{Key: string(key), Value: &dom.Node{}}
+		cursor, err = d.decode(buf, cursor+1, depth, m.Value)
+		if err != nil {
+			return 0, err
+		}
+		members = append(members, m)
+		cursor = skipWhiteSpace(buf, cursor)
+		switch buf[cursor] {
+		case '}':
+			*n = dom.ObjectOf(members)
+			return cursor + 1, nil
+		case ',':
+			cursor = skipWhiteSpace(buf, cursor+1)
+		default:
+			return 0, errors.ErrExpected("comma after object value", cursor)
+		}
+	}
+}
+
+func (d *nodeDecoder) decodeArray(buf []byte, cursor, depth int64, n *dom.Node) (int64, error) {
+	if depth > maxDecodeNestingDepth {
+		return 0, errors.ErrExceededMaxDepth(buf[cursor], cursor)
+	}
+	cursor = skipWhiteSpace(buf, cursor+1)
+	elems := []*dom.Node{}
+	if buf[cursor] == ']' {
+		*n = dom.ArrayOf(elems)
+		return cursor + 1, nil
+	}
+	for {
+		elem := &dom.Node{}
+		c, err := d.decode(buf, cursor, depth, elem)
+		if err != nil {
+			return 0, err
+		}
+		elems = append(elems, elem)
+		cursor = skipWhiteSpace(buf, c)
+		switch buf[cursor] {
+		case ']':
+			*n = dom.ArrayOf(elems)
+			return cursor + 1, nil
+		case ',':
+			cursor++
+		default:
+			return 0, errors.ErrInvalidCharacter(buf[cursor], "slice", cursor)
+		}
+	}
+}
+
+func (d *nodeDecoder) DecodePath(ctx *RuntimeContext, cursor, depth int64) ([][]byte, int64, error) {
+	return nil, 0, fmt.Errorf("json: node decoder does not support decode path")
+}
@@ -1,17 +1,77 @@
 package decoder
 
-import "context"
+import (
+	"context"
 
-type OptionFlags uint8
+	"github.com/goccy/go-json/internal/errors"
+)
+
+type OptionFlags uint16
 
 const (
 	FirstWinOption OptionFlags = 1 << iota
 	ContextOption
 	PathOption
+	DisallowUnknownFieldsOption
+	UseNumberOption
+	CollectErrorsOption
+	CaseSensitiveOption
+	DisallowDuplicateKeysOption
+	UnmarshalFuncOption
 )
 
 type Option struct {
 	Flags   OptionFlags
 	Context context.Context
 	Path    *Path
+	Errors  []error // errors collected by CollectErrorsOption
+
+	unmarshalFuncs    []*TypeUnmarshalFunc
+	unmarshalFuncsKey []byte
+}
+
+// errorMark returns the number of the collected errors, or -1 if errors are not collected.
+func (o *Option) errorMark() int {
+	if o.Flags&CollectErrorsOption == 0 {
+		return -1
+	}
+	return len(o.Errors)
+}
+
+// collectError records err and reports whether the decoding can continue by skipping the value.
+// Only type errors are collected because the input is still valid JSON.
+func (o *Option) collectError(err error) bool {
+	if o.Flags&CollectErrorsOption == 0 {
+		return false
+	}
+	if _, ok := err.(*errors.UnmarshalTypeError); !ok {
+		return false
+	}
+	o.Errors = append(o.Errors, err)
+	return true
+}
+
+// collectedSince reports whether any error has been collected since mark.
+func (o *Option) collectedSince(mark int) bool {
+	return mark >= 0 && len(o.Errors) > mark
+}
+
+// addPointerToken prepends token to the JSON Pointer of the errors collected since mark.
+func (o *Option) addPointerToken(mark int, token string) {
+	if mark < 0 {
+		return
+	}
+	for _, err := range o.Errors[mark:] {
+		withPointerToken(err, token)
+	}
+}
+
+// addPointerIndex prepends idx to the JSON Pointer of the errors collected since mark.
+func (o *Option) addPointerIndex(mark int, idx int) {
+	if mark < 0 {
+		return
+	}
+	for _, err := range o.Errors[mark:] {
+		withPointerIndex(err, idx)
+	}
 }
@@ -0,0 +1,255 @@
+package decoder
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/errors"
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+// orderedMapDecoder decodes the object to json.OrderedMap.
+// The members are appended to the entries in document order, and the index keeps the position of each key.
+// If the key already exists, the value of the entry is replaced and the entry keeps the position.
+type orderedMapDecoder struct {
+	layout       *runtime.OrderedMapType
+	keyDecoder   Decoder
+	valueDecoder Decoder
+	isStringKey  bool
+	keySet       keySet
+	zeroEntry    unsafe.Pointer
+	structName   string
+	fieldName    string
+}
+
+func newOrderedMapDecoder(layout *runtime.OrderedMapType, keyDec, valueDec Decoder, structName, fieldName string) *orderedMapDecoder {
+	return &orderedMapDecoder{
+		layout:       layout,
+		keyDecoder:   keyDec,
+		valueDecoder: valueDec,
+		isStringKey:  layout.Key.Kind() == reflect.String,
+		keySet:       newKeySet(layout.Key),
+		zeroEntry:    unsafe_New(layout.Entry),
+		structName:   structName,
+		fieldName:    fieldName,
+	}
+}
+
+// maplen and mapassignptr are linked to the same functions as encoder.MapLen and mapassign_faststr,
+// which the runtime keeps linkable for this package.
+// commit looks up and adds the key by one mapassign call, and a duplicate key is detected by the length of the index
+// that doesn't grow, so the key is hashed only once instead of a lookup followed by an assignment.
+//
+//go:linkname maplen reflect.maplen
+//go:noescape
+func maplen(m unsafe.Pointer) int
+
+//go:linkname mapassignptr runtime.mapassign
+//go:noescape
+func mapassignptr(t *runtime.Type, m unsafe.Pointer, k unsafe.Pointer) unsafe.Pointer
+
+// entries returns the entries of json.OrderedMap that is the first field.
+func (d *orderedMapDecoder) entries(p unsafe.Pointer) *sliceHeader {
+	return (*sliceHeader)(p)
+}
+
+func (d *orderedMapDecoder) index(p unsafe.Pointer) *unsafe.Pointer {
+	return (*unsafe.Pointer)(unsafe.Pointer(uintptr(p) + d.layout.IndexOffset))
+}
+
+func (d *orderedMapDecoder) reset(p unsafe.Pointer) {
+	*d.entries(p) = sliceHeader{}
+	*d.index(p) = nil
+}
+
+func (d *orderedMapDecoder) prepare(p unsafe.Pointer) {
+	if *d.index(p) == nil {
+		*d.index(p) = makemap(d.layout.Index, 0)
+	}
+}
+
+// nextEntry returns the entry after the last entry. The entries are grown if there is no space.
+func (d *orderedMapDecoder) nextEntry(p unsafe.Pointer) unsafe.Pointer {
+	entries := d.entries(p)
+	if entries.len == entries.cap {
+		newcap := entries.cap * 2
+		if newcap < defaultSliceCapacity {
+			newcap = defaultSliceCapacity
+		}
+		dst := sliceHeader{data: newArray(d.layout.Entry, newcap), len: entries.len, cap: newcap}
+		copySlice(d.layout.Entry, dst, *entries)
+		*entries = dst
+	}
+	return unsafe.Pointer(uintptr(entries.data) + uintptr(entries.len)*d.layout.Entry.Size())
+}
+
+// commit adds the entry after the last entry to the index.
+// If the key already exists, the value is moved to the existing entry and the entry after the last entry is cleared.
+func (d *orderedMapDecoder) commit(p, entry unsafe.Pointer) {
+	entries := d.entries(p)
+	index := *d.index(p)
+	n := maplen(index)
+	var pos unsafe.Pointer
+	if d.isStringKey {
+		pos = mapassign_faststr(d.layout.Index, index, *(*string)(entry))
+	} else {
+		pos = mapassignptr(d.layout.Index, index, entry)
+	}
+	if maplen(index) == n {
+		existing := unsafe.Pointer(uintptr(entries.data) + uintptr(*(*int)(pos))*d.layout.Entry.Size())
+		typedmemmove(d.layout.Value, d.value(existing), d.value(entry))
+		d.clear(entry)
+		return
+	}
+	*(*int)(pos) = entries.len
+	entries.len++
+}
+
+func (d *orderedMapDecoder) value(entry unsafe.Pointer) unsafe.Pointer {
+	return unsafe.Pointer(uintptr(entry) + d.layout.ValueOffset)
+}
+
+// clear releases the references from the entry that is not added.
+func (d *orderedMapDecoder) clear(entry unsafe.Pointer) {
+	typedmemmove(d.layout.Entry, entry, d.zeroEntry)
+}
+
+func (d *orderedMapDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) error {
+	depth++
+	if depth > maxDecodeNestingDepth {
+		return errors.ErrExceededMaxDepth(s.char(), s.cursor)
+	}
+
+	switch s.skipWhiteSpace() {
+	case 'n':
+		if err := nullBytes(s); err != nil {
+			return err
+		}
+		d.reset(p)
+		return nil
+	case '{':
+	default:
+		return errors.ErrExpected("{ character for map value", s.totalOffset())
+	}
+	d.prepare(p)
+	s.cursor++
+	if s.skipWhiteSpace() == '}' {
+		s.cursor++
+		return nil
+	}
+	seenKeys := d.keySet.newSet(s.Option)
+	for {
+		entry := d.nextEntry(p)
+		var keyOffset int64
+		if seenKeys != nil {
+			s.skipWhiteSpace()
+			keyOffset = s.totalOffset()
+		}
+		if err := d.keyDecoder.DecodeStream(s, depth, entry); err != nil {
+			d.clear(entry)
+			return err
+		}
+		if seenKeys != nil && d.keySet.add(seenKeys, entry) {
+			err := errors.ErrDuplicateKey(mapKeyToken(d.layout.Key, entry), keyOffset)
+			d.clear(entry)
+			return err
+		}
+		s.skipWhiteSpace()
+		if !s.equalChar(':') {
+			d.clear(entry)
+			return errors.ErrExpected("colon after object key", s.totalOffset())
+		}
+		s.cursor++
+		if err := d.valueDecoder.DecodeStream(s, depth, d.value(entry)); err != nil {
+			err = withPointerToken(err, mapKeyToken(d.layout.Key, entry))
+			d.clear(entry)
+			return err
+		}
+		d.commit(p, entry)
+		s.skipWhiteSpace()
+		if s.equalChar('}') {
+			s.cursor++
+			return nil
+		}
+		if !s.equalChar(',') {
+			return errors.ErrExpected("comma after object value", s.totalOffset())
+		}
+		s.cursor++
+	}
+}
+
+func (d *orderedMapDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsafe.Pointer) (int64, error) {
+	buf := ctx.Buf
+	depth++
+	if depth > maxDecodeNestingDepth {
+		return 0, errors.ErrExceededMaxDepth(buf[cursor], cursor)
+	}
+
+	cursor = skipWhiteSpace(buf, cursor)
+	switch buf[cursor] {
+	case 'n':
+		if err := validateNull(buf, cursor); err != nil {
+			return 0, err
+		}
+		d.reset(p)
+		return cursor + 4, nil
+	case '{':
+	default:
+		return 0, errors.ErrExpected("{ character for map value", cursor)
+	}
+	d.prepare(p)
+	cursor = skipWhiteSpace(buf, cursor+1)
+	if buf[cursor] == '}' {
+		return cursor + 1, nil
+	}
+	seenKeys := d.keySet.newSet(ctx.Option)
+	for {
+		entry := d.nextEntry(p)
+		keyCursor, err := d.keyDecoder.Decode(ctx, cursor, depth, entry)
+		if err != nil {
+			d.clear(entry)
+			return 0, err
+		}
+		if seenKeys != nil && d.keySet.add(seenKeys, entry) {
+			err := errors.ErrDuplicateKey(mapKeyToken(d.layout.Key, entry), skipWhiteSpace(buf, cursor))
+			d.clear(entry)
+			return 0, err
+		}
+		cursor = skipWhiteSpace(buf, keyCursor)
+		if buf[cursor] != ':' {
+			d.clear(entry)
+			return 0, errors.ErrExpected("colon after object key", cursor)
+		}
+		cursor++
+		mark := ctx.Option.errorMark()
+		valueCursor, err := d.valueDecoder.Decode(ctx, cursor, depth, d.value(entry))
+		if ctx.Option.collectedSince(mark) {
+			ctx.Option.addPointerToken(mark, mapKeyToken(d.layout.Key, entry))
+		}
+		if err != nil {
+			err = withPointerToken(err, mapKeyToken(d.layout.Key, entry))
+			d.clear(entry)
+			if !ctx.Option.collectError(err) {
+				return 0, err
+			}
+			if valueCursor, err = skipValue(buf, cursor, depth); err != nil {
+				return 0, err
+			}
+		} else {
+			d.commit(p, entry)
+		}
+		cursor = skipWhiteSpace(buf, valueCursor)
+		if buf[cursor] == '}' {
+			return cursor + 1, nil
+		}
+		if buf[cursor] != ',' {
+			return 0, errors.ErrExpected("comma after object value", cursor)
+		}
+		cursor++
+	}
+}
+
+func (d *orderedMapDecoder) DecodePath(ctx *RuntimeContext, cursor, depth int64) ([][]byte, int64, error) {
+	return nil, 0, fmt.Errorf("json: ordered map decoder does not support decode path")
+}
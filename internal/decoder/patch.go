@@ -0,0 +1,689 @@
+package decoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json/internal/errors"
+)
+
+// patchOperation is the operation of JSON Patch ( RFC 6902 ).
+type patchOperation struct {
+	op       string
+	path     string
+	from     string
+	hasPath  bool
+	hasFrom  bool
+	value    *rawRFCNode
+	hasValue bool
+}
+
+// patchTarget is the value referenced by JSON Pointer in the document.
+type patchTarget struct {
+	// parent is nil if JSON Pointer references the root value.
+	parent *rawRFCNode
+	// index is the index of the child in the parent.
+	// If the child doesn't exist, it is the length of the parent.
+	index  int
+	name   string
+	node   *rawRFCNode
+	exists bool
+}
+
+// newRawPatchNode scans the JSON value in the buffer that ends with nul byte.
+func newRawPatchNode(buf []byte) (*rawRFCNode, error) {
+	start := skipWhiteSpace(buf, 0)
+	end, err := skipValue(buf, start, 0)
+	if err != nil {
+		return nil, err
+	}
+	if cursor := skipWhiteSpace(buf, end); buf[cursor] != nul {
+		return nil, errors.ErrSyntax("invalid character '"+string(buf[cursor])+"' after top-level value", cursor+1)
+	}
+	doc := &rfcRawDocument{buf: buf, keyDecoder: newStringDecoder("", "")}
+	return &rawRFCNode{doc: doc, start: start, end: end}, nil
+}
+
+// rawContainer returns the scanned container of the node, or nil if the node is neither array nor object.
+func rawContainer(node *rawRFCNode) (*rawPathContainer, error) {
+	if node.container() == nil {
+		return nil, node.doc.err
+	}
+	return node.c, nil
+}
+
+// lastMemberIndex returns the index of the member of the object by name.
+// If the object has duplicate names, the last one is used.
+func lastMemberIndex(c *rawPathContainer, name string) int {
+	for i := c.len() - 1; i >= 0; i-- {
+		if c.keys[i] == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func rawNodeKind(node *rawRFCNode) byte {
+	return node.doc.buf[node.start]
+}
+
+func rawNodeBytes(node *rawRFCNode) []byte {
+	return node.doc.buf[node.start:node.end]
+}
+
+// locatePatchTarget finds the value referenced by JSON Pointer.
+// The last reference token may reference the value that doesn't exist.
+func locatePatchTarget(root *rawRFCNode, pointer string) (*patchTarget, error) {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, errors.ErrInvalidPath("%q is not a valid JSON Pointer", pointer)
+	}
+	if len(tokens) == 0 {
+		return &patchTarget{node: root, exists: true}, nil
+	}
+	node := root
+	for i, token := range tokens {
+		c, err := rawContainer(node)
+		if err != nil {
+			return nil, errors.ErrInvalidPath(err.Error())
+		}
+		if c == nil {
+			return nil, errors.ErrInvalidPath("parent of %q is neither array nor object", pointer)
+		}
+		idx := c.len()
+		if c.array {
+			if token != "-" {
+				idx = pointerIndex(token)
+				if idx < 0 {
+					return nil, errors.ErrInvalidPath("%q is not a valid array index", token)
+				}
+			}
+		} else if found := lastMemberIndex(c, token); found >= 0 {
+			idx = found
+		}
+		exists := idx < c.len()
+		if i == len(tokens)-1 {
+			target := &patchTarget{parent: node, index: idx, name: token, exists: exists}
+			if exists {
+				target.node = node.child(idx).(*rawRFCNode)
+			}
+			return target, nil
+		}
+		if !exists {
+			return nil, errors.ErrInvalidPath("%q does not exist", pointer)
+		}
+		node = node.child(idx).(*rawRFCNode)
+	}
+	return nil, nil
+}
+
+// insertMembersEdit returns the edit that inserts the members text after the last member that isn't deleted.
+func insertMembersEdit(node *rawRFCNode, deleted map[int]struct{}, text []byte) pathEdit {
+	c := node.c
+	for i := c.len() - 1; i >= 0; i-- {
+		if _, exists := deleted[i]; !exists {
+			pos := c.ends[i]
+			return pathEdit{start: pos, end: pos, text: append([]byte{','}, text...)}
+		}
+	}
+	if c.len() == 0 {
+		// insert before the right brace.
+		return pathEdit{start: node.end - 1, end: node.end - 1, text: text}
+	}
+	// all members are deleted, so insert at the position of the first member.
+	pos := c.keyStarts[0]
+	return pathEdit{start: pos, end: pos, text: text}
+}
+
+func encodeMember(name string, value []byte) []byte {
+	key, _ := json.Marshal(name)
+	return append(append(key, ':'), value...)
+}
+
+// ApplyPatch applies JSON Patch ( RFC 6902 ) to the document.
+// Both of the document and the patch must end with nul byte. The result doesn't end with nul byte.
+func ApplyPatch(doc, patch []byte) ([]byte, error) {
+	ops, err := parsePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := newRawPatchNode(doc); err != nil {
+		return nil, err
+	}
+	ret := doc[:len(doc)-1]
+	for i, op := range ops {
+		ret, err = op.apply(doc)
+		if err != nil {
+			if pathErr, ok := err.(*errors.PathError); ok {
+				return nil, &errors.PatchError{Index: i, Op: op.op, Path: op.path, Err: pathErr}
+			}
+			return nil, err
+		}
+		doc = append(ret, nul)
+	}
+	return ret, nil
+}
+
+// parsePatch parses the patch document that is the array of operations.
+func parsePatch(patch []byte) ([]*patchOperation, error) {
+	root, err := newRawPatchNode(patch)
+	if err != nil {
+		return nil, err
+	}
+	c, err := rawContainer(root)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil || !c.array {
+		return nil, &errors.PatchError{Err: errors.ErrInvalidPath("patch document must be an array")}
+	}
+	ops := make([]*patchOperation, 0, c.len())
+	for i := 0; i < c.len(); i++ {
+		op, err := parsePatchOperation(root.child(i).(*rawRFCNode))
+		if err != nil {
+			if pathErr, ok := err.(*errors.PathError); ok {
+				return nil, &errors.PatchError{Index: i, Op: op.op, Path: op.path, Err: pathErr}
+			}
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+func parsePatchOperation(node *rawRFCNode) (*patchOperation, error) {
+	op := &patchOperation{}
+	c, err := rawContainer(node)
+	if err != nil {
+		return op, err
+	}
+	if c == nil || c.array {
+		return op, errors.ErrInvalidPath("operation must be an object")
+	}
+	var hasOp bool
+	for i := 0; i < c.len(); i++ {
+		member := node.child(i).(*rawRFCNode)
+		switch c.keys[i] {
+		case "op", "path", "from":
+			if rawNodeKind(member) != '"' {
+				return op, errors.ErrInvalidPath("%q member must be a string", c.keys[i])
+			}
+			s, _, err := decodeRawPathString(node.doc.keyDecoder, node.doc.buf, member.start)
+			if err != nil {
+				return op, err
+			}
+			switch c.keys[i] {
+			case "op":
+				op.op, hasOp = string(s), true
+			case "path":
+				op.path, op.hasPath = string(s), true
+			case "from":
+				op.from, op.hasFrom = string(s), true
+			}
+		case "value":
+			op.value, op.hasValue = member, true
+		}
+	}
+	if !hasOp {
+		return op, errors.ErrInvalidPath(`"op" member is missing`)
+	}
+	if !op.hasPath {
+		return op, errors.ErrInvalidPath(`"path" member is missing`)
+	}
+	switch op.op {
+	case "add", "replace", "test":
+		if !op.hasValue {
+			return op, errors.ErrInvalidPath(`"value" member is missing`)
+		}
+	case "move", "copy":
+		if !op.hasFrom {
+			return op, errors.ErrInvalidPath(`"from" member is missing`)
+		}
+	case "remove":
+	default:
+		return op, errors.ErrInvalidPath("unknown operation")
+	}
+	return op, nil
+}
+
+// apply applies the operation to the document that ends with nul byte.
+func (op *patchOperation) apply(doc []byte) ([]byte, error) {
+	root, err := newRawPatchNode(doc)
+	if err != nil {
+		return nil, err
+	}
+	switch op.op {
+	case "add":
+		return patchAdd(root, op.path, rawNodeBytes(op.value))
+	case "remove":
+		return patchRemove(root, op.path)
+	case "replace":
+		target, err := locatePatchTarget(root, op.path)
+		if err != nil {
+			return nil, err
+		}
+		if !target.exists {
+			return nil, errors.ErrInvalidPath("%q does not exist", op.path)
+		}
+		edit := pathEdit{start: target.node.start, end: target.node.end, text: rawNodeBytes(op.value)}
+		return applyPathEdits(doc, []pathEdit{edit}), nil
+	case "move":
+		if op.from == op.path {
+			return doc[:len(doc)-1], nil
+		}
+		if strings.HasPrefix(op.path, op.from+"/") {
+			return nil, errors.ErrInvalidPath("%q cannot be moved into its child", op.from)
+		}
+		value, err := patchGet(root, op.from)
+		if err != nil {
+			return nil, err
+		}
+		removed, err := patchRemove(root, op.from)
+		if err != nil {
+			return nil, err
+		}
+		removedRoot, err := newRawPatchNode(append(removed, nul))
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(removedRoot, op.path, value)
+	case "copy":
+		value, err := patchGet(root, op.from)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(root, op.path, value)
+	case "test":
+		target, err := locatePatchTarget(root, op.path)
+		if err != nil {
+			return nil, err
+		}
+		if !target.exists {
+			return nil, errors.ErrInvalidPath("%q does not exist", op.path)
+		}
+		equal, err := rawValueEqual(target.node, op.value)
+		if err != nil {
+			return nil, err
+		}
+		if !equal {
+			return nil, errors.ErrInvalidPath("value of %q is not equal to the tested value", op.path)
+		}
+		return doc[:len(doc)-1], nil
+	}
+	return nil, errors.ErrInvalidPath("unknown operation")
+}
+
+func patchGet(root *rawRFCNode, pointer string) ([]byte, error) {
+	target, err := locatePatchTarget(root, pointer)
+	if err != nil {
+		return nil, err
+	}
+	if !target.exists {
+		return nil, errors.ErrInvalidPath("%q does not exist", pointer)
+	}
+	return rawNodeBytes(target.node), nil
+}
+
+func patchAdd(root *rawRFCNode, pointer string, value []byte) ([]byte, error) {
+	target, err := locatePatchTarget(root, pointer)
+	if err != nil {
+		return nil, err
+	}
+	buf := root.doc.buf
+	if target.parent == nil {
+		return applyPathEdits(buf, []pathEdit{{start: root.start, end: root.end, text: value}}), nil
+	}
+	parent := target.parent
+	c := parent.c
+	var edit pathEdit
+	switch {
+	case !c.array && target.exists:
+		edit = pathEdit{start: target.node.start, end: target.node.end, text: value}
+	case !c.array:
+		edit = insertMembersEdit(parent, nil, encodeMember(target.name, value))
+	case target.index > c.len():
+		return nil, errors.ErrInvalidPath("index %d is out of range of the array of length %d", target.index, c.len())
+	case c.len() == 0:
+		// insert before the right bracket.
+		edit = pathEdit{start: parent.end - 1, end: parent.end - 1, text: value}
+	case target.index == c.len():
+		pos := c.ends[c.len()-1]
+		edit = pathEdit{start: pos, end: pos, text: append([]byte{','}, value...)}
+	default:
+		pos := c.starts[target.index]
+		edit = pathEdit{start: pos, end: pos, text: append(append([]byte(nil), value...), ',')}
+	}
+	return applyPathEdits(buf, []pathEdit{edit}), nil
+}
+
+func patchRemove(root *rawRFCNode, pointer string) ([]byte, error) {
+	target, err := locatePatchTarget(root, pointer)
+	if err != nil {
+		return nil, err
+	}
+	if target.parent == nil {
+		return nil, errors.ErrInvalidPath("root value cannot be removed")
+	}
+	if !target.exists {
+		return nil, errors.ErrInvalidPath("%q does not exist", pointer)
+	}
+	edits := deleteChildrenEdits(target.parent.c, map[int]struct{}{target.index: {}})
+	return applyPathEdits(root.doc.buf, edits), nil
+}
+
+// rawValueEqual reports whether the JSON values are equal as defined by RFC 6902 section 4.6.
+// The numbers are compared by their values, so 1 and 1.0 are equal.
+func rawValueEqual(a, b *rawRFCNode) (bool, error) {
+	ak, bk := rawNodeKind(a), rawNodeKind(b)
+	switch ak {
+	case '{', '[':
+		if ak != bk {
+			return false, nil
+		}
+		ac, err := rawContainer(a)
+		if err != nil {
+			return false, err
+		}
+		bc, err := rawContainer(b)
+		if err != nil {
+			return false, err
+		}
+		if ak == '[' {
+			if ac.len() != bc.len() {
+				return false, nil
+			}
+			for i := 0; i < ac.len(); i++ {
+				equal, err := rawValueEqual(a.child(i).(*rawRFCNode), b.child(i).(*rawRFCNode))
+				if err != nil || !equal {
+					return false, err
+				}
+			}
+			return true, nil
+		}
+		var names int
+		for i := 0; i < ac.len(); i++ {
+			if lastMemberIndex(ac, ac.keys[i]) != i {
+				continue
+			}
+			names++
+			j := lastMemberIndex(bc, ac.keys[i])
+			if j < 0 {
+				return false, nil
+			}
+			equal, err := rawValueEqual(a.child(i).(*rawRFCNode), b.child(j).(*rawRFCNode))
+			if err != nil || !equal {
+				return false, err
+			}
+		}
+		for i := 0; i < bc.len(); i++ {
+			if lastMemberIndex(bc, bc.keys[i]) == i {
+				names--
+			}
+		}
+		return names == 0, nil
+	case '"':
+		if bk != '"' {
+			return false, nil
+		}
+		as, _, err := decodeRawPathString(a.doc.keyDecoder, a.doc.buf, a.start)
+		if err != nil {
+			return false, err
+		}
+		as = append([]byte(nil), as...)
+		bs, _, err := decodeRawPathString(b.doc.keyDecoder, b.doc.buf, b.start)
+		if err != nil {
+			return false, err
+		}
+		return bytes.Equal(as, bs), nil
+	case 't', 'f', 'n':
+		return bytes.Equal(rawNodeBytes(a), rawNodeBytes(b)), nil
+	}
+	if bk != '-' && (bk < '0' || bk > '9') {
+		return false, nil
+	}
+	if bytes.Equal(rawNodeBytes(a), rawNodeBytes(b)) {
+		return true, nil
+	}
+	// compare with enough precision not to lose the digits of large integers.
+	af, _, err := big.ParseFloat(string(rawNodeBytes(a)), 10, 256, big.ToNearestEven)
+	if err != nil {
+		return false, nil
+	}
+	bf, _, err := big.ParseFloat(string(rawNodeBytes(b)), 10, 256, big.ToNearestEven)
+	if err != nil {
+		return false, nil
+	}
+	return af.Cmp(bf) == 0, nil
+}
+
+// CreatePatch creates JSON Patch ( RFC 6902 ) that transforms the document a into the document b.
+// Both of the documents must end with nul byte. The values in the patch are copied from b byte for byte.
+func CreatePatch(a, b []byte) ([]byte, error) {
+	aroot, err := newRawPatchNode(a)
+	if err != nil {
+		return nil, err
+	}
+	broot, err := newRawPatchNode(b)
+	if err != nil {
+		return nil, err
+	}
+	w := &patchWriter{buf: []byte{'['}}
+	if err := w.diff(aroot, broot, ""); err != nil {
+		return nil, err
+	}
+	return append(w.buf, ']'), nil
+}
+
+// patchWriter writes the operations of JSON Patch.
+type patchWriter struct {
+	buf []byte
+	n   int
+}
+
+func (w *patchWriter) write(op, pointer string, value []byte) {
+	if w.n > 0 {
+		w.buf = append(w.buf, ',')
+	}
+	w.n++
+	w.buf = append(w.buf, `{"op":"`...)
+	w.buf = append(w.buf, op...)
+	w.buf = append(w.buf, `","path":`...)
+	path, _ := json.Marshal(pointer)
+	w.buf = append(w.buf, path...)
+	if value != nil {
+		w.buf = append(w.buf, `,"value":`...)
+		w.buf = append(w.buf, value...)
+	}
+	w.buf = append(w.buf, '}')
+}
+
+func (w *patchWriter) diff(a, b *rawRFCNode, pointer string) error {
+	ak, bk := rawNodeKind(a), rawNodeKind(b)
+	if (ak == '{' || ak == '[') && ak == bk {
+		ac, err := rawContainer(a)
+		if err != nil {
+			return err
+		}
+		bc, err := rawContainer(b)
+		if err != nil {
+			return err
+		}
+		if ak == '[' {
+			return w.diffArray(a, b, ac, bc, pointer)
+		}
+		return w.diffObject(a, b, ac, bc, pointer)
+	}
+	equal, err := rawValueEqual(a, b)
+	if err != nil {
+		return err
+	}
+	if !equal {
+		w.write("replace", pointer, rawNodeBytes(b))
+	}
+	return nil
+}
+
+func (w *patchWriter) diffObject(a, b *rawRFCNode, ac, bc *rawPathContainer, pointer string) error {
+	for i := 0; i < ac.len(); i++ {
+		name := ac.keys[i]
+		if lastMemberIndex(ac, name) != i {
+			continue
+		}
+		child := pointer + "/" + EscapePointerToken(name)
+		j := lastMemberIndex(bc, name)
+		if j < 0 {
+			w.write("remove", child, nil)
+			continue
+		}
+		if err := w.diff(a.child(i).(*rawRFCNode), b.child(j).(*rawRFCNode), child); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < bc.len(); i++ {
+		name := bc.keys[i]
+		if lastMemberIndex(bc, name) != i || lastMemberIndex(ac, name) >= 0 {
+			continue
+		}
+		w.write("add", pointer+"/"+EscapePointerToken(name), rawNodeBytes(b.child(i).(*rawRFCNode)))
+	}
+	return nil
+}
+
+func (w *patchWriter) diffArray(a, b *rawRFCNode, ac, bc *rawPathContainer, pointer string) error {
+	n := ac.len()
+	if bc.len() < n {
+		n = bc.len()
+	}
+	for i := 0; i < n; i++ {
+		if err := w.diff(a.child(i).(*rawRFCNode), b.child(i).(*rawRFCNode), pointer+"/"+strconv.Itoa(i)); err != nil {
+			return err
+		}
+	}
+	// remove the rest of elements from the end, so that the indexes of the other elements don't change.
+	for i := ac.len() - 1; i >= n; i-- {
+		w.write("remove", pointer+"/"+strconv.Itoa(i), nil)
+	}
+	for i := n; i < bc.len(); i++ {
+		w.write("add", pointer+"/"+strconv.Itoa(i), rawNodeBytes(b.child(i).(*rawRFCNode)))
+	}
+	return nil
+}
+
+// MergePatch applies JSON Merge Patch ( RFC 7386 ) to the document.
+// Both of the document and the patch must end with nul byte. The result doesn't end with nul byte.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	docRoot, err := newRawPatchNode(doc)
+	if err != nil {
+		return nil, err
+	}
+	patchRoot, err := newRawPatchNode(patch)
+	if err != nil {
+		return nil, err
+	}
+	if rawNodeKind(patchRoot) != '{' {
+		return append([]byte(nil), rawNodeBytes(patchRoot)...), nil
+	}
+	if rawNodeKind(docRoot) != '{' {
+		return mergePatchValue(patchRoot)
+	}
+	edits, err := mergePatchEdits(docRoot, patchRoot)
+	if err != nil {
+		return nil, err
+	}
+	return applyPathEdits(doc, edits), nil
+}
+
+// mergePatchEdits returns the edits that merge the patch object into the document object.
+func mergePatchEdits(doc, patch *rawRFCNode) ([]pathEdit, error) {
+	dc, err := rawContainer(doc)
+	if err != nil {
+		return nil, err
+	}
+	pc, err := rawContainer(patch)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		edits   []pathEdit
+		added   []byte
+		deleted = map[int]struct{}{}
+	)
+	for i := 0; i < pc.len(); i++ {
+		name := pc.keys[i]
+		if lastMemberIndex(pc, name) != i {
+			continue
+		}
+		value := patch.child(i).(*rawRFCNode)
+		idx := lastMemberIndex(dc, name)
+		if rawNodeKind(value) == 'n' {
+			if idx >= 0 {
+				deleted[idx] = struct{}{}
+			}
+			continue
+		}
+		if idx >= 0 {
+			target := doc.child(idx).(*rawRFCNode)
+			if rawNodeKind(target) == '{' && rawNodeKind(value) == '{' {
+				children, err := mergePatchEdits(target, value)
+				if err != nil {
+					return nil, err
+				}
+				edits = append(edits, children...)
+				continue
+			}
+			text, err := mergePatchValue(value)
+			if err != nil {
+				return nil, err
+			}
+			edits = append(edits, pathEdit{start: target.start, end: target.end, text: text})
+			continue
+		}
+		text, err := mergePatchValue(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(added) > 0 {
+			added = append(added, ',')
+		}
+		added = append(added, encodeMember(name, text)...)
+	}
+	edits = append(edits, deleteChildrenEdits(dc, deleted)...)
+	if len(added) > 0 {
+		edits = append(edits, insertMembersEdit(doc, deleted, added))
+	}
+	return edits, nil
+}
+
+// mergePatchValue returns the value of the patch merged into the value that isn't an object.
+// The members with null value are removed from the object.
+func mergePatchValue(patch *rawRFCNode) ([]byte, error) {
+	if rawNodeKind(patch) != '{' {
+		return rawNodeBytes(patch), nil
+	}
+	c, err := rawContainer(patch)
+	if err != nil {
+		return nil, err
+	}
+	var edits []pathEdit
+	deleted := map[int]struct{}{}
+	for i := 0; i < c.len(); i++ {
+		value := patch.child(i).(*rawRFCNode)
+		if lastMemberIndex(c, c.keys[i]) != i || rawNodeKind(value) == 'n' {
+			deleted[i] = struct{}{}
+			continue
+		}
+		if rawNodeKind(value) != '{' {
+			continue
+		}
+		text, err := mergePatchValue(value)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, pathEdit{start: value.start, end: value.end, text: text})
+	}
+	edits = append(edits, deleteChildrenEdits(c, deleted)...)
+	return applyPathEditsRange(patch.doc.buf, patch.start, patch.end, edits), nil
+}
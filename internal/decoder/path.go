@@ -3,7 +3,7 @@ package decoder
 import (
 	"fmt"
 	"reflect"
-	"strconv"
+	"strings"
 
 	"github.com/goccy/go-json/internal/errors"
 	"github.com/goccy/go-json/internal/runtime"
@@ -16,6 +16,11 @@ func (s PathString) Build() (*Path, error) {
 	return builder.Build([]rune(s))
 }
 
+// PathBuildOption is the option to build JSON Path.
+type PathBuildOption struct {
+	RFC9535 bool
+}
+
 type PathBuilder struct {
 	root                    PathNode
 	node                    PathNode
@@ -223,46 +228,60 @@ func (b *PathBuilder) buildIndex(buf []rune) (int, error) {
 	switch buf[0] {
 	case '.', '[', ']', '$':
 		return 0, errors.ErrInvalidPath("found invalid path character %c after left bracket", buf[0])
-	case '\'':
-		if len(buf) == 1 {
-			return 0, errors.ErrInvalidPath("JSON Path ends with single quote character")
-		}
-		offset, err := b.buildQuoteSelector(buf[1:], SingleQuotePathSelector)
+	}
+	cursor, err := findPathRightBracket(buf)
+	if err != nil {
+		return 0, err
+	}
+	parts, err := splitPathSelectors(buf[:cursor])
+	if err != nil {
+		return 0, err
+	}
+	selectors := make([]pathChildSelector, 0, len(parts))
+	for _, part := range parts {
+		sel, err := parsePathSelector(part)
 		if err != nil {
 			return 0, err
 		}
-		return 1 + offset, nil
-	case '*':
-		if len(buf) == 1 {
-			return 0, errors.ErrInvalidPath("JSON Path ends with star character")
-		}
-		if buf[1] != ']' {
-			return 0, errors.ErrInvalidPath("expect right bracket character for index all path but found %c character", buf[1])
-		}
-		b.addIndexAllNode()
-		offset := len("*]")
-		if len(buf) > 2 {
-			buildOffset, err := b.buildNext(buf[2:])
-			if err != nil {
-				return 0, err
+		if _, ok := sel.(*pathNameSelector); ok {
+			switch strings.TrimSpace(string(part))[0] {
+			case '\'':
+				b.singleQuotePathSelector = true
+			case '"':
+				b.doubleQuotePathSelector = true
 			}
-			return offset + buildOffset, nil
 		}
-		return offset, nil
-	}
-
-	for cursor := 0; cursor < len(buf); cursor++ {
-		switch buf[cursor] {
-		case ']':
-			index, err := strconv.ParseInt(string(buf[:cursor]), 10, 64)
-			if err != nil {
-				return 0, errors.ErrInvalidPath("%q is unexpected index path", buf[:cursor])
+		selectors = append(selectors, sel)
+	}
+	b.addIndexSelectors(selectors, string(buf[:cursor]))
+	return b.buildNextCharIfExists(buf, cursor+1)
+}
+
+// addIndexSelectors adds the node for the bracketed selection.
+// The simple selection uses the existing node to keep the fast path of Field and Index.
+func (b *PathBuilder) addIndexSelectors(selectors []pathChildSelector, raw string) {
+	if len(selectors) == 1 {
+		switch sel := selectors[0].(type) {
+		case *pathNameSelector:
+			b.addSelectorNode(sel.name)
+			return
+		case *pathIndexSelector:
+			if sel.index >= 0 {
+				b.addIndexNode(sel.index)
+				return
 			}
-			b.addIndexNode(int(index))
-			return b.buildNextCharIfExists(buf, cursor+1)
+		case *pathWildcardSelector:
+			b.addIndexAllNode()
+			return
 		}
 	}
-	return 0, errors.ErrInvalidPath("couldn't find right bracket character in index path context")
+	node := newPathUnionNode(selectors, raw)
+	if b.root == nil {
+		b.root = node
+		b.node = node
+	} else {
+		b.node = b.node.chain(node)
+	}
 }
 
 func (b *PathBuilder) addIndexAllNode() {
@@ -317,6 +336,9 @@ type Path struct {
 	RootSelectorOnly        bool
 	SingleQuotePathSelector bool
 	DoubleQuotePathSelector bool
+	RFC9535                 bool
+	query                   *rfcQuery // query built by BuildRFC9535
+	raw                     string
 }
 
 func (p *Path) Field(sel string) (PathNode, bool, error) {
@@ -327,6 +349,9 @@ func (p *Path) Field(sel string) (PathNode, bool, error) {
 }
 
 func (p *Path) Get(src, dst reflect.Value) error {
+	if p.query != nil {
+		return p.getRFC9535(src, dst)
+	}
 	if p.node == nil {
 		return nil
 	}
@@ -334,6 +359,9 @@ func (p *Path) Get(src, dst reflect.Value) error {
 }
 
 func (p *Path) String() string {
+	if p.query != nil {
+		return p.raw
+	}
 	if p.node == nil {
 		return "$"
 	}
@@ -380,7 +408,7 @@ func newPathSelectorNode(selector string) *PathSelectorNode {
 }
 
 func (n *PathSelectorNode) Index(idx int) (PathNode, bool, error) {
-	return nil, false, &errors.PathError{}
+	return nil, false, errors.ErrInvalidPath(".%s cannot select the array element %d", n.selector, idx)
 }
 
 func (n *PathSelectorNode) Field(fieldName string) (PathNode, bool, error) {
@@ -412,7 +440,10 @@ func (n *PathSelectorNode) Get(src, dst reflect.Value) error {
 		}
 	case reflect.Struct:
 		typ := src.Type()
-		for i := 0; i < typ.Len(); i++ {
+		for i := 0; i < typ.NumField(); i++ {
+			if runtime.IsIgnoredStructField(typ.Field(i)) {
+				continue
+			}
 			tag := runtime.StructTagFromField(typ.Field(i))
 			child, found, err := n.Field(tag.Key)
 			if err != nil {
@@ -463,7 +494,7 @@ func (n *PathIndexNode) Index(idx int) (PathNode, bool, error) {
 }
 
 func (n *PathIndexNode) Field(fieldName string) (PathNode, bool, error) {
-	return nil, false, &errors.PathError{}
+	return nil, false, errors.ErrInvalidPath("[%d] cannot select the object member %q", n.selector, fieldName)
 }
 
 func (n *PathIndexNode) Get(src, dst reflect.Value) error {
@@ -506,7 +537,7 @@ func (n *PathIndexAllNode) Index(idx int) (PathNode, bool, error) {
 }
 
 func (n *PathIndexAllNode) Field(fieldName string) (PathNode, bool, error) {
-	return nil, false, &errors.PathError{}
+	return nil, false, errors.ErrInvalidPath("[*] cannot select the object member %q", fieldName)
 }
 
 func (n *PathIndexAllNode) Get(src, dst reflect.Value) error {
@@ -620,7 +651,10 @@ func (n *PathRecursiveNode) Get(src, dst reflect.Value) error {
 		return nil
 	case reflect.Struct:
 		typ := src.Type()
-		for i := 0; i < typ.Len(); i++ {
+		for i := 0; i < typ.NumField(); i++ {
+			if runtime.IsIgnoredStructField(typ.Field(i)) {
+				continue
+			}
 			tag := runtime.StructTagFromField(typ.Field(i))
 			child, found, err := n.Field(tag.Key)
 			if err != nil {
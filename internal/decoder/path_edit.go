@@ -0,0 +1,159 @@
+package decoder
+
+import (
+	"sort"
+
+	"github.com/goccy/go-json/internal/errors"
+)
+
+// pathEdit replaces the bytes in [start, end) with text.
+type pathEdit struct {
+	start, end int64
+	text       []byte
+}
+
+// applyPathEdits applies the edits to the buffer that ends with nul byte.
+// If an edit is inside of the other edit, it is ignored because the outer edit replaces it.
+func applyPathEdits(buf []byte, edits []pathEdit) []byte {
+	return applyPathEditsRange(buf, 0, int64(len(buf)-1), edits) // exclude nul byte
+}
+
+// applyPathEditsRange applies the edits to buf[start:end] and returns the edited copy of the range.
+// The insertion ( the edit of the empty range ) is applied before the other edit at the same position.
+func applyPathEditsRange(buf []byte, start, end int64, edits []pathEdit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start < edits[j].start
+		}
+		if (edits[i].start == edits[i].end) != (edits[j].start == edits[j].end) {
+			return edits[i].start == edits[i].end
+		}
+		return edits[i].end > edits[j].end
+	})
+	ret := make([]byte, 0, end-start)
+	cursor := start
+	for _, edit := range edits {
+		if edit.start < cursor {
+			continue
+		}
+		ret = append(ret, buf[cursor:edit.start]...)
+		ret = append(ret, edit.text...)
+		cursor = edit.end
+	}
+	return append(ret, buf[cursor:end]...)
+}
+
+// Set replaces the values selected by the path with the encoded value in the buffer that ends with nul byte.
+// If the path is singular and the last selector is a member name that doesn't exist,
+// the member is added to the parent object.
+// It returns the cursor after the root value to validate the rest of the buffer.
+func (p *Path) Set(buf []byte, value []byte) ([]byte, int64, error) {
+	segments, ok := p.segments()
+	if !ok {
+		return nil, 0, errors.ErrInvalidPath("%s cannot be evaluated on the buffer", p.String())
+	}
+	matches, end, err := evalRawSegments(buf, segments)
+	if err != nil {
+		return nil, 0, err
+	}
+	edits := make([]pathEdit, 0, len(matches))
+	for _, m := range matches {
+		node := m.node.(*rawRFCNode)
+		edits = append(edits, pathEdit{start: node.start, end: node.end, text: value})
+	}
+	if len(matches) == 0 && len(segments) > 0 && (&rfcQuery{segments: segments}).singular() {
+		edit, err := addMemberEdit(buf, segments, value)
+		if err != nil {
+			return nil, 0, err
+		}
+		edits = append(edits, edit)
+	}
+	return applyPathEdits(buf, edits), end, nil
+}
+
+// addMemberEdit returns the edit that adds the member selected by the last segment to the parent object.
+func addMemberEdit(buf []byte, segments []*rfcSegment, value []byte) (pathEdit, error) {
+	last := segments[len(segments)-1]
+	sel, ok := last.selectors[0].(*pathNameSelector)
+	if !ok {
+		return pathEdit{}, errors.ErrInvalidPath("array element to set is not found")
+	}
+	parents, _, err := evalRawSegments(buf, segments[:len(segments)-1])
+	if err != nil {
+		return pathEdit{}, err
+	}
+	if len(parents) == 0 {
+		return pathEdit{}, errors.ErrInvalidPath("parent of %q is not found", sel.name)
+	}
+	parent := parents[0].node.(*rawRFCNode)
+	c := parent.container()
+	if c == nil || c.isArray() {
+		return pathEdit{}, errors.ErrInvalidPath("parent of %q is not an object", sel.name)
+	}
+	return insertMembersEdit(parent, nil, encodeMember(sel.name, value)), nil
+}
+
+// Delete removes the members or elements selected by the path from the buffer that ends with nul byte.
+// It returns the cursor after the root value to validate the rest of the buffer.
+func (p *Path) Delete(buf []byte) ([]byte, int64, error) {
+	if p.RootSelectorOnly {
+		return nil, 0, errors.ErrInvalidPath("root value cannot be deleted")
+	}
+	matches, end, err := p.evalRaw(buf)
+	if err != nil {
+		return nil, 0, err
+	}
+	// group the deleted children by the container.
+	deleted := map[*rawRFCNode]map[int]struct{}{}
+	var containers []*rawRFCNode
+	for _, m := range matches {
+		container := m.loc.container.(*rawRFCNode)
+		if _, exists := deleted[container]; !exists {
+			deleted[container] = map[int]struct{}{}
+			containers = append(containers, container)
+		}
+		deleted[container][m.loc.index] = struct{}{}
+	}
+	var edits []pathEdit
+	for _, container := range containers {
+		edits = append(edits, deleteChildrenEdits(container.c, deleted[container])...)
+	}
+	return applyPathEdits(buf, edits), end, nil
+}
+
+// deleteChildrenEdits returns the edits that remove the children with the separators.
+// The consecutive children are removed together, so that the separators remain valid.
+func deleteChildrenEdits(c *rawPathContainer, deleted map[int]struct{}) []pathEdit {
+	childStart := func(i int) int64 {
+		if c.array {
+			return c.starts[i]
+		}
+		return c.keyStarts[i]
+	}
+	var edits []pathEdit
+	n := c.len()
+	for i := 0; i < n; i++ {
+		if _, exists := deleted[i]; !exists {
+			continue
+		}
+		j := i
+		for j+1 < n {
+			if _, exists := deleted[j+1]; !exists {
+				break
+			}
+			j++
+		}
+		switch {
+		case j < n-1:
+			// remove up to the next child including the comma.
+			edits = append(edits, pathEdit{start: childStart(i), end: childStart(j + 1)})
+		case i > 0:
+			// remove from the end of the previous child including the comma.
+			edits = append(edits, pathEdit{start: c.ends[i-1], end: c.ends[j]})
+		default:
+			edits = append(edits, pathEdit{start: childStart(i), end: c.ends[j]})
+		}
+		i = j
+	}
+	return edits
+}
@@ -0,0 +1,501 @@
+package decoder
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/goccy/go-json/internal/errors"
+)
+
+var numberType = reflect.TypeOf(json.Number(""))
+
+// pathFilterExpr is the logical expression of filter selector. e.g.) [?(@.price < 10 && @.tag == 'x')]
+type pathFilterExpr interface {
+	eval(cur reflect.Value) bool
+}
+
+// pathFilterOperand is the operand of comparison in filter expression.
+type pathFilterOperand interface {
+	// value returns the normalized value of the operand.
+	// found is false if the operand is a query that doesn't select any value.
+	value(cur reflect.Value) (v interface{}, found bool)
+}
+
+type pathFilterOr struct {
+	left  pathFilterExpr
+	right pathFilterExpr
+}
+
+func (e *pathFilterOr) eval(cur reflect.Value) bool {
+	return e.left.eval(cur) || e.right.eval(cur)
+}
+
+type pathFilterAnd struct {
+	left  pathFilterExpr
+	right pathFilterExpr
+}
+
+func (e *pathFilterAnd) eval(cur reflect.Value) bool {
+	return e.left.eval(cur) && e.right.eval(cur)
+}
+
+type pathFilterNot struct {
+	expr pathFilterExpr
+}
+
+func (e *pathFilterNot) eval(cur reflect.Value) bool {
+	return !e.expr.eval(cur)
+}
+
+// pathFilterExists tests whether the query selects a value. e.g.) [?(@.isbn)]
+type pathFilterExists struct {
+	query *pathFilterQuery
+}
+
+func (e *pathFilterExists) eval(cur reflect.Value) bool {
+	_, found := e.query.value(cur)
+	return found
+}
+
+type pathFilterCompare struct {
+	op    string
+	left  pathFilterOperand
+	right pathFilterOperand
+}
+
+func (e *pathFilterCompare) eval(cur reflect.Value) bool {
+	l, lfound := e.left.value(cur)
+	r, rfound := e.right.value(cur)
+	switch e.op {
+	case "==":
+		return pathFilterEqual(l, lfound, r, rfound)
+	case "!=":
+		return !pathFilterEqual(l, lfound, r, rfound)
+	case "<":
+		return pathFilterLess(l, lfound, r, rfound)
+	case "<=":
+		return pathFilterLess(l, lfound, r, rfound) || pathFilterEqual(l, lfound, r, rfound)
+	case ">":
+		return pathFilterLess(r, rfound, l, lfound)
+	case ">=":
+		return pathFilterLess(r, rfound, l, lfound) || pathFilterEqual(l, lfound, r, rfound)
+	}
+	return false
+}
+
+func pathFilterEqual(l interface{}, lfound bool, r interface{}, rfound bool) bool {
+	if !lfound || !rfound {
+		return lfound == rfound
+	}
+	switch lv := l.(type) {
+	case float64:
+		rv, ok := r.(float64)
+		return ok && lv == rv
+	case string:
+		rv, ok := r.(string)
+		return ok && lv == rv
+	case bool:
+		rv, ok := r.(bool)
+		return ok && lv == rv
+	case nil:
+		return r == nil
+	}
+	return reflect.DeepEqual(l, r)
+}
+
+func pathFilterLess(l interface{}, lfound bool, r interface{}, rfound bool) bool {
+	if !lfound || !rfound {
+		return false
+	}
+	switch lv := l.(type) {
+	case float64:
+		rv, ok := r.(float64)
+		return ok && lv < rv
+	case string:
+		rv, ok := r.(string)
+		return ok && lv < rv
+	}
+	return false
+}
+
+type pathFilterLiteral struct {
+	v interface{}
+}
+
+func (l *pathFilterLiteral) value(reflect.Value) (interface{}, bool) {
+	return l.v, true
+}
+
+// pathFilterQuery is the relative query from the current node (@) in filter expression.
+type pathFilterQuery struct {
+	segments []pathFilterSegment
+}
+
+type pathFilterSegment struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+func (q *pathFilterQuery) value(cur reflect.Value) (interface{}, bool) {
+	v := cur
+	for _, seg := range q.segments {
+		child, found := pathFilterChild(v, seg)
+		if !found {
+			return nil, false
+		}
+		v = child
+	}
+	return normalizePathFilterValue(v), true
+}
+
+func pathFilterChild(v reflect.Value, seg pathFilterSegment) (reflect.Value, bool) {
+	v = derefPathValue(v)
+	if !v.IsValid() {
+		return reflect.Value{}, false
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if seg.isIndex {
+			return reflect.Value{}, false
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if mapKeyValueToken(iter.Key()) == seg.name {
+				return iter.Value(), true
+			}
+		}
+	case reflect.Struct:
+		if seg.isIndex {
+			return reflect.Value{}, false
+		}
+		if idx, ok := structFieldIndexByKey(v.Type(), seg.name); ok {
+			return v.Field(idx), true
+		}
+	case reflect.Slice, reflect.Array:
+		if !seg.isIndex {
+			return reflect.Value{}, false
+		}
+		idx := seg.index
+		if idx < 0 {
+			idx += v.Len()
+		}
+		if idx >= 0 && idx < v.Len() {
+			return v.Index(idx), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// derefPathValue returns the value referenced by pointer or interface.
+// It returns the zero Value for nil.
+func derefPathValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// normalizePathFilterValue converts v to the value compared in filter expression.
+// numbers are converted to float64, and null is nil.
+func normalizePathFilterValue(v reflect.Value) interface{} {
+	v = derefPathValue(v)
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		if v.Type() == numberType {
+			if f, err := strconv.ParseFloat(v.String(), 64); err == nil {
+				return f
+			}
+		}
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+	}
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return nil
+}
+
+// pathFilterParser parses filter expression.
+//
+//	expr       := or
+//	or         := and ( '||' and )*
+//	and        := unary ( '&&' unary )*
+//	unary      := '!' unary | '(' expr ')' | comparison | query
+//	comparison := operand ( '==' | '!=' | '<' | '<=' | '>' | '>=' ) operand
+//	operand    := query | number | string | true | false | null
+//	query      := '@' ( '.' name | '[' ( string | integer ) ']' )*
+type pathFilterParser struct {
+	buf []rune
+	pos int
+}
+
+func parsePathFilter(buf []rune) (pathFilterExpr, error) {
+	p := &pathFilterParser{buf: buf}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.buf) {
+		return nil, errors.ErrInvalidPath("unexpected %q in filter expression", string(p.buf[p.pos:]))
+	}
+	return expr, nil
+}
+
+func (p *pathFilterParser) skipSpace() {
+	for p.pos < len(p.buf) && unicode.IsSpace(p.buf[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *pathFilterParser) consume(s string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(string(p.buf[p.pos:]), s) {
+		p.pos += len([]rune(s))
+		return true
+	}
+	return false
+}
+
+func (p *pathFilterParser) parseOr() (pathFilterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &pathFilterOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *pathFilterParser) parseAnd() (pathFilterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &pathFilterAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *pathFilterParser) parseUnary() (pathFilterExpr, error) {
+	p.skipSpace()
+	if p.pos >= len(p.buf) {
+		return nil, errors.ErrInvalidPath("filter expression ends unexpectedly")
+	}
+	switch p.buf[p.pos] {
+	case '!':
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &pathFilterNot{expr: expr}, nil
+	case '(':
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, errors.ErrInvalidPath("couldn't find right parenthesis in filter expression")
+		}
+		return expr, nil
+	}
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return &pathFilterCompare{op: op, left: left, right: right}, nil
+		}
+	}
+	query, ok := left.(*pathFilterQuery)
+	if !ok {
+		return nil, errors.ErrInvalidPath("literal must be compared in filter expression")
+	}
+	return &pathFilterExists{query: query}, nil
+}
+
+func (p *pathFilterParser) parseOperand() (pathFilterOperand, error) {
+	p.skipSpace()
+	if p.pos >= len(p.buf) {
+		return nil, errors.ErrInvalidPath("filter expression ends unexpectedly")
+	}
+	switch c := p.buf[p.pos]; {
+	case c == '@':
+		p.pos++
+		return p.parseQuery()
+	case c == '$':
+		return nil, errors.ErrInvalidPath("root node reference in filter expression is not supported")
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &pathFilterLiteral{v: s}, nil
+	case c == '-' || ('0' <= c && c <= '9'):
+		return p.parseNumber()
+	}
+	for _, lit := range []struct {
+		name string
+		v    interface{}
+	}{{"true", true}, {"false", false}, {"null", nil}} {
+		if p.consume(lit.name) {
+			return &pathFilterLiteral{v: lit.v}, nil
+		}
+	}
+	return nil, errors.ErrInvalidPath("unexpected %q in filter expression", string(p.buf[p.pos:]))
+}
+
+func (p *pathFilterParser) parseQuery() (*pathFilterQuery, error) {
+	query := &pathFilterQuery{}
+	for p.pos < len(p.buf) {
+		switch p.buf[p.pos] {
+		case '.':
+			p.pos++
+			start := p.pos
+			for p.pos < len(p.buf) && isPathNameChar(p.buf[p.pos]) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, errors.ErrInvalidPath("expected member name after dot in filter expression")
+			}
+			query.segments = append(query.segments, pathFilterSegment{name: string(p.buf[start:p.pos])})
+		case '[':
+			p.pos++
+			p.skipSpace()
+			if p.pos < len(p.buf) && (p.buf[p.pos] == '\'' || p.buf[p.pos] == '"') {
+				name, err := p.parseString()
+				if err != nil {
+					return nil, err
+				}
+				query.segments = append(query.segments, pathFilterSegment{name: name})
+			} else {
+				start := p.pos
+				for p.pos < len(p.buf) && p.buf[p.pos] != ']' {
+					p.pos++
+				}
+				idx, err := strconv.Atoi(strings.TrimSpace(string(p.buf[start:p.pos])))
+				if err != nil {
+					return nil, errors.ErrInvalidPath("%q is unexpected index in filter expression", string(p.buf[start:p.pos]))
+				}
+				query.segments = append(query.segments, pathFilterSegment{index: idx, isIndex: true})
+			}
+			if !p.consume("]") {
+				return nil, errors.ErrInvalidPath("couldn't find right bracket in filter expression")
+			}
+		default:
+			return query, nil
+		}
+	}
+	return query, nil
+}
+
+func (p *pathFilterParser) parseString() (string, error) {
+	s, n, err := parsePathQuotedString(p.buf[p.pos:])
+	if err != nil {
+		return "", err
+	}
+	p.pos += n
+	return s, nil
+}
+
+func (p *pathFilterParser) parseNumber() (pathFilterOperand, error) {
+	start := p.pos
+	if p.buf[p.pos] == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.buf) && strings.ContainsRune("0123456789.eE+-", p.buf[p.pos]) {
+		p.pos++
+	}
+	f, err := strconv.ParseFloat(string(p.buf[start:p.pos]), 64)
+	if err != nil {
+		return nil, errors.ErrInvalidPath("%q is unexpected number in filter expression", string(p.buf[start:p.pos]))
+	}
+	return &pathFilterLiteral{v: f}, nil
+}
+
+func isPathNameChar(c rune) bool {
+	return c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c) || c >= 0x80
+}
+
+// parsePathQuotedString parses the string quoted by single or double quote at the beginning of buf.
+// It returns the unescaped string and the number of consumed runes.
+func parsePathQuotedString(buf []rune) (string, int, error) {
+	quote := buf[0]
+	var b strings.Builder
+	for i := 1; i < len(buf); i++ {
+		switch buf[i] {
+		case quote:
+			return b.String(), i + 1, nil
+		case '\\':
+			i++
+			if i >= len(buf) {
+				return "", 0, errors.ErrInvalidPath("JSON Path ends with escape character")
+			}
+			switch buf[i] {
+			case 'b':
+				b.WriteRune('\b')
+			case 'f':
+				b.WriteRune('\f')
+			case 'n':
+				b.WriteRune('\n')
+			case 'r':
+				b.WriteRune('\r')
+			case 't':
+				b.WriteRune('\t')
+			case 'u':
+				if i+4 >= len(buf) {
+					return "", 0, errors.ErrInvalidPath("invalid unicode escape in quoted string")
+				}
+				r, err := strconv.ParseUint(string(buf[i+1:i+5]), 16, 32)
+				if err != nil {
+					return "", 0, errors.ErrInvalidPath("invalid unicode escape in quoted string")
+				}
+				b.WriteRune(rune(r))
+				i += 4
+			default:
+				b.WriteRune(buf[i])
+			}
+		default:
+			b.WriteRune(buf[i])
+		}
+	}
+	return "", 0, errors.ErrInvalidPath("couldn't find quote character in quoted string")
+}
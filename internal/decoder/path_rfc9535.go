@@ -0,0 +1,770 @@
+package decoder
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/goccy/go-json/internal/errors"
+)
+
+// maxPathInt is the maximum absolute value of integer in RFC 9535 JSON Path ( I-JSON range ).
+const maxPathInt = 1<<53 - 1
+
+// rfcSegment is the child segment ( [<selectors>] ) or the descendant segment ( ..[<selectors>] ) of RFC 9535 JSON Path.
+type rfcSegment struct {
+	descendant bool
+	selectors  []pathChildSelector
+}
+
+// singular reports whether the segment selects at most one node.
+func (s *rfcSegment) singular() bool {
+	if s.descendant || len(s.selectors) != 1 {
+		return false
+	}
+	switch s.selectors[0].(type) {
+	case *pathNameSelector, *pathIndexSelector:
+		return true
+	}
+	return false
+}
+
+// rfcQuery is the query of RFC 9535 JSON Path.
+// relative is true for the query that starts with @ in filter expression.
+type rfcQuery struct {
+	relative bool
+	segments []*rfcSegment
+}
+
+func (q *rfcQuery) singular() bool {
+	for _, seg := range q.segments {
+		if !seg.singular() {
+			return false
+		}
+	}
+	return true
+}
+
+// rfcType is the type of expression in filter expression. ( RFC 9535 section 2.4.1 )
+type rfcType int
+
+const (
+	rfcValueType rfcType = iota
+	rfcLogicalType
+	rfcNodesType
+)
+
+type rfcExpr interface {
+	typ() rfcType
+}
+
+type rfcLiteral struct {
+	v interface{}
+}
+
+func (*rfcLiteral) typ() rfcType { return rfcValueType }
+
+type rfcQueryExpr struct {
+	query *rfcQuery
+}
+
+func (*rfcQueryExpr) typ() rfcType { return rfcNodesType }
+
+type rfcFuncExpr struct {
+	fn   *rfcFunction
+	args []rfcExpr
+}
+
+func (e *rfcFuncExpr) typ() rfcType { return e.fn.result }
+
+type rfcOrExpr struct {
+	left  rfcExpr
+	right rfcExpr
+}
+
+func (*rfcOrExpr) typ() rfcType { return rfcLogicalType }
+
+type rfcAndExpr struct {
+	left  rfcExpr
+	right rfcExpr
+}
+
+func (*rfcAndExpr) typ() rfcType { return rfcLogicalType }
+
+type rfcNotExpr struct {
+	expr rfcExpr
+}
+
+func (*rfcNotExpr) typ() rfcType { return rfcLogicalType }
+
+type rfcCompareExpr struct {
+	op    string
+	left  rfcExpr
+	right rfcExpr
+}
+
+func (*rfcCompareExpr) typ() rfcType { return rfcLogicalType }
+
+// rfcFilterSelector is the filter selector of RFC 9535 JSON Path. e.g.) [?@.price < 10]
+type rfcFilterSelector struct {
+	expr rfcExpr
+}
+
+func (s *rfcFilterSelector) selectChildren(c pathContainer) []int {
+	rc, ok := c.(*rfcContainer)
+	if !ok {
+		return nil
+	}
+	var ret []int
+	for i := 0; i < rc.len(); i++ {
+		if rc.ev.test(s.expr, rc.node.child(i)) {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+// BuildRFC9535 builds JSON Path that follows RFC 9535 strictly.
+func (s PathString) BuildRFC9535() (*Path, error) {
+	query, err := parseRFC9535Path([]rune(s))
+	if err != nil {
+		return nil, err
+	}
+	return &Path{
+		RootSelectorOnly: len(query.segments) == 0,
+		RFC9535:          true,
+		query:            query,
+		raw:              string(s),
+	}, nil
+}
+
+type rfcParser struct {
+	buf []rune
+	pos int
+}
+
+func parseRFC9535Path(buf []rune) (*rfcQuery, error) {
+	if len(buf) == 0 {
+		return nil, errors.ErrEmptyPath()
+	}
+	if buf[0] != '$' {
+		return nil, errors.ErrInvalidPath("JSON Path must start with a $ character")
+	}
+	p := &rfcParser{buf: buf, pos: 1}
+	segments, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.buf) {
+		return nil, errors.ErrInvalidPath("remain invalid path %q", string(p.buf[p.pos:]))
+	}
+	return &rfcQuery{segments: segments}, nil
+}
+
+func (p *rfcParser) eof() bool {
+	return p.pos >= len(p.buf)
+}
+
+func (p *rfcParser) char() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.buf[p.pos]
+}
+
+func (p *rfcParser) skipBlank() {
+	for !p.eof() {
+		switch p.buf[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *rfcParser) errorf(msg string, args ...interface{}) error {
+	return errors.ErrInvalidPath(msg+" at %d", append(args, p.pos)...)
+}
+
+func (p *rfcParser) parseSegments() ([]*rfcSegment, error) {
+	var segments []*rfcSegment
+	for {
+		// blank characters are allowed only before the segment.
+		start := p.pos
+		p.skipBlank()
+		if c := p.char(); c != '.' && c != '[' {
+			p.pos = start
+			return segments, nil
+		}
+		seg, err := p.parseSegment()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, seg)
+	}
+}
+
+func (p *rfcParser) parseSegment() (*rfcSegment, error) {
+	if p.char() == '[' {
+		selectors, err := p.parseBracketedSelection()
+		if err != nil {
+			return nil, err
+		}
+		return &rfcSegment{selectors: selectors}, nil
+	}
+	p.pos++ // skip dot
+	seg := &rfcSegment{}
+	if p.char() == '.' {
+		p.pos++
+		seg.descendant = true
+		if p.char() == '[' {
+			selectors, err := p.parseBracketedSelection()
+			if err != nil {
+				return nil, err
+			}
+			seg.selectors = selectors
+			return seg, nil
+		}
+	}
+	switch c := p.char(); {
+	case c == '*':
+		p.pos++
+		seg.selectors = []pathChildSelector{&pathWildcardSelector{}}
+	case isRFCNameFirst(c):
+		start := p.pos
+		for !p.eof() && (isRFCNameFirst(p.char()) || isDigit(p.char())) {
+			p.pos++
+		}
+		seg.selectors = []pathChildSelector{&pathNameSelector{name: string(p.buf[start:p.pos])}}
+	default:
+		return nil, p.errorf("expected member name or wildcard after dot")
+	}
+	return seg, nil
+}
+
+func (p *rfcParser) parseBracketedSelection() ([]pathChildSelector, error) {
+	p.pos++ // skip left bracket
+	var selectors []pathChildSelector
+	for {
+		p.skipBlank()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, sel)
+		p.skipBlank()
+		switch p.char() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return selectors, nil
+		default:
+			return nil, p.errorf("expected comma or right bracket in bracketed selection")
+		}
+	}
+}
+
+func (p *rfcParser) parseSelector() (pathChildSelector, error) {
+	switch c := p.char(); {
+	case c == '\'' || c == '"':
+		name, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &pathNameSelector{name: name}, nil
+	case c == '*':
+		p.pos++
+		return &pathWildcardSelector{}, nil
+	case c == '?':
+		p.pos++
+		p.skipBlank()
+		expr, err := p.parseLogicalOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkLogical(expr); err != nil {
+			return nil, err
+		}
+		return &rfcFilterSelector{expr: expr}, nil
+	case c == '-' || c == ':' || isDigit(c):
+		return p.parseIndexOrSlice()
+	}
+	return nil, p.errorf("unexpected selector")
+}
+
+func (p *rfcParser) parseIndexOrSlice() (pathChildSelector, error) {
+	start, hasStart, err := p.parseOptionalInt()
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if p.char() != ':' {
+		if !hasStart {
+			return nil, p.errorf("expected index")
+		}
+		return &pathIndexSelector{index: start}, nil
+	}
+	p.pos++
+	p.skipBlank()
+	end, hasEnd, err := p.parseOptionalInt()
+	if err != nil {
+		return nil, err
+	}
+	sel := &pathSliceSelector{start: start, hasStart: hasStart, end: end, hasEnd: hasEnd, step: 1}
+	p.skipBlank()
+	if p.char() == ':' {
+		p.pos++
+		p.skipBlank()
+		step, hasStep, err := p.parseOptionalInt()
+		if err != nil {
+			return nil, err
+		}
+		if hasStep {
+			sel.step = step
+		}
+	}
+	return sel, nil
+}
+
+// parseOptionalInt parses the integer ( "0" / ["-"] DIGIT1 *DIGIT ).
+func (p *rfcParser) parseOptionalInt() (int, bool, error) {
+	start := p.pos
+	if p.char() == '-' {
+		p.pos++
+	}
+	if !isDigit(p.char()) {
+		if p.pos != start {
+			return 0, false, p.errorf("expected digit after minus")
+		}
+		return 0, false, nil
+	}
+	if p.char() == '0' {
+		p.pos++
+		if isDigit(p.char()) || p.pos-start == 2 {
+			return 0, false, p.errorf("invalid integer %q", string(p.buf[start:p.pos]))
+		}
+		return 0, true, nil
+	}
+	for isDigit(p.char()) {
+		p.pos++
+	}
+	v, err := strconv.ParseInt(string(p.buf[start:p.pos]), 10, 64)
+	if err != nil || v > maxPathInt || v < -maxPathInt {
+		return 0, false, p.errorf("integer %q is out of range", string(p.buf[start:p.pos]))
+	}
+	return int(v), true, nil
+}
+
+// parseString parses the string literal quoted by single or double quote.
+func (p *rfcParser) parseString() (string, error) {
+	quote := p.char()
+	p.pos++
+	var b strings.Builder
+	for !p.eof() {
+		c := p.char()
+		p.pos++
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c < 0x20:
+			return "", p.errorf("control character must be escaped in string literal")
+		case c == '\\':
+			r, err := p.parseEscape(quote)
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return "", p.errorf("couldn't find quote character in string literal")
+}
+
+func (p *rfcParser) parseEscape(quote rune) (rune, error) {
+	c := p.char()
+	p.pos++
+	switch c {
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case '/', '\\':
+		return c, nil
+	case quote:
+		return c, nil
+	case 'u':
+		r, err := p.parseHex4()
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case 0xD800 <= r && r <= 0xDBFF:
+			if p.char() != '\\' || p.pos+1 >= len(p.buf) || p.buf[p.pos+1] != 'u' {
+				return 0, p.errorf("high surrogate must be followed by low surrogate")
+			}
+			p.pos += 2
+			low, err := p.parseHex4()
+			if err != nil {
+				return 0, err
+			}
+			if low < 0xDC00 || 0xDFFF < low {
+				return 0, p.errorf("invalid low surrogate")
+			}
+			return (r-0xD800)<<10 + (low - 0xDC00) + 0x10000, nil
+		case 0xDC00 <= r && r <= 0xDFFF:
+			return 0, p.errorf("unexpected low surrogate")
+		}
+		return r, nil
+	}
+	return 0, p.errorf("invalid escape sequence")
+}
+
+func (p *rfcParser) parseHex4() (rune, error) {
+	if p.pos+4 > len(p.buf) {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	v, err := strconv.ParseUint(string(p.buf[p.pos:p.pos+4]), 16, 32)
+	if err != nil {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	p.pos += 4
+	return rune(v), nil
+}
+
+func (p *rfcParser) parseLogicalOr() (rfcExpr, error) {
+	left, err := p.parseLogicalAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		start := p.pos
+		p.skipBlank()
+		if !p.consume("||") {
+			p.pos = start
+			return left, nil
+		}
+		p.skipBlank()
+		right, err := p.parseLogicalAnd()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkLogical(left, right); err != nil {
+			return nil, err
+		}
+		left = &rfcOrExpr{left: left, right: right}
+	}
+}
+
+func (p *rfcParser) parseLogicalAnd() (rfcExpr, error) {
+	left, err := p.parseBasic()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		start := p.pos
+		p.skipBlank()
+		if !p.consume("&&") {
+			p.pos = start
+			return left, nil
+		}
+		p.skipBlank()
+		right, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkLogical(left, right); err != nil {
+			return nil, err
+		}
+		left = &rfcAndExpr{left: left, right: right}
+	}
+}
+
+// parseBasic parses paren-expr, comparison-expr or test-expr.
+// The test-expr is returned as it is, so that it can be used as the function argument.
+func (p *rfcParser) parseBasic() (rfcExpr, error) {
+	switch p.char() {
+	case '!':
+		p.pos++
+		p.skipBlank()
+		if p.char() == '(' {
+			expr, err := p.parseParen()
+			if err != nil {
+				return nil, err
+			}
+			return &rfcNotExpr{expr: expr}, nil
+		}
+		expr, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkLogical(expr); err != nil {
+			return nil, err
+		}
+		start := p.pos
+		p.skipBlank()
+		if p.comparisonOp() != "" {
+			return nil, p.errorf("comparison expression must be enclosed in parentheses to be negated")
+		}
+		p.pos = start
+		return &rfcNotExpr{expr: expr}, nil
+	case '(':
+		return p.parseParen()
+	}
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	start := p.pos
+	p.skipBlank()
+	op := p.comparisonOp()
+	if op == "" {
+		p.pos = start
+		return left, nil
+	}
+	p.pos += len(op)
+	p.skipBlank()
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkComparable(left); err != nil {
+		return nil, err
+	}
+	if err := p.checkComparable(right); err != nil {
+		return nil, err
+	}
+	return &rfcCompareExpr{op: op, left: left, right: right}, nil
+}
+
+func (p *rfcParser) parseParen() (rfcExpr, error) {
+	p.pos++ // skip left parenthesis
+	p.skipBlank()
+	expr, err := p.parseLogicalOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkLogical(expr); err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if p.char() != ')' {
+		return nil, p.errorf("couldn't find right parenthesis")
+	}
+	p.pos++
+	return expr, nil
+}
+
+func (p *rfcParser) comparisonOp() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.hasPrefix(op) {
+			return op
+		}
+	}
+	return ""
+}
+
+func (p *rfcParser) hasPrefix(s string) bool {
+	if p.pos+len(s) > len(p.buf) {
+		return false
+	}
+	return string(p.buf[p.pos:p.pos+len(s)]) == s
+}
+
+func (p *rfcParser) consume(s string) bool {
+	if p.hasPrefix(s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// parsePrimary parses literal, query or function expression.
+func (p *rfcParser) parsePrimary() (rfcExpr, error) {
+	switch c := p.char(); {
+	case c == '@' || c == '$':
+		p.pos++
+		segments, err := p.parseSegments()
+		if err != nil {
+			return nil, err
+		}
+		return &rfcQueryExpr{query: &rfcQuery{relative: c == '@', segments: segments}}, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &rfcLiteral{v: s}, nil
+	case c == '-' || isDigit(c):
+		return p.parseNumber()
+	case 'a' <= c && c <= 'z':
+		start := p.pos
+		for !p.eof() && ('a' <= p.char() && p.char() <= 'z' || p.char() == '_' || isDigit(p.char())) {
+			p.pos++
+		}
+		name := string(p.buf[start:p.pos])
+		if p.char() == '(' {
+			return p.parseFunction(name)
+		}
+		switch name {
+		case "true":
+			return &rfcLiteral{v: true}, nil
+		case "false":
+			return &rfcLiteral{v: false}, nil
+		case "null":
+			return &rfcLiteral{v: nil}, nil
+		}
+		p.pos = start
+	}
+	return nil, p.errorf("unexpected character in filter expression")
+}
+
+// parseNumber parses the number literal ( (int / "-0") [ frac ] [ exp ] ).
+func (p *rfcParser) parseNumber() (rfcExpr, error) {
+	start := p.pos
+	if p.char() == '-' {
+		p.pos++
+	}
+	switch {
+	case p.char() == '0':
+		p.pos++
+		if isDigit(p.char()) {
+			return nil, p.errorf("number must not have leading zeros")
+		}
+	case isDigit(p.char()):
+		for isDigit(p.char()) {
+			p.pos++
+		}
+	default:
+		return nil, p.errorf("expected digit")
+	}
+	if p.char() == '.' {
+		p.pos++
+		if !isDigit(p.char()) {
+			return nil, p.errorf("expected digit after decimal point")
+		}
+		for isDigit(p.char()) {
+			p.pos++
+		}
+	}
+	if p.char() == 'e' || p.char() == 'E' {
+		p.pos++
+		if p.char() == '+' || p.char() == '-' {
+			p.pos++
+		}
+		if !isDigit(p.char()) {
+			return nil, p.errorf("expected digit in exponent")
+		}
+		for isDigit(p.char()) {
+			p.pos++
+		}
+	}
+	f, err := strconv.ParseFloat(string(p.buf[start:p.pos]), 64)
+	if err != nil {
+		return nil, p.errorf("invalid number %q", string(p.buf[start:p.pos]))
+	}
+	return &rfcLiteral{v: f}, nil
+}
+
+func (p *rfcParser) parseFunction(name string) (rfcExpr, error) {
+	fn, exists := rfcFunctions[name]
+	if !exists {
+		return nil, p.errorf("unknown function %s", name)
+	}
+	p.pos++ // skip left parenthesis
+	p.skipBlank()
+	var args []rfcExpr
+	if p.char() == ')' {
+		p.pos++
+	} else {
+		for {
+			arg, err := p.parseLogicalOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			p.skipBlank()
+			if p.char() == ')' {
+				p.pos++
+				break
+			}
+			if p.char() != ',' {
+				return nil, p.errorf("expected comma or right parenthesis in function arguments")
+			}
+			p.pos++
+			p.skipBlank()
+		}
+	}
+	if len(args) != len(fn.params) {
+		return nil, p.errorf("function %s requires %d arguments but got %d", name, len(fn.params), len(args))
+	}
+	for i, arg := range args {
+		if !rfcArgumentAcceptable(fn.params[i], arg) {
+			return nil, p.errorf("invalid argument type for function %s", name)
+		}
+	}
+	return &rfcFuncExpr{fn: fn, args: args}, nil
+}
+
+// rfcArgumentAcceptable reports whether the argument is well-typed for the parameter. ( RFC 9535 section 2.4.3 )
+func rfcArgumentAcceptable(param rfcType, arg rfcExpr) bool {
+	switch param {
+	case rfcValueType:
+		if q, ok := arg.(*rfcQueryExpr); ok {
+			return q.query.singular()
+		}
+		return arg.typ() == rfcValueType
+	case rfcLogicalType:
+		return arg.typ() == rfcLogicalType || arg.typ() == rfcNodesType
+	case rfcNodesType:
+		return arg.typ() == rfcNodesType
+	}
+	return false
+}
+
+// checkLogical checks that the expressions can be used as logical expression.
+func (p *rfcParser) checkLogical(exprs ...rfcExpr) error {
+	for _, expr := range exprs {
+		switch expr.typ() {
+		case rfcLogicalType, rfcNodesType:
+		default:
+			if _, ok := expr.(*rfcLiteral); ok {
+				return p.errorf("literal must be compared")
+			}
+			return p.errorf("function result of value type must be compared")
+		}
+	}
+	return nil
+}
+
+// checkComparable checks that the expression can be used as the operand of comparison.
+func (p *rfcParser) checkComparable(expr rfcExpr) error {
+	if q, ok := expr.(*rfcQueryExpr); ok {
+		if !q.query.singular() {
+			return p.errorf("non-singular query is not comparable")
+		}
+		return nil
+	}
+	if expr.typ() != rfcValueType {
+		return p.errorf("function result of logical type is not comparable")
+	}
+	return nil
+}
+
+func isDigit(c rune) bool {
+	return '0' <= c && c <= '9'
+}
+
+// isRFCNameFirst reports whether c can be the first character of member name shorthand.
+func isRFCNameFirst(c rune) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || c == '_' ||
+		(0x80 <= c && c <= 0xD7FF) || (0xE000 <= c && c <= utf8.MaxRune)
+}
@@ -0,0 +1,600 @@
+package decoder
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/errors"
+)
+
+// rfcNode is the JSON value evaluated by RFC 9535 JSON Path.
+type rfcNode interface {
+	// container returns nil if the node is neither array nor object.
+	container() pathContainer
+	child(i int) rfcNode
+	// value returns the value converted to float64, string, bool, nil, []interface{} or map[string]interface{}.
+	value() interface{}
+}
+
+// rfcRawDocument is the JSON buffer that the rawRFCNode refers to.
+type rfcRawDocument struct {
+	buf        []byte
+	keyDecoder *stringDecoder
+	err        error
+}
+
+// rawRFCNode is the node in the JSON buffer. The children are scanned when the node is selected.
+type rawRFCNode struct {
+	doc        *rfcRawDocument
+	start, end int64
+	depth      int64
+	c          *rawPathContainer
+	scanned    bool
+}
+
+func (n *rawRFCNode) container() pathContainer {
+	if n.scanned {
+		if n.c == nil {
+			return nil
+		}
+		return n.c
+	}
+	n.scanned = true
+	buf := n.doc.buf
+	var (
+		c   *rawPathContainer
+		err error
+	)
+	switch buf[n.start] {
+	case '[':
+		cursor := skipWhiteSpace(buf, n.start+1)
+		if buf[cursor] == ']' {
+			c = &rawPathContainer{buf: buf, array: true}
+		} else {
+			c, _, err = scanRawPathArray(buf, cursor, n.depth+1)
+		}
+	case '{':
+		cursor := skipWhiteSpace(buf, n.start+1)
+		if buf[cursor] == '}' {
+			c = &rawPathContainer{buf: buf}
+		} else {
+			c, _, err = scanRawPathObject(buf, n.doc.keyDecoder, cursor, n.depth+1)
+		}
+	default:
+		return nil
+	}
+	if err != nil {
+		if n.doc.err == nil {
+			n.doc.err = err
+		}
+		return nil
+	}
+	n.c = c
+	return c
+}
+
+func (n *rawRFCNode) child(i int) rfcNode {
+	return &rawRFCNode{doc: n.doc, start: n.c.starts[i], end: n.c.ends[i], depth: n.depth + 1}
+}
+
+func (n *rawRFCNode) value() interface{} {
+	var v interface{}
+	buf, start := rawPathValueBuf(n.doc.buf, n.start, n.end)
+	ctx := &RuntimeContext{Buf: buf, Option: &Option{}}
+	if _, err := pathFilterValueDecoder.Decode(ctx, start, n.depth, unsafe.Pointer(&v)); err != nil {
+		if n.doc.err == nil {
+			n.doc.err = err
+		}
+		return nil
+	}
+	return v
+}
+
+// valueRFCNode is the node of Go value.
+type valueRFCNode struct {
+	v       reflect.Value
+	c       *valuePathContainer
+	scanned bool
+}
+
+func (n *valueRFCNode) container() pathContainer {
+	if !n.scanned {
+		n.scanned = true
+		if c, ok := newValuePathContainer(n.v); ok {
+			n.c = c
+		}
+	}
+	if n.c == nil {
+		return nil
+	}
+	return n.c
+}
+
+func (n *valueRFCNode) child(i int) rfcNode {
+	return &valueRFCNode{v: n.c.value(i)}
+}
+
+func (n *valueRFCNode) value() interface{} {
+	return canonicalPathValue(n.v)
+}
+
+// canonicalPathValue converts the Go value to the value that has the same structure as the decoded JSON value.
+func canonicalPathValue(v reflect.Value) interface{} {
+	v = derefPathValue(v)
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+	}
+	c, ok := newValuePathContainer(v)
+	if !ok {
+		return normalizePathFilterValue(v)
+	}
+	if c.isArray() {
+		arr := make([]interface{}, c.len())
+		for i := range arr {
+			arr[i] = canonicalPathValue(c.value(i))
+		}
+		return arr
+	}
+	obj := make(map[string]interface{}, c.len())
+	for i := 0; i < c.len(); i++ {
+		obj[c.key(i)] = canonicalPathValue(c.value(i))
+	}
+	return obj
+}
+
+// rfcContainer passes the evaluator and the parent node to rfcFilterSelector.
+type rfcContainer struct {
+	pathContainer
+	ev   *rfcEvaluator
+	node rfcNode
+}
+
+// rfcLocation is the location of the node. It is used to build the normalized path.
+type rfcLocation struct {
+	parent    *rfcLocation
+	container rfcNode // the array or object that has the node
+	name      string
+	index     int // index of the node in the container
+	isIndex   bool
+}
+
+// String returns the normalized path ( RFC 9535 section 2.7 ). e.g.) $['store']['book'][0]
+func (l *rfcLocation) String() string {
+	var locs []*rfcLocation
+	for loc := l; loc != nil; loc = loc.parent {
+		locs = append(locs, loc)
+	}
+	var b strings.Builder
+	b.WriteByte('$')
+	for i := len(locs) - 1; i >= 0; i-- {
+		loc := locs[i]
+		if loc.isIndex {
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(loc.index))
+			b.WriteByte(']')
+			continue
+		}
+		b.WriteString("['")
+		writeNormalizedPathName(&b, loc.name)
+		b.WriteString("']")
+	}
+	return b.String()
+}
+
+func writeNormalizedPathName(b *strings.Builder, name string) {
+	for _, r := range name {
+		switch r {
+		case '\'':
+			b.WriteString(`\'`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+}
+
+type rfcMatch struct {
+	node rfcNode
+	loc  *rfcLocation
+}
+
+func (m rfcMatch) childLocation(c pathContainer, idx int) *rfcLocation {
+	loc := &rfcLocation{parent: m.loc, container: m.node, index: idx}
+	if c.isArray() {
+		loc.isIndex = true
+	} else {
+		loc.name = c.key(idx)
+	}
+	return loc
+}
+
+type rfcEvaluator struct {
+	root rfcNode
+}
+
+func (ev *rfcEvaluator) evalSegments(segments []*rfcSegment, input []rfcMatch) []rfcMatch {
+	for _, seg := range segments {
+		var next []rfcMatch
+		for _, m := range input {
+			if seg.descendant {
+				next = ev.descend(seg, m, next)
+			} else {
+				next = ev.selectChildren(seg, m, next)
+			}
+		}
+		input = next
+	}
+	return input
+}
+
+func (ev *rfcEvaluator) selectChildren(seg *rfcSegment, m rfcMatch, out []rfcMatch) []rfcMatch {
+	c := m.node.container()
+	if c == nil {
+		return out
+	}
+	rc := &rfcContainer{pathContainer: c, ev: ev, node: m.node}
+	for _, sel := range seg.selectors {
+		for _, idx := range sel.selectChildren(rc) {
+			out = append(out, rfcMatch{node: m.node.child(idx), loc: m.childLocation(c, idx)})
+		}
+	}
+	return out
+}
+
+// descend applies the selectors to the node and its descendants in document order.
+func (ev *rfcEvaluator) descend(seg *rfcSegment, m rfcMatch, out []rfcMatch) []rfcMatch {
+	out = ev.selectChildren(seg, m, out)
+	c := m.node.container()
+	if c == nil {
+		return out
+	}
+	for i := 0; i < c.len(); i++ {
+		out = ev.descend(seg, rfcMatch{node: m.node.child(i), loc: m.childLocation(c, i)}, out)
+	}
+	return out
+}
+
+func (ev *rfcEvaluator) nodes(q *rfcQuery, cur rfcNode) []rfcMatch {
+	start := ev.root
+	if q.relative {
+		start = cur
+	}
+	return ev.evalSegments(q.segments, []rfcMatch{{node: start}})
+}
+
+// test evaluates the logical expression, or tests the existence of the nodes selected by the query.
+func (ev *rfcEvaluator) test(expr rfcExpr, cur rfcNode) bool {
+	switch e := expr.(type) {
+	case *rfcOrExpr:
+		return ev.test(e.left, cur) || ev.test(e.right, cur)
+	case *rfcAndExpr:
+		return ev.test(e.left, cur) && ev.test(e.right, cur)
+	case *rfcNotExpr:
+		return !ev.test(e.expr, cur)
+	case *rfcCompareExpr:
+		l, lfound := ev.value(e.left, cur)
+		r, rfound := ev.value(e.right, cur)
+		return rfcCompare(e.op, l, lfound, r, rfound)
+	case *rfcQueryExpr:
+		return len(ev.nodes(e.query, cur)) > 0
+	case *rfcFuncExpr:
+		v, _ := ev.call(e, cur)
+		b, _ := v.(bool)
+		return b
+	}
+	return false
+}
+
+// value evaluates the expression of value type. found is false for Nothing.
+func (ev *rfcEvaluator) value(expr rfcExpr, cur rfcNode) (interface{}, bool) {
+	switch e := expr.(type) {
+	case *rfcLiteral:
+		return e.v, true
+	case *rfcQueryExpr:
+		nodes := ev.nodes(e.query, cur)
+		if len(nodes) != 1 {
+			return nil, false
+		}
+		return nodes[0].node.value(), true
+	case *rfcFuncExpr:
+		return ev.call(e, cur)
+	}
+	return nil, false
+}
+
+func (ev *rfcEvaluator) call(e *rfcFuncExpr, cur rfcNode) (interface{}, bool) {
+	args := make([]rfcArgument, len(e.args))
+	for i, arg := range e.args {
+		switch e.fn.params[i] {
+		case rfcValueType:
+			args[i].value, args[i].found = ev.value(arg, cur)
+		case rfcLogicalType:
+			args[i].logical = ev.test(arg, cur)
+		case rfcNodesType:
+			args[i].nodes = ev.nodes(arg.(*rfcQueryExpr).query, cur)
+		}
+	}
+	return e.fn.call(args)
+}
+
+func rfcCompare(op string, l interface{}, lfound bool, r interface{}, rfound bool) bool {
+	switch op {
+	case "==":
+		return rfcEqual(l, lfound, r, rfound)
+	case "!=":
+		return !rfcEqual(l, lfound, r, rfound)
+	case "<":
+		return pathFilterLess(l, lfound, r, rfound)
+	case "<=":
+		return pathFilterLess(l, lfound, r, rfound) || rfcEqual(l, lfound, r, rfound)
+	case ">":
+		return pathFilterLess(r, rfound, l, lfound)
+	case ">=":
+		return pathFilterLess(r, rfound, l, lfound) || rfcEqual(l, lfound, r, rfound)
+	}
+	return false
+}
+
+func rfcEqual(l interface{}, lfound bool, r interface{}, rfound bool) bool {
+	if !lfound || !rfound {
+		return lfound == rfound
+	}
+	switch lv := l.(type) {
+	case []interface{}:
+		rv, ok := r.([]interface{})
+		if !ok || len(lv) != len(rv) {
+			return false
+		}
+		for i := range lv {
+			if !rfcEqual(lv[i], true, rv[i], true) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		rv, ok := r.(map[string]interface{})
+		if !ok || len(lv) != len(rv) {
+			return false
+		}
+		for k, v := range lv {
+			other, exists := rv[k]
+			if !exists || !rfcEqual(v, true, other, true) {
+				return false
+			}
+		}
+		return true
+	}
+	return pathFilterEqual(l, true, r, true)
+}
+
+// rfcArgument is the evaluated argument of function.
+type rfcArgument struct {
+	value   interface{}
+	found   bool
+	logical bool
+	nodes   []rfcMatch
+}
+
+// rfcFunction is the function extension of RFC 9535 JSON Path.
+type rfcFunction struct {
+	params []rfcType
+	result rfcType
+	// call returns the result value. found is false for Nothing.
+	call func(args []rfcArgument) (v interface{}, found bool)
+}
+
+var rfcFunctions = map[string]*rfcFunction{
+	"length": {
+		params: []rfcType{rfcValueType},
+		result: rfcValueType,
+		call: func(args []rfcArgument) (interface{}, bool) {
+			switch v := args[0].value; v := v.(type) {
+			case string:
+				return float64(utf8.RuneCountInString(v)), true
+			case []interface{}:
+				return float64(len(v)), true
+			case map[string]interface{}:
+				return float64(len(v)), true
+			}
+			return nil, false
+		},
+	},
+	"count": {
+		params: []rfcType{rfcNodesType},
+		result: rfcValueType,
+		call: func(args []rfcArgument) (interface{}, bool) {
+			return float64(len(args[0].nodes)), true
+		},
+	},
+	"match": {
+		params: []rfcType{rfcValueType, rfcValueType},
+		result: rfcLogicalType,
+		call: func(args []rfcArgument) (interface{}, bool) {
+			return rfcRegexpMatch(args[0].value, args[1].value, true), true
+		},
+	},
+	"search": {
+		params: []rfcType{rfcValueType, rfcValueType},
+		result: rfcLogicalType,
+		call: func(args []rfcArgument) (interface{}, bool) {
+			return rfcRegexpMatch(args[0].value, args[1].value, false), true
+		},
+	},
+	"value": {
+		params: []rfcType{rfcNodesType},
+		result: rfcValueType,
+		call: func(args []rfcArgument) (interface{}, bool) {
+			if len(args[0].nodes) != 1 {
+				return nil, false
+			}
+			return args[0].nodes[0].node.value(), true
+		},
+	},
+}
+
+func rfcRegexpMatch(v, pattern interface{}, full bool) bool {
+	s, ok := v.(string)
+	if !ok {
+		return false
+	}
+	p, ok := pattern.(string)
+	if !ok {
+		return false
+	}
+	re, err := compileIRegexp(p, full)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(s)
+}
+
+// compileIRegexp compiles the I-Regexp ( RFC 9485 ) pattern.
+// The dot of I-Regexp doesn't match \n and \r, so it is replaced with the character class.
+func compileIRegexp(pattern string, full bool) (*regexp.Regexp, error) {
+	var b strings.Builder
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			b.WriteByte(c)
+			i++
+			b.WriteByte(pattern[i])
+		case c == '[':
+			inClass = true
+			b.WriteByte(c)
+		case c == ']':
+			inClass = false
+			b.WriteByte(c)
+		case c == '.' && !inClass:
+			b.WriteString(`[^\n\r]`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if full {
+		return regexp.Compile(`^(?:` + b.String() + `)$`)
+	}
+	return regexp.Compile(b.String())
+}
+
+// ExtractWithPaths extracts the JSON values and their normalized paths from the buffer that ends with nul byte.
+// It returns the cursor after the root value.
+func (p *Path) ExtractWithPaths(buf []byte) ([][]byte, []string, int64, error) {
+	matches, end, err := p.evalRaw(buf)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	values := make([][]byte, 0, len(matches))
+	paths := make([]string, 0, len(matches))
+	for _, m := range matches {
+		node := m.node.(*rawRFCNode)
+		values = append(values, buf[node.start:node.end])
+		paths = append(paths, m.loc.String())
+	}
+	return values, paths, end, nil
+}
+
+// evalRaw evaluates the path on the buffer that ends with nul byte.
+// It returns the cursor after the root value.
+func (p *Path) evalRaw(buf []byte) ([]rfcMatch, int64, error) {
+	segments, ok := p.segments()
+	if !ok {
+		return nil, 0, errors.ErrInvalidPath("%s cannot be evaluated on the buffer", p.String())
+	}
+	return evalRawSegments(buf, segments)
+}
+
+func evalRawSegments(buf []byte, segments []*rfcSegment) ([]rfcMatch, int64, error) {
+	start := skipWhiteSpace(buf, 0)
+	end, err := skipValue(buf, start, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	doc := &rfcRawDocument{buf: buf, keyDecoder: newStringDecoder("", "")}
+	root := &rawRFCNode{doc: doc, start: start, end: end}
+	ev := &rfcEvaluator{root: root}
+	matches := ev.evalSegments(segments, []rfcMatch{{node: root}})
+	if doc.err != nil {
+		return nil, 0, doc.err
+	}
+	return matches, end, nil
+}
+
+func (p *Path) getRFC9535(src, dst reflect.Value) error {
+	root := &valueRFCNode{v: src}
+	ev := &rfcEvaluator{root: root}
+	matches := ev.evalSegments(p.query.segments, []rfcMatch{{node: root}})
+	arr := make([]interface{}, 0, len(matches))
+	for _, m := range matches {
+		var v interface{}
+		if err := AssignValue(m.node.(*valueRFCNode).v, reflect.ValueOf(&v)); err != nil {
+			return err
+		}
+		arr = append(arr, v)
+	}
+	return AssignValue(reflect.ValueOf(arr), dst)
+}
+
+// segments returns the segments to evaluate the path.
+// The path built by PathBuilder is converted to the equivalent segments.
+// It returns false if the path has the node that cannot be converted.
+func (p *Path) segments() ([]*rfcSegment, bool) {
+	if p.query != nil {
+		return p.query.segments, true
+	}
+	segments := []*rfcSegment{}
+	for node := p.node; node != nil; {
+		switch n := node.(type) {
+		case *PathSelectorNode:
+			segments = append(segments, &rfcSegment{selectors: []pathChildSelector{&pathNameSelector{name: n.selector}}})
+			node = n.child
+		case *PathIndexNode:
+			segments = append(segments, &rfcSegment{selectors: []pathChildSelector{&pathIndexSelector{index: n.selector}}})
+			node = n.child
+		case *PathIndexAllNode:
+			segments = append(segments, &rfcSegment{selectors: []pathChildSelector{&pathWildcardSelector{}}})
+			node = n.child
+		case *PathUnionNode:
+			segments = append(segments, &rfcSegment{selectors: n.selectors})
+			node = n.child
+		case *PathRecursiveNode:
+			// the child of PathRecursiveNode is the selector node of the same name.
+			segments = append(segments, &rfcSegment{descendant: true, selectors: []pathChildSelector{&pathNameSelector{name: n.selector}}})
+			selector, ok := n.child.(*PathSelectorNode)
+			if !ok {
+				return nil, false
+			}
+			node = selector.child
+		default:
+			return nil, false
+		}
+	}
+	return segments, true
+}
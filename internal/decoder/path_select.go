@@ -0,0 +1,621 @@
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/errors"
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+// pathContainer is the array or object whose children are selected by pathChildSelector.
+type pathContainer interface {
+	isArray() bool
+	len() int
+	// key returns the member name of i-th child. It is used only for object.
+	key(i int) string
+	// value returns the i-th child value. It is used by filter selector.
+	value(i int) reflect.Value
+}
+
+// pathChildSelector selects the children of array or object.
+// It returns the indices of the selected children in selection order.
+type pathChildSelector interface {
+	selectChildren(c pathContainer) []int
+}
+
+// pathNameSelector selects the member of object by name. e.g.) ['a']
+type pathNameSelector struct {
+	name string
+}
+
+func (s *pathNameSelector) selectChildren(c pathContainer) []int {
+	if c.isArray() {
+		return nil
+	}
+	var ret []int
+	for i := 0; i < c.len(); i++ {
+		if c.key(i) == s.name {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+// pathIndexSelector selects the element of array by index. Negative index counts from the end. e.g.) [-1]
+type pathIndexSelector struct {
+	index int
+}
+
+func (s *pathIndexSelector) selectChildren(c pathContainer) []int {
+	if !c.isArray() {
+		return nil
+	}
+	idx := s.index
+	if idx < 0 {
+		idx += c.len()
+	}
+	if idx < 0 || idx >= c.len() {
+		return nil
+	}
+	return []int{idx}
+}
+
+// pathSliceSelector selects the elements of array by range. e.g.) [1:5:2]
+type pathSliceSelector struct {
+	start, end       int
+	hasStart, hasEnd bool
+	step             int
+}
+
+func (s *pathSliceSelector) selectChildren(c pathContainer) []int {
+	if !c.isArray() || s.step == 0 {
+		return nil
+	}
+	length := c.len()
+	normalize := func(idx int) int {
+		if idx < 0 {
+			return idx + length
+		}
+		return idx
+	}
+	clamp := func(idx, min, max int) int {
+		if idx < min {
+			return min
+		}
+		if idx > max {
+			return max
+		}
+		return idx
+	}
+	var ret []int
+	if s.step > 0 {
+		lower, upper := 0, length
+		if s.hasStart {
+			lower = clamp(normalize(s.start), 0, length)
+		}
+		if s.hasEnd {
+			upper = clamp(normalize(s.end), 0, length)
+		}
+		for i := lower; i < upper; i += s.step {
+			ret = append(ret, i)
+		}
+		return ret
+	}
+	upper, lower := length-1, -1
+	if s.hasStart {
+		upper = clamp(normalize(s.start), -1, length-1)
+	}
+	if s.hasEnd {
+		lower = clamp(normalize(s.end), -1, length-1)
+	}
+	for i := upper; i > lower; i += s.step {
+		ret = append(ret, i)
+	}
+	return ret
+}
+
+// pathWildcardSelector selects all children. e.g.) [*]
+type pathWildcardSelector struct{}
+
+func (s *pathWildcardSelector) selectChildren(c pathContainer) []int {
+	ret := make([]int, c.len())
+	for i := range ret {
+		ret[i] = i
+	}
+	return ret
+}
+
+// pathFilterSelector selects the children that satisfy the filter expression. e.g.) [?(@.price < 10)]
+type pathFilterSelector struct {
+	expr pathFilterExpr
+}
+
+func (s *pathFilterSelector) selectChildren(c pathContainer) []int {
+	var ret []int
+	for i := 0; i < c.len(); i++ {
+		if s.expr.eval(c.value(i)) {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+// findPathRightBracket returns the position of the right bracket that closes the bracketed selection.
+// Brackets and parentheses in quoted strings or filter expression are skipped.
+func findPathRightBracket(buf []rune) (int, error) {
+	depth := 0
+	for cursor := 0; cursor < len(buf); cursor++ {
+		switch buf[cursor] {
+		case '\'', '"':
+			_, n, err := parsePathQuotedString(buf[cursor:])
+			if err != nil {
+				return 0, err
+			}
+			cursor += n - 1
+		case '(', '[':
+			depth++
+		case ')':
+			depth--
+		case ']':
+			if depth == 0 {
+				return cursor, nil
+			}
+			depth--
+		}
+	}
+	return 0, errors.ErrInvalidPath("couldn't find right bracket character in index path context")
+}
+
+// splitPathSelectors splits the bracketed selection by comma. e.g.) 'a',0,1:3
+func splitPathSelectors(buf []rune) ([][]rune, error) {
+	var (
+		ret   [][]rune
+		depth int
+		start int
+	)
+	for cursor := 0; cursor < len(buf); cursor++ {
+		switch buf[cursor] {
+		case '\'', '"':
+			_, n, err := parsePathQuotedString(buf[cursor:])
+			if err != nil {
+				return nil, err
+			}
+			cursor += n - 1
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				ret = append(ret, buf[start:cursor])
+				start = cursor + 1
+			}
+		}
+	}
+	return append(ret, buf[start:]), nil
+}
+
+func parsePathSelector(buf []rune) (pathChildSelector, error) {
+	sel := []rune(strings.TrimSpace(string(buf)))
+	if len(sel) == 0 {
+		return nil, errors.ErrInvalidPath("found empty selector in index path")
+	}
+	switch sel[0] {
+	case '\'', '"':
+		name, n, err := parsePathQuotedString(sel)
+		if err != nil {
+			return nil, err
+		}
+		if n != len(sel) {
+			return nil, errors.ErrInvalidPath("found invalid character after quoted name %q", string(sel[n:]))
+		}
+		return &pathNameSelector{name: name}, nil
+	case '*':
+		if len(sel) != 1 {
+			return nil, errors.ErrInvalidPath("expect right bracket character for index all path but found %c character", sel[1])
+		}
+		return &pathWildcardSelector{}, nil
+	case '?':
+		expr, err := parsePathFilter(sel[1:])
+		if err != nil {
+			return nil, err
+		}
+		return &pathFilterSelector{expr: expr}, nil
+	}
+	if strings.ContainsRune(string(sel), ':') {
+		return parsePathSliceSelector(sel)
+	}
+	index, err := strconv.ParseInt(string(sel), 10, 64)
+	if err != nil {
+		return nil, errors.ErrInvalidPath("%q is unexpected index path", string(sel))
+	}
+	return &pathIndexSelector{index: int(index)}, nil
+}
+
+func parsePathSliceSelector(sel []rune) (*pathSliceSelector, error) {
+	parts := strings.Split(string(sel), ":")
+	if len(parts) > 3 {
+		return nil, errors.ErrInvalidPath("%q is unexpected slice path", string(sel))
+	}
+	values := make([]int, len(parts))
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		v, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, errors.ErrInvalidPath("%q is unexpected slice path", string(sel))
+		}
+		values[i] = int(v)
+	}
+	s := &pathSliceSelector{
+		start:    values[0],
+		hasStart: strings.TrimSpace(parts[0]) != "",
+		end:      values[1],
+		hasEnd:   strings.TrimSpace(parts[1]) != "",
+		step:     1,
+	}
+	if len(parts) == 3 && strings.TrimSpace(parts[2]) != "" {
+		s.step = values[2]
+	}
+	return s, nil
+}
+
+// PathUnionNode selects the children by the list of selectors.
+// It is used for negative index, array slice, union and filter expression. e.g.) [-1], [1:5:2], ['a','b'], [?(@.price < 10)]
+type PathUnionNode struct {
+	*BasePathNode
+	selectors []pathChildSelector
+	raw       string
+}
+
+func newPathUnionNode(selectors []pathChildSelector, raw string) *PathUnionNode {
+	return &PathUnionNode{
+		BasePathNode: &BasePathNode{},
+		selectors:    selectors,
+		raw:          raw,
+	}
+}
+
+func (n *PathUnionNode) selectChildren(c pathContainer) []int {
+	var ret []int
+	for _, sel := range n.selectors {
+		ret = append(ret, sel.selectChildren(c)...)
+	}
+	return ret
+}
+
+// Index and Field are not used because the selection depends on the other children.
+// The decoder selects the children by decodePathUnion instead.
+func (n *PathUnionNode) Index(idx int) (PathNode, bool, error) {
+	return nil, false, errors.ErrInvalidPath("[%s] cannot select the array element %d by itself", n.raw, idx)
+}
+
+func (n *PathUnionNode) Field(fieldName string) (PathNode, bool, error) {
+	return nil, false, errors.ErrInvalidPath("[%s] cannot select the object member %q by itself", n.raw, fieldName)
+}
+
+// pathNodeSelects reports whether node selects the children of the object and the array.
+// The union selects the children of any kind, so the child whose kind doesn't match the next node is skipped.
+func pathNodeSelects(node PathNode) (object bool, array bool) {
+	switch node.(type) {
+	case *PathSelectorNode:
+		return true, false
+	case *PathIndexNode, *PathIndexAllNode:
+		return false, true
+	}
+	return true, true
+}
+
+// selectsRawValue reports whether node selects the children of the JSON value at the cursor.
+func selectsRawValue(node PathNode, buf []byte, cursor int64) bool {
+	object, array := pathNodeSelects(node)
+	switch buf[skipWhiteSpace(buf, cursor)] {
+	case '{':
+		return object
+	case '[':
+		return array
+	}
+	return false
+}
+
+// selectsValue is the same as selectsRawValue, but for the Go value.
+func selectsValue(node PathNode, v reflect.Value) bool {
+	object, array := pathNodeSelects(node)
+	v = derefPathValue(v)
+	if !v.IsValid() {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Map, reflect.Struct:
+		return object
+	case reflect.Array, reflect.Slice:
+		return array
+	}
+	return false
+}
+
+func (n *PathUnionNode) Get(src, dst reflect.Value) error {
+	c, ok := newValuePathContainer(src)
+	if !ok {
+		return fmt.Errorf("failed to get [%s] value from %s", n.raw, src.Type())
+	}
+	var arr []interface{}
+	for _, idx := range n.selectChildren(c) {
+		if n.child != nil && !selectsValue(n.child, c.value(idx)) {
+			continue
+		}
+		var v interface{}
+		rv := reflect.ValueOf(&v)
+		if n.child != nil {
+			if err := n.child.Get(c.value(idx), rv); err != nil {
+				return err
+			}
+		} else {
+			if err := AssignValue(c.value(idx), rv); err != nil {
+				return err
+			}
+		}
+		arr = append(arr, v)
+	}
+	return AssignValue(reflect.ValueOf(arr), dst)
+}
+
+func (n *PathUnionNode) String() string {
+	s := fmt.Sprintf("[%s]", n.raw)
+	if n.child != nil {
+		s += n.child.String()
+	}
+	return s
+}
+
+// valuePathContainer is the pathContainer of Go value.
+type valuePathContainer struct {
+	v      reflect.Value
+	keys   []string
+	values []reflect.Value
+}
+
+func newValuePathContainer(src reflect.Value) (*valuePathContainer, bool) {
+	v := derefPathValue(src)
+	if !v.IsValid() {
+		return nil, false
+	}
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		return &valuePathContainer{v: v}, true
+	case reflect.Map:
+		c := &valuePathContainer{v: v}
+		type member struct {
+			key   string
+			value reflect.Value
+		}
+		members := make([]member, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			members = append(members, member{key: mapKeyValueToken(iter.Key()), value: iter.Value()})
+		}
+		// map has no order, so the members are sorted by name to make the result stable.
+		sort.Slice(members, func(i, j int) bool { return members[i].key < members[j].key })
+		for _, m := range members {
+			c.keys = append(c.keys, m.key)
+			c.values = append(c.values, m.value)
+		}
+		return c, true
+	case reflect.Struct:
+		c := &valuePathContainer{v: v}
+		typ := v.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if runtime.IsIgnoredStructField(field) {
+				continue
+			}
+			c.keys = append(c.keys, runtime.StructTagFromField(field).Key)
+			c.values = append(c.values, v.Field(i))
+		}
+		return c, true
+	}
+	return nil, false
+}
+
+func (c *valuePathContainer) isArray() bool {
+	kind := c.v.Kind()
+	return kind == reflect.Array || kind == reflect.Slice
+}
+
+func (c *valuePathContainer) len() int {
+	if c.isArray() {
+		return c.v.Len()
+	}
+	return len(c.keys)
+}
+
+func (c *valuePathContainer) key(i int) string {
+	return c.keys[i]
+}
+
+func (c *valuePathContainer) value(i int) reflect.Value {
+	if c.isArray() {
+		return c.v.Index(i)
+	}
+	return c.values[i]
+}
+
+// structFieldIndexByKey returns the index of the struct field that has the key name.
+func structFieldIndexByKey(typ reflect.Type, key string) (int, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if runtime.IsIgnoredStructField(field) {
+			continue
+		}
+		if runtime.StructTagFromField(field).Key == key {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+var pathFilterValueDecoder = newEmptyInterfaceDecoder("", "")
+
+// rawPathContainer is the pathContainer of the array or object in the JSON buffer.
+// Children are decoded only when the filter expression refers to them.
+type rawPathContainer struct {
+	buf       []byte
+	array     bool
+	keys      []string
+	keyStarts []int64
+	starts    []int64
+	ends      []int64
+	values    []*reflect.Value
+}
+
+func (c *rawPathContainer) isArray() bool {
+	return c.array
+}
+
+func (c *rawPathContainer) len() int {
+	return len(c.starts)
+}
+
+func (c *rawPathContainer) key(i int) string {
+	return c.keys[i]
+}
+
+func (c *rawPathContainer) value(i int) reflect.Value {
+	if c.values == nil {
+		c.values = make([]*reflect.Value, len(c.starts))
+	}
+	if c.values[i] != nil {
+		return *c.values[i]
+	}
+	var v interface{}
+	buf, start := rawPathValueBuf(c.buf, c.starts[i], c.ends[i])
+	ctx := &RuntimeContext{Buf: buf, Option: &Option{}}
+	rv := reflect.ValueOf(&v).Elem()
+	if _, err := pathFilterValueDecoder.Decode(ctx, start, 0, unsafe.Pointer(&v)); err != nil {
+		// the value is already validated by skipValue, so this doesn't happen.
+		rv = reflect.Value{}
+	}
+	c.values[i] = &rv
+	return rv
+}
+
+// scanRawPathArray scans the elements of array. The cursor must point to the first element.
+// It returns the cursor after the right bracket.
+func scanRawPathArray(buf []byte, cursor, depth int64) (*rawPathContainer, int64, error) {
+	c := &rawPathContainer{buf: buf, array: true}
+	for {
+		cursor = skipWhiteSpace(buf, cursor)
+		end, err := skipValue(buf, cursor, depth)
+		if err != nil {
+			return nil, 0, err
+		}
+		c.starts = append(c.starts, cursor)
+		c.ends = append(c.ends, end)
+		cursor = skipWhiteSpace(buf, end)
+		switch buf[cursor] {
+		case ']':
+			cursor++
+			return c, cursor, nil
+		case ',':
+			cursor++
+		default:
+			return nil, 0, errors.ErrInvalidCharacter(buf[cursor], "slice", cursor)
+		}
+	}
+}
+
+// scanRawPathObject scans the members of object. The cursor must point to the first key.
+// It returns the cursor after the right brace.
+func scanRawPathObject(buf []byte, keyDecoder *stringDecoder, cursor, depth int64) (*rawPathContainer, int64, error) {
+	c := &rawPathContainer{buf: buf}
+	for {
+		keyStart := cursor
+		key, keyCursor, err := decodeRawPathString(keyDecoder, buf, cursor)
+		if err != nil {
+			return nil, 0, err
+		}
+		cursor = skipWhiteSpace(buf, keyCursor)
+		if buf[cursor] != ':' {
+			return nil, 0, errors.ErrExpected("colon after object key", cursor)
+		}
+		cursor = skipWhiteSpace(buf, cursor+1)
+		end, err := skipValue(buf, cursor, depth)
+		if err != nil {
+			return nil, 0, err
+		}
+		c.keys = append(c.keys, string(key))
+		c.keyStarts = append(c.keyStarts, keyStart)
+		c.starts = append(c.starts, cursor)
+		c.ends = append(c.ends, end)
+		cursor = skipWhiteSpace(buf, end)
+		if buf[cursor] == '}' {
+			cursor++
+			return c, cursor, nil
+		}
+		if buf[cursor] != ',' {
+			return nil, 0, errors.ErrExpected("comma after object value", cursor)
+		}
+		cursor = skipWhiteSpace(buf, cursor+1)
+	}
+}
+
+// decodeRawPathString decodes the string at the cursor.
+// stringDecoder unescapes the string in the buffer, so the escaped string is decoded from the copy
+// to keep the buffer that is referred to after the string is decoded.
+func decodeRawPathString(d *stringDecoder, buf []byte, cursor int64) ([]byte, int64, error) {
+	cursor = skipWhiteSpace(buf, cursor)
+	if buf[cursor] != '"' {
+		return d.decodeByte(buf, cursor)
+	}
+	end, err := skipValue(buf, cursor, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	src, start := rawPathValueBuf(buf, cursor, end)
+	s, _, err := d.decodeByte(src, start)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s, end, nil
+}
+
+// rawPathValueBuf returns the buffer and the cursor to decode the value from start to end.
+// The decoder unescapes the strings in the buffer, so the value that has the escaped strings is decoded from the copy.
+func rawPathValueBuf(buf []byte, start, end int64) ([]byte, int64) {
+	if bytes.IndexByte(buf[start:end], '\\') < 0 {
+		return buf, start
+	}
+	src := make([]byte, end-start+1) // append nul byte to the end
+	copy(src, buf[start:end])
+	return src, 0
+}
+
+// decodePathUnion selects the children of the array or object in the JSON buffer by PathUnionNode.
+func decodePathUnion(ctx *RuntimeContext, node *PathUnionNode, c *rawPathContainer, valueDecoder Decoder, depth int64) ([][]byte, error) {
+	ret := [][]byte{}
+	for _, idx := range node.selectChildren(c) {
+		if node.child == nil {
+			ret = append(ret, ctx.Buf[c.starts[idx]:c.ends[idx]])
+			continue
+		}
+		if !selectsRawValue(node.child, ctx.Buf, c.starts[idx]) {
+			continue
+		}
+		ctx.Option.Path.node = node.child
+		paths, _, err := valueDecoder.DecodePath(ctx, c.starts[idx], depth)
+		ctx.Option.Path.node = node
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, paths...)
+	}
+	return ret, nil
+}
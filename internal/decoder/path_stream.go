@@ -0,0 +1,375 @@
+package decoder
+
+import (
+	"sort"
+
+	"github.com/goccy/go-json/internal/errors"
+)
+
+// pathStreamer extracts the values selected by the path from the stream.
+//
+// The segments are evaluated while the stream is scanned: each value has the states
+// that are the indices of the segments to be applied to the value.
+// Only the selected values ( and the values needed to test filter selectors ) are buffered,
+// and the other values are skipped without being buffered entirely.
+type pathStreamer struct {
+	s          *Stream
+	segments   []*rfcSegment
+	keyDecoder *stringDecoder
+	fn         func([]byte) error
+}
+
+// ExtractStream calls fn with the values selected by the path from the stream in document order.
+// The value passed to fn is not reused, so fn can retain it.
+func (p *Path) ExtractStream(s *Stream, fn func([]byte) error) error {
+	segments, ok := p.segments()
+	if !ok {
+		return errors.ErrInvalidPath("%s cannot be evaluated on the stream", p.String())
+	}
+	ps := &pathStreamer{
+		s:          s,
+		segments:   segments,
+		keyDecoder: newStringDecoder("", ""),
+		fn:         fn,
+	}
+	states := []int{0}
+	if rfcSegmentsUseRoot(segments) {
+		// the filter refers to the root value, so the whole document is needed.
+		if err := ps.capture(states, 0); err != nil {
+			return err
+		}
+	} else if err := ps.walk(states, 0); err != nil {
+		return err
+	}
+	return s.ValidateEnd()
+}
+
+// rfcSegmentsUseRoot reports whether the filter selectors in the segments refer to the root value.
+func rfcSegmentsUseRoot(segments []*rfcSegment) bool {
+	for _, seg := range segments {
+		for _, sel := range seg.selectors {
+			if f, ok := sel.(*rfcFilterSelector); ok && rfcExprUsesRoot(f.expr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func rfcExprUsesRoot(expr rfcExpr) bool {
+	switch e := expr.(type) {
+	case *rfcQueryExpr:
+		return !e.query.relative || rfcSegmentsUseRoot(e.query.segments)
+	case *rfcFuncExpr:
+		for _, arg := range e.args {
+			if rfcExprUsesRoot(arg) {
+				return true
+			}
+		}
+	case *rfcOrExpr:
+		return rfcExprUsesRoot(e.left) || rfcExprUsesRoot(e.right)
+	case *rfcAndExpr:
+		return rfcExprUsesRoot(e.left) || rfcExprUsesRoot(e.right)
+	case *rfcNotExpr:
+		return rfcExprUsesRoot(e.expr)
+	case *rfcCompareExpr:
+		return rfcExprUsesRoot(e.left) || rfcExprUsesRoot(e.right)
+	}
+	return false
+}
+
+func appendPathState(states []int, state int) []int {
+	for _, s := range states {
+		if s == state {
+			return states
+		}
+	}
+	return append(states, state)
+}
+
+// streamable reports whether the children can be selected without knowing the length of the array.
+func (ps *pathStreamer) streamable(states []int) bool {
+	for _, i := range states {
+		if i == len(ps.segments) {
+			return false
+		}
+		for _, sel := range ps.segments[i].selectors {
+			switch sel := sel.(type) {
+			case *pathIndexSelector:
+				if sel.index < 0 {
+					return false
+				}
+			case *pathSliceSelector:
+				if sel.step < 0 || (sel.hasStart && sel.start < 0) || (sel.hasEnd && sel.end < 0) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// childStates returns the states of the child. The states of filter selectors are returned separately,
+// because the child value is needed to test them.
+func (ps *pathStreamer) childStates(states []int, name []byte, idx int, isArray bool) ([]int, []int) {
+	var next, filters []int
+	for _, i := range states {
+		seg := ps.segments[i]
+		if seg.descendant {
+			next = appendPathState(next, i)
+		}
+		for _, sel := range seg.selectors {
+			switch sel := sel.(type) {
+			case *pathNameSelector:
+				if !isArray && string(name) == sel.name {
+					next = appendPathState(next, i+1)
+				}
+			case *pathIndexSelector:
+				if isArray && sel.index == idx {
+					next = appendPathState(next, i+1)
+				}
+			case *pathSliceSelector:
+				if !isArray || sel.step == 0 {
+					continue
+				}
+				if (sel.hasStart && idx < sel.start) || (sel.hasEnd && idx >= sel.end) {
+					continue
+				}
+				start := 0
+				if sel.hasStart {
+					start = sel.start
+				}
+				if (idx-start)%sel.step == 0 {
+					next = appendPathState(next, i+1)
+				}
+			case *pathWildcardSelector:
+				next = appendPathState(next, i+1)
+			default:
+				filters = appendPathState(filters, i)
+			}
+		}
+	}
+	return next, filters
+}
+
+func (ps *pathStreamer) walk(states []int, depth int64) error {
+	s := ps.s
+	if len(states) == 0 {
+		return ps.skip(depth)
+	}
+	if !ps.streamable(states) {
+		return ps.capture(states, depth)
+	}
+	switch s.skipWhiteSpace() {
+	case '{':
+		return ps.walkObject(states, depth+1)
+	case '[':
+		return ps.walkArray(states, depth+1)
+	}
+	return s.skipValue(depth)
+}
+
+func (ps *pathStreamer) walkObject(states []int, depth int64) error {
+	s := ps.s
+	if depth > maxDecodeNestingDepth {
+		return errors.ErrExceededMaxDepth(s.char(), s.cursor)
+	}
+	s.cursor++
+	if s.skipWhiteSpace() == '}' {
+		s.cursor++
+		return nil
+	}
+	for {
+		s.discard()
+		if s.skipWhiteSpace() != '"' {
+			return errors.ErrExpected("object key", s.totalOffset())
+		}
+		key, err := ps.keyDecoder.decodeStreamByte(s)
+		if err != nil {
+			return err
+		}
+		next, filters := ps.childStates(states, key, 0, false)
+		if s.skipWhiteSpace() != ':' {
+			return errors.ErrExpected("colon after object key", s.totalOffset())
+		}
+		s.cursor++
+		if err := ps.walkChild(next, filters, depth); err != nil {
+			return err
+		}
+		switch s.skipWhiteSpace() {
+		case '}':
+			s.cursor++
+			return nil
+		case ',':
+			s.cursor++
+		default:
+			return errors.ErrExpected("comma after object value", s.totalOffset())
+		}
+	}
+}
+
+func (ps *pathStreamer) walkArray(states []int, depth int64) error {
+	s := ps.s
+	if depth > maxDecodeNestingDepth {
+		return errors.ErrExceededMaxDepth(s.char(), s.cursor)
+	}
+	s.cursor++
+	if s.skipWhiteSpace() == ']' {
+		s.cursor++
+		return nil
+	}
+	for idx := 0; ; idx++ {
+		s.discard()
+		next, filters := ps.childStates(states, nil, idx, true)
+		if err := ps.walkChild(next, filters, depth); err != nil {
+			return err
+		}
+		switch s.skipWhiteSpace() {
+		case ']':
+			s.cursor++
+			return nil
+		case ',':
+			s.cursor++
+		default:
+			return errors.ErrInvalidCharacter(s.char(), "slice", s.totalOffset())
+		}
+	}
+}
+
+func (ps *pathStreamer) walkChild(next, filters []int, depth int64) error {
+	if len(filters) == 0 {
+		return ps.walk(next, depth)
+	}
+	// buffer the child as the element of array, so that the filter selectors can select it.
+	s := ps.s
+	s.skipWhiteSpace()
+	start := s.cursor
+	if err := s.skipValue(depth); err != nil {
+		return err
+	}
+	value := s.buf[start:s.cursor]
+	buf := make([]byte, 0, len(value)+3)
+	buf = append(buf, '[')
+	buf = append(buf, value...)
+	buf = append(buf, ']', nul)
+	doc := &rfcRawDocument{buf: buf, keyDecoder: ps.keyDecoder}
+	root := &rawRFCNode{doc: doc, start: 0, end: int64(len(buf) - 1), depth: depth - 1}
+	if root.container() == nil {
+		return doc.err
+	}
+	ev := &rfcEvaluator{root: root}
+	rc := &rfcContainer{pathContainer: root.c, ev: ev, node: root}
+	for _, i := range filters {
+		for _, sel := range ps.segments[i].selectors {
+			switch sel.(type) {
+			case *pathNameSelector, *pathIndexSelector, *pathSliceSelector, *pathWildcardSelector:
+				continue
+			}
+			if len(sel.selectChildren(rc)) > 0 {
+				next = appendPathState(next, i+1)
+			}
+		}
+	}
+	if doc.err != nil {
+		return doc.err
+	}
+	return ps.emit(root.child(0).(*rawRFCNode), next)
+}
+
+// capture buffers the value and evaluates the rest of segments on it.
+func (ps *pathStreamer) capture(states []int, depth int64) error {
+	s := ps.s
+	s.skipWhiteSpace()
+	start := s.cursor
+	if err := s.skipValue(depth); err != nil {
+		return err
+	}
+	value := s.buf[start:s.cursor]
+	buf := make([]byte, len(value)+1) // append nul byte to the end
+	copy(buf, value)
+	doc := &rfcRawDocument{buf: buf, keyDecoder: ps.keyDecoder}
+	return ps.emit(&rawRFCNode{doc: doc, start: 0, end: int64(len(value)), depth: depth}, states)
+}
+
+// emit calls fn with the buffered node if it is selected, and with the descendants selected by the rest of segments.
+func (ps *pathStreamer) emit(node *rawRFCNode, states []int) error {
+	var nodes []*rawRFCNode
+	ev := &rfcEvaluator{root: node}
+	for _, i := range states {
+		if i == len(ps.segments) {
+			nodes = append(nodes, node)
+			continue
+		}
+		for _, m := range ev.evalSegments(ps.segments[i:], []rfcMatch{{node: node}}) {
+			nodes = append(nodes, m.node.(*rawRFCNode))
+		}
+	}
+	if node.doc.err != nil {
+		return node.doc.err
+	}
+	// the descendants selected by the different states may be the same.
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].start < nodes[j].start
+	})
+	for i, n := range nodes {
+		if i > 0 && nodes[i-1].start == n.start {
+			continue
+		}
+		if err := ps.fn(n.doc.buf[n.start:n.end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skip skips the value that has no selected descendants.
+// The members of object and the elements of array are skipped one by one,
+// so that the buffer doesn't grow while a large value is skipped.
+func (ps *pathStreamer) skip(depth int64) error {
+	s := ps.s
+	var end byte
+	switch s.skipWhiteSpace() {
+	case '{':
+		end = '}'
+	case '[':
+		end = ']'
+	default:
+		return s.skipValue(depth)
+	}
+	depth++
+	if depth > maxDecodeNestingDepth {
+		return errors.ErrExceededMaxDepth(s.char(), s.cursor)
+	}
+	s.cursor++
+	if s.skipWhiteSpace() == end {
+		s.cursor++
+		return nil
+	}
+	for {
+		s.discard()
+		if end == '}' {
+			if s.skipWhiteSpace() != '"' {
+				return errors.ErrExpected("object key", s.totalOffset())
+			}
+			if err := s.skipValue(depth); err != nil {
+				return err
+			}
+			if s.skipWhiteSpace() != ':' {
+				return errors.ErrExpected("colon after object key", s.totalOffset())
+			}
+			s.cursor++
+		}
+		if err := ps.skip(depth); err != nil {
+			return err
+		}
+		switch s.skipWhiteSpace() {
+		case end:
+			s.cursor++
+			return nil
+		case ',':
+			s.cursor++
+		default:
+			return errors.ErrExpected("comma or end of value", s.totalOffset())
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package decoder
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/errors"
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+var (
+	pointerTokenEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerTokenUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// EscapePointerToken escapes the reference token of JSON Pointer.
+func EscapePointerToken(token string) string {
+	if !strings.ContainsAny(token, "~/") {
+		return token
+	}
+	return pointerTokenEscaper.Replace(token)
+}
+
+// ParsePointer parses the JSON Pointer ( RFC 6901 ) and returns the unescaped reference tokens.
+func ParsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if p[0] != '/' {
+		return nil, errors.ErrInvalidPointer(p, "JSON Pointer must start with a / character")
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, token := range tokens {
+		for j := 0; j < len(token); j++ {
+			if token[j] != '~' {
+				continue
+			}
+			if j+1 >= len(token) || (token[j+1] != '0' && token[j+1] != '1') {
+				return nil, errors.ErrInvalidPointer(p, "~ must be followed by 0 or 1")
+			}
+		}
+		tokens[i] = pointerTokenUnescaper.Replace(token)
+	}
+	return tokens, nil
+}
+
+// NewPointerPath creates Path that selects the value referenced by the tokens of JSON Pointer.
+func NewPointerPath(tokens []string) *Path {
+	var (
+		root PathNode
+		node PathNode
+	)
+	for _, token := range tokens {
+		child := newPathPointerNode(token)
+		if root == nil {
+			root = child
+			node = child
+		} else {
+			node = node.chain(child)
+		}
+	}
+	return &Path{
+		node:             root,
+		RootSelectorOnly: root == nil,
+	}
+}
+
+// withPointerToken prepends the reference token of the child value to the JSON Pointer of the type error.
+// The pointer is built while the error is propagated, so it doesn't cost anything on success.
+func withPointerToken(err error, token string) error {
+	if e, ok := err.(*errors.UnmarshalTypeError); ok {
+		e.Pointer = "/" + EscapePointerToken(token) + e.Pointer
+	}
+	return err
+}
+
+func withPointerIndex(err error, idx int) error {
+	if e, ok := err.(*errors.UnmarshalTypeError); ok {
+		e.Pointer = "/" + strconv.Itoa(idx) + e.Pointer
+	}
+	return err
+}
+
+// mapKeyToken returns the reference token for the decoded map key.
+func mapKeyToken(typ *runtime.Type, k unsafe.Pointer) string {
+	return mapKeyValueToken(reflect.NewAt(runtime.RType2Type(typ), k).Elem())
+}
+
+func mapKeyValueToken(v reflect.Value) string {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// PathPointerNode is the reference token of JSON Pointer.
+// It selects the member of object or the element of array by the token.
+type PathPointerNode struct {
+	*BasePathNode
+	token string
+	index int // array index represented by token. -1 if token is not an array index
+}
+
+func newPathPointerNode(token string) *PathPointerNode {
+	return &PathPointerNode{
+		BasePathNode: &BasePathNode{},
+		token:        token,
+		index:        pointerIndex(token),
+	}
+}
+
+// pointerIndex returns the array index represented by token.
+// RFC 6901 doesn't allow leading zeros, so "01" is not an array index.
+func pointerIndex(token string) int {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return -1
+	}
+	for i := 0; i < len(token); i++ {
+		if token[i] < '0' || token[i] > '9' {
+			return -1
+		}
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil {
+		return -1
+	}
+	return idx
+}
+
+func (n *PathPointerNode) Index(idx int) (PathNode, bool, error) {
+	if n.index >= 0 && n.index == idx {
+		return n.child, true, nil
+	}
+	return nil, false, nil
+}
+
+func (n *PathPointerNode) Field(fieldName string) (PathNode, bool, error) {
+	if n.token == fieldName {
+		return n.child, true, nil
+	}
+	return nil, false, nil
+}
+
+func (n *PathPointerNode) Get(src, dst reflect.Value) error {
+	switch src.Type().Kind() {
+	case reflect.Map:
+		iter := src.MapRange()
+		for iter.Next() {
+			if mapKeyValueToken(iter.Key()) == n.token {
+				return n.getChild(iter.Value(), dst)
+			}
+		}
+	case reflect.Struct:
+		typ := src.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if runtime.IsIgnoredStructField(field) {
+				continue
+			}
+			if runtime.StructTagFromField(field).Key == n.token {
+				return n.getChild(src.Field(i), dst)
+			}
+		}
+	case reflect.Array, reflect.Slice:
+		if n.index >= 0 && src.Len() > n.index {
+			return n.getChild(src.Index(n.index), dst)
+		}
+	case reflect.Ptr:
+		if !src.IsNil() {
+			return n.Get(src.Elem(), dst)
+		}
+	case reflect.Interface:
+		if !src.IsNil() {
+			return n.Get(reflect.ValueOf(src.Interface()), dst)
+		}
+	}
+	return fmt.Errorf("failed to get /%s value from %s", EscapePointerToken(n.token), src.Type())
+}
+
+func (n *PathPointerNode) getChild(src, dst reflect.Value) error {
+	if n.child != nil {
+		return n.child.Get(src, dst)
+	}
+	return AssignValue(src, dst)
+}
+
+func (n *PathPointerNode) String() string {
+	s := "/" + EscapePointerToken(n.token)
+	if n.child != nil {
+		s += n.child.String()
+	}
+	return s
+}
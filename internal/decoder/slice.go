@@ -154,7 +154,7 @@ func (d *sliceDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) er
 				}
 
 				if err := d.valueDecoder.DecodeStream(s, depth, ep); err != nil {
-					return err
+					return withPointerIndex(err, idx)
 				}
 				s.skipWhiteSpace()
 			RETRY:
@@ -261,9 +261,17 @@ func (d *sliceDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsafe
 						typedmemmove(d.elemType, ep, unsafe_New(d.elemType))
 					}
 				}
+				mark := ctx.Option.errorMark()
 				c, err := d.valueDecoder.Decode(ctx, cursor, depth, ep)
+				ctx.Option.addPointerIndex(mark, idx)
 				if err != nil {
-					return 0, err
+					err = withPointerIndex(err, idx)
+					if !ctx.Option.collectError(err) {
+						return 0, err
+					}
+					if c, err = skipValue(buf, cursor, depth); err != nil {
+						return 0, err
+					}
 				}
 				cursor = c
 				cursor = skipWhiteSpace(buf, cursor)
@@ -326,6 +334,17 @@ func (d *sliceDecoder) DecodePath(ctx *RuntimeContext, cursor, depth int64) ([][
 				cursor++
 				return ret, cursor, nil
 			}
+			if node, ok := ctx.Option.Path.node.(*PathUnionNode); ok {
+				c, end, err := scanRawPathArray(buf, cursor, depth)
+				if err != nil {
+					return nil, 0, err
+				}
+				paths, err := decodePathUnion(ctx, node, c, d.valueDecoder, depth)
+				if err != nil {
+					return nil, 0, err
+				}
+				return paths, end, nil
+			}
 			idx := 0
 			for {
 				child, found, err := ctx.Option.Path.node.Index(idx)
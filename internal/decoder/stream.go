@@ -3,6 +3,7 @@ package decoder
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"strconv"
 	"unsafe"
@@ -26,14 +27,24 @@ type Stream struct {
 	UseNumber             bool
 	DisallowUnknownFields bool
 	Option                *Option
+
+	// base is the buffer that buf is sliced from, and is used to locate errors.
+	// The lines of the bytes before base are counted when the buffer is replaced, so that they are not kept.
+	base          []byte
+	baseOffset    int64 // total offset of base[0]
+	baseLine      int   // 1-based line number of base[0]
+	baseLineStart int64 // total offset of the beginning of the line that contains base[0]
 }
 
 func NewStream(r io.Reader) *Stream {
+	buf := make([]byte, initBufSize)
 	return &Stream{
-		r:       r,
-		bufSize: initBufSize,
-		buf:     make([]byte, initBufSize),
-		Option:  &Option{},
+		r:        r,
+		bufSize:  initBufSize,
+		buf:      buf,
+		Option:   &Option{},
+		base:     buf,
+		baseLine: 1,
 	}
 }
 
@@ -106,6 +117,81 @@ func (s *Stream) Reset() {
 	s.bufSize = int64(len(s.buf))
 }
 
+// ValidateEnd returns an error if the rest of the stream contains characters other than white spaces.
+func (s *Stream) ValidateEnd() error {
+	c := s.skipWhiteSpace()
+	if c == nul {
+		return nil
+	}
+	err := errors.ErrSyntax(
+		fmt.Sprintf("invalid character '%c' after top-level value", c),
+		s.totalOffset()+1,
+	)
+	return s.source().LocateAt(err, s.totalOffset())
+}
+
+// ResetReader makes the stream read the new input from r.
+// line is the 1-based line number of the beginning of the input used to locate errors.
+// The decoded values may refer to the buffer, so the new input is read into the unused rest of the buffer
+// instead of overwriting it, and the new buffer is allocated only if the rest is too small.
+func (s *Stream) ResetReader(r io.Reader, line int) {
+	s.reset()
+	buf := s.buf[s.length:]
+	if len(buf) < initBufSize {
+		buf = make([]byte, initBufSize)
+	}
+	s.r = r
+	s.buf = buf
+	s.bufSize = int64(len(buf))
+	s.length = 0
+	s.offset = 0
+	s.filledBuffer = false
+	s.allRead = false
+	s.base = buf
+	s.baseOffset = 0
+	s.baseLine = line
+	s.baseLineStart = 0
+}
+
+// SetLine sets the 1-based line number of the beginning of the stream used to locate errors.
+func (s *Stream) SetLine(line int) {
+	s.baseLine = line
+}
+
+// LocateError sets the line and column to err if err is *errors.SyntaxError or *errors.UnmarshalTypeError.
+func (s *Stream) LocateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return s.source().Locate(err)
+}
+
+func (s *Stream) source() *errors.Source {
+	return &errors.Source{
+		Buf:       s.base[:s.offset-s.baseOffset+s.length],
+		Offset:    s.baseOffset,
+		Line:      s.baseLine,
+		LineStart: s.baseLineStart,
+	}
+}
+
+// countLines advances the line number of base[0] by the lines of b at the total offset.
+func (s *Stream) countLines(b []byte, offset int64) {
+	if n := bytes.Count(b, []byte{'\n'}); n > 0 {
+		s.baseLine += n
+		s.baseLineStart = offset + int64(bytes.LastIndexByte(b, '\n')) + 1
+	}
+}
+
+// replaceBuf replaces the buffer with the newly allocated buf.
+// The lines of the bytes discarded from the current buffer are counted here, so the bytes are not kept.
+func (s *Stream) replaceBuf(buf []byte) {
+	s.countLines(s.base[:s.offset-s.baseOffset], s.baseOffset)
+	s.buf = buf
+	s.base = buf
+	s.baseOffset = s.offset
+}
+
 func (s *Stream) More() bool {
 	for {
 		switch s.char() {
@@ -139,7 +225,7 @@ func (s *Stream) Token() (interface{}, error) {
 		case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
 			bytes := floatBytes(s)
 			str := *(*string)(unsafe.Pointer(&bytes))
-			if s.UseNumber {
+			if s.UseNumber || (s.Option.Flags&UseNumberOption) != 0 {
 				return json.Number(str), nil
 			}
 			f64, err := strconv.ParseFloat(str, 64)
@@ -188,11 +274,34 @@ func (s *Stream) reset() {
 	s.cursor = 0
 }
 
+// discard discards the bytes before the cursor, and moves the unread bytes to the beginning of the buffer.
+// It is called while a large value is scanned by parts, so that the buffer is reused instead of growing.
+// To amortize the cost of moving, the bytes are discarded only when more than half of the buffer is scanned.
+func (s *Stream) discard() {
+	if s.cursor <= int64(len(s.buf))/2 {
+		return
+	}
+	s.reset()
+	remain := s.buf[:s.length]
+	buf := s.base
+	// the discarded bytes are overwritten by reusing the buffer, so the lines must be counted here.
+	s.countLines(buf[:s.offset-s.baseOffset], s.baseOffset)
+	s.buf = buf
+	s.baseOffset = s.offset
+	copy(buf, remain)
+	// the stream expects that the bytes after the read bytes are nul.
+	tail := buf[s.length:]
+	for i := range tail {
+		tail[i] = nul
+	}
+	s.filledBuffer = false
+}
+
 func (s *Stream) readBuf() []byte {
 	if s.filledBuffer {
 		s.bufSize *= 2
 		remainBuf := s.buf
-		s.buf = make([]byte, s.bufSize)
+		s.replaceBuf(make([]byte, s.bufSize))
 		copy(s.buf, remainBuf)
 	}
 	remainLen := s.length - s.cursor
@@ -2,7 +2,9 @@ package decoder
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
+	"math/bits"
 	"reflect"
 	"unicode"
 	"unicode/utf16"
@@ -199,6 +201,60 @@ var (
 	runeErrBytesLen = int64(len(runeErrBytes))
 )
 
+const (
+	lsb = 0x0101010101010101
+	msb = 0x8080808080808080
+)
+
+// hasLess sets the MSB of each byte of n that is less than c ( c must be 0x80 or less ).
+// The borrow may set the MSB of the byte above the matched byte, but never the MSB of the lowest matched byte,
+// so the lowest set bit always points to the first matched byte.
+func hasLess(n uint64, c byte) uint64 {
+	return (n - lsb*uint64(c)) &^ n & msb
+}
+
+// hasByte sets the MSB of each byte of n that is equal to c.
+func hasByte(n uint64, c byte) uint64 {
+	return hasLess(n^(lsb*uint64(c)), 1)
+}
+
+// specialCharMask sets the MSB of each byte of n that is '"', '\\' or the control character including nul.
+func specialCharMask(n uint64) uint64 {
+	return hasLess(n, 0x20) | hasByte(n, '"') | hasByte(n, '\\')
+}
+
+// indexSpecialChar returns the index of the first '"', '\\' or control character in buf[cursor:] by checking 8 bytes at a time.
+// buf must be terminated by nul character, so it always finds the character.
+func indexSpecialChar(buf []byte, cursor int64) int64 {
+	for ; cursor+8 <= int64(len(buf)); cursor += 8 {
+		if mask := specialCharMask(binary.LittleEndian.Uint64(buf[cursor:])); mask != 0 {
+			return cursor + int64(bits.TrailingZeros64(mask)/8)
+		}
+	}
+	for ; cursor < int64(len(buf)); cursor++ {
+		if c := buf[cursor]; c < 0x20 || c == '"' || c == '\\' {
+			return cursor
+		}
+	}
+	return cursor
+}
+
+// indexNonASCIIOrSpecialChar is the same as indexSpecialChar, but it also finds the bytes outside the ASCII range.
+func indexNonASCIIOrSpecialChar(buf []byte, cursor int64) int64 {
+	for ; cursor+8 <= int64(len(buf)); cursor += 8 {
+		n := binary.LittleEndian.Uint64(buf[cursor:])
+		if mask := specialCharMask(n) | n&msb; mask != 0 {
+			return cursor + int64(bits.TrailingZeros64(mask)/8)
+		}
+	}
+	for ; cursor < int64(len(buf)); cursor++ {
+		if c := buf[cursor]; c < 0x20 || c >= 0x80 || c == '"' || c == '\\' {
+			return cursor
+		}
+	}
+	return cursor
+}
+
 func stringBytes(s *Stream) ([]byte, error) {
 	_, cursor, p := s.stat()
 	cursor++ // skip double quote char
@@ -228,7 +284,9 @@ func stringBytes(s *Stream) ([]byte, error) {
 			0x50, 0x51, 0x52, 0x53, 0x54, 0x55, 0x56, 0x57, 0x58, 0x59, 0x5A, 0x5B /*0x5C,*/, 0x5D, 0x5E, 0x5F, // 0x50-0x5F
 			0x60, 0x61, 0x62, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69, 0x6A, 0x6B, 0x6C, 0x6D, 0x6E, 0x6F, // 0x60-0x6F
 			0x70, 0x71, 0x72, 0x73, 0x74, 0x75, 0x76, 0x77, 0x78, 0x79, 0x7A, 0x7B, 0x7C, 0x7D, 0x7E, 0x7F: // 0x70-0x7F
-			// character is ASCII. skip to next char
+			// character is ASCII. skip to next non ASCII or special char
+			cursor = indexNonASCIIOrSpecialChar(s.buf, cursor+1)
+			continue
 		case
 			0x80, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87, 0x88, 0x89, 0x8A, 0x8B, 0x8C, 0x8D, 0x8E, 0x8F, // 0x80-0x8F
 			0x90, 0x91, 0x92, 0x93, 0x94, 0x95, 0x96, 0x97, 0x98, 0x99, 0x9A, 0x9B, 0x9C, 0x9D, 0x9E, 0x9F, // 0x90-0x9F
@@ -237,7 +295,7 @@ func stringBytes(s *Stream) ([]byte, error) {
 			0xC0, 0xC1, // 0xC0-0xC1
 			0xF5, 0xF6, 0xF7, 0xF8, 0xF9, 0xFA, 0xFB, 0xFC, 0xFD, 0xFE, 0xFF: // 0xF5-0xFE
 			// character is invalid
-			s.buf = append(append(append([]byte{}, s.buf[:cursor]...), runeErrBytes...), s.buf[cursor+1:]...)
+			s.replaceBuf(append(append(append([]byte{}, s.buf[:cursor]...), runeErrBytes...), s.buf[cursor+1:]...))
 			_, _, p = s.stat()
 			cursor += runeErrBytesLen
 			s.length += runeErrBytesLen
@@ -259,7 +317,7 @@ func stringBytes(s *Stream) ([]byte, error) {
 			fallthrough
 		default:
 			// multi bytes character
-			if !utf8.FullRune(s.buf[cursor : len(s.buf)-1]) {
+			if !utf8.FullRune(s.buf[cursor:s.length]) {
 				s.cursor = cursor
 				if s.read() {
 					_, cursor, p = s.stat()
@@ -269,7 +327,7 @@ func stringBytes(s *Stream) ([]byte, error) {
 			}
 			r, size := utf8.DecodeRune(s.buf[cursor:])
 			if r == utf8.RuneError {
-				s.buf = append(append(append([]byte{}, s.buf[:cursor]...), runeErrBytes...), s.buf[cursor+1:]...)
+				s.replaceBuf(append(append(append([]byte{}, s.buf[:cursor]...), runeErrBytes...), s.buf[cursor+1:]...))
 				cursor += runeErrBytesLen
 				s.length += runeErrBytesLen
 				_, _, p = s.stat()
@@ -330,6 +388,7 @@ func (d *stringDecoder) decodeByte(buf []byte, cursor int64) ([]byte, int64, err
 			b := (*sliceHeader)(unsafe.Pointer(&buf)).data
 			escaped := 0
 			for {
+				cursor = indexSpecialChar(buf, cursor)
 				switch char(b, cursor) {
 				case '\\':
 					escaped++
@@ -1,6 +1,7 @@
 package decoder
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"math/bits"
@@ -14,13 +15,15 @@ import (
 )
 
 type structFieldSet struct {
-	dec         Decoder
-	offset      uintptr
-	isTaggedKey bool
-	fieldIdx    int
-	key         string
-	keyLen      int64
-	err         error
+	dec          Decoder
+	offset       uintptr
+	isTaggedKey  bool
+	isStrictCase bool
+	isFoldedKey  bool // the key is the lower case key of the other key for case-insensitive matching
+	fieldIdx     int
+	key          string
+	keyLen       int64
+	err          error
 }
 
 type structDecoder struct {
@@ -35,6 +38,13 @@ type structDecoder struct {
 	sortedFieldSets    []*structFieldSet
 	keyDecoder         func(*structDecoder, []byte, int64) (int64, *structFieldSet, error)
 	keyStreamDecoder   func(*structDecoder, *Stream) (*structFieldSet, string, error)
+
+	// caseSensitiveDecoder has the lookup tables of the exact keys for CaseSensitiveOption.
+	// It shares the field sets, and is used only to decode the keys.
+	caseSensitiveDecoder *structDecoder
+
+	// unknownFields keeps the members that don't match any field if the struct has the field with the unknown option.
+	unknownFields *unknownFieldsDecoder
 }
 
 var (
@@ -76,28 +86,108 @@ const (
 )
 
 func (d *structDecoder) tryOptimize() {
-	fieldUniqueNameMap := map[string]int{}
-	fieldIdx := -1
+	d.assignFieldIdx()
+
+	exactFieldMap := map[string]*structFieldSet{}
 	for k, v := range d.fieldMap {
-		lower := strings.ToLower(k)
-		idx, exists := fieldUniqueNameMap[lower]
-		if exists {
-			v.fieldIdx = idx
-		} else {
-			fieldIdx++
-			v.fieldIdx = fieldIdx
+		if k == v.key && !v.isFoldedKey {
+			exactFieldMap[k] = v
 		}
-		fieldUniqueNameMap[lower] = fieldIdx
 	}
-	d.fieldUniqueNameNum = len(fieldUniqueNameMap)
+	d.caseSensitiveDecoder = newStructDecoder(d.structName, d.fieldName, exactFieldMap)
+	d.caseSensitiveDecoder.optimizeKeyDecoder(true)
 
 	if d.isTriedOptimize {
 		return
 	}
+	d.optimizeKeyDecoder(false)
+}
+
+// assignFieldIdx gives the same index to the keys of the same field, and the different index to the different fields
+// even if their names differ only in case. The indexes are used to track the decoded fields.
+func (d *structDecoder) assignFieldIdx() {
+	keys := make([]string, 0, len(d.fieldMap))
+	for k := range d.fieldMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type foldedKey struct {
+		lower  string
+		offset uintptr
+	}
+	fieldIdxMap := map[string]int{}
+	foldedIdxMap := map[foldedKey]int{}
+	for _, k := range keys {
+		v := d.fieldMap[k]
+		if v.isFoldedKey {
+			continue
+		}
+		idx, exists := fieldIdxMap[v.key]
+		if !exists {
+			idx = len(fieldIdxMap)
+			fieldIdxMap[v.key] = idx
+		}
+		v.fieldIdx = idx
+		foldedIdxMap[foldedKey{lower: strings.ToLower(v.key), offset: v.offset}] = idx
+	}
+	fieldNum := len(fieldIdxMap)
+	for _, k := range keys {
+		v := d.fieldMap[k]
+		if !v.isFoldedKey {
+			continue
+		}
+		// the lower case key copied from the embedded struct refers to the field at the same offset.
+		key := foldedKey{lower: k, offset: v.offset}
+		idx, exists := foldedIdxMap[key]
+		if !exists {
+			idx = fieldNum
+			fieldNum++
+			foldedIdxMap[key] = idx
+		}
+		v.fieldIdx = idx
+	}
+	d.fieldUniqueNameNum = fieldNum
+}
+
+// fieldBitset is the set of the indexes of the decoded fields.
+// It doesn't allocate if the struct has 64 fields or less.
+type fieldBitset struct {
+	small uint64
+	large []uint64
+}
+
+func newFieldBitset(fieldNum int) fieldBitset {
+	if fieldNum <= 64 {
+		return fieldBitset{}
+	}
+	return fieldBitset{large: make([]uint64, (fieldNum+63)/64)}
+}
+
+// testAndSet adds idx to the set and reports whether idx has been already added.
+func (b *fieldBitset) testAndSet(idx int) bool {
+	word := &b.small
+	if b.large != nil {
+		word = &b.large[idx/64]
+	}
+	mask := uint64(1) << uint(idx%64)
+	exists := *word&mask != 0
+	*word |= mask
+	return exists
+}
+
+// optimizeKeyDecoder builds the bitmaps of the keys to decode the key without allocation.
+// The bitmaps are indexed by the raw chars of the key.
+// If caseSensitive is false, the chars of the keys are set for both the lower case and the upper case
+// except the keys of the fields that have the strictcase option.
+func (d *structDecoder) optimizeKeyDecoder(caseSensitive bool) {
 	fieldMap := map[string]*structFieldSet{}
 	conflicted := map[string]struct{}{}
 	for k, v := range d.fieldMap {
-		key := strings.ToLower(k)
+		key := k
+		if !caseSensitive {
+			key = strings.ToLower(k)
+		}
 		if key != k {
 			if key != toASCIILower(k) {
 				d.isTriedOptimize = true
@@ -145,11 +235,16 @@ func (d *structDecoder) tryOptimize() {
 	if len(sortedKeys) <= 8 {
 		keyBitmap := make([][256]uint8, bitmapLen)
 		for i, key := range sortedKeys {
+			field := fieldMap[key]
+			key, fold := bitmapKey(key, field, caseSensitive)
 			for j := 0; j < len(key); j++ {
 				c := key[j]
 				keyBitmap[j][c] |= (1 << uint(i))
+				if fold && 'a' <= c && c <= 'z' {
+					keyBitmap[j][c-'a'+'A'] |= (1 << uint(i))
+				}
 			}
-			d.sortedFieldSets = append(d.sortedFieldSets, fieldMap[key])
+			d.sortedFieldSets = append(d.sortedFieldSets, field)
 		}
 		d.keyBitmapUint8 = keyBitmap
 		d.keyDecoder = decodeKeyByBitmapUint8
@@ -157,11 +252,16 @@ func (d *structDecoder) tryOptimize() {
 	} else {
 		keyBitmap := make([][256]uint16, bitmapLen)
 		for i, key := range sortedKeys {
+			field := fieldMap[key]
+			key, fold := bitmapKey(key, field, caseSensitive)
 			for j := 0; j < len(key); j++ {
 				c := key[j]
 				keyBitmap[j][c] |= (1 << uint(i))
+				if fold && 'a' <= c && c <= 'z' {
+					keyBitmap[j][c-'a'+'A'] |= (1 << uint(i))
+				}
 			}
-			d.sortedFieldSets = append(d.sortedFieldSets, fieldMap[key])
+			d.sortedFieldSets = append(d.sortedFieldSets, field)
 		}
 		d.keyBitmapUint16 = keyBitmap
 		d.keyDecoder = decodeKeyByBitmapUint16
@@ -169,6 +269,15 @@ func (d *structDecoder) tryOptimize() {
 	}
 }
 
+// bitmapKey returns the key that is set to the bitmap, and whether the upper case chars are also set.
+// The key of the field that has the strictcase option is set as is.
+func bitmapKey(lowerKey string, field *structFieldSet, caseSensitive bool) (string, bool) {
+	if caseSensitive || field.isStrictCase {
+		return field.key, false
+	}
+	return lowerKey, true
+}
+
 // decode from '\uXXXX'
 func decodeKeyCharByUnicodeRune(buf []byte, cursor int64) ([]byte, int64, error) {
 	const defaultOffset = 4
@@ -263,7 +372,7 @@ func decodeKeyByBitmapUint8(d *structDecoder, buf []byte, cursor int64) (int64,
 						return 0, nil, err
 					}
 					for _, c := range chars {
-						curBit &= bitmap[keyIdx][largeToSmallTable[c]]
+						curBit &= bitmap[keyIdx][c]
 						if curBit == 0 {
 							return decodeKeyNotFound(b, cursor)
 						}
@@ -271,7 +380,7 @@ func decodeKeyByBitmapUint8(d *structDecoder, buf []byte, cursor int64) (int64,
 					}
 					cursor = nextCursor
 				default:
-					curBit &= bitmap[keyIdx][largeToSmallTable[c]]
+					curBit &= bitmap[keyIdx][c]
 					if curBit == 0 {
 						return decodeKeyNotFound(b, cursor)
 					}
@@ -329,7 +438,7 @@ func decodeKeyByBitmapUint16(d *structDecoder, buf []byte, cursor int64) (int64,
 						return 0, nil, err
 					}
 					for _, c := range chars {
-						curBit &= bitmap[keyIdx][largeToSmallTable[c]]
+						curBit &= bitmap[keyIdx][c]
 						if curBit == 0 {
 							return decodeKeyNotFound(b, cursor)
 						}
@@ -337,7 +446,7 @@ func decodeKeyByBitmapUint16(d *structDecoder, buf []byte, cursor int64) (int64,
 					}
 					cursor = nextCursor
 				default:
-					curBit &= bitmap[keyIdx][largeToSmallTable[c]]
+					curBit &= bitmap[keyIdx][c]
 					if curBit == 0 {
 						return decodeKeyNotFound(b, cursor)
 					}
@@ -370,7 +479,7 @@ func decodeKeyNotFound(b unsafe.Pointer, cursor int64) (int64, *structFieldSet,
 }
 
 func decodeKey(d *structDecoder, buf []byte, cursor int64) (int64, *structFieldSet, error) {
-	key, c, err := d.stringDecoder.decodeByte(buf, cursor)
+	key, c, err := d.decodeKeyByte(buf, cursor)
 	if err != nil {
 		return 0, nil, err
 	}
@@ -418,6 +527,7 @@ func decodeKeyByBitmapUint8Stream(d *structDecoder, s *Stream) (*structFieldSet,
 			}
 			keyIdx := 0
 			bitmap := d.keyBitmapUint8
+			escaped := false
 			for {
 				c := char(p, cursor)
 				switch c {
@@ -429,9 +539,13 @@ func decodeKeyByBitmapUint8Stream(d *structDecoder, s *Stream) (*structFieldSet,
 					s.cursor = cursor
 					if keyLen < field.keyLen {
 						// early match
-						return nil, field.key, nil
+						return decodeKeyNotFoundStream(d, s, start)
+					}
+					if escaped {
+						return decodeKeyFoundStream(d, s, start, field)
 					}
-					return field, field.key, nil
+					key := s.buf[start : cursor-1]
+					return field, *(*string)(unsafe.Pointer(&key)), nil
 				case nul:
 					s.cursor = cursor
 					if s.read() {
@@ -440,6 +554,7 @@ func decodeKeyByBitmapUint8Stream(d *structDecoder, s *Stream) (*structFieldSet,
 					}
 					return nil, "", errors.ErrUnexpectedEndOfJSON("string", s.totalOffset())
 				case '\\':
+					escaped = true
 					s.cursor = cursor + 1 // skip '\' char
 					chars, err := decodeKeyCharByEscapeCharStream(s)
 					if err != nil {
@@ -447,18 +562,18 @@ func decodeKeyByBitmapUint8Stream(d *structDecoder, s *Stream) (*structFieldSet,
 					}
 					cursor = s.cursor
 					for _, c := range chars {
-						curBit &= bitmap[keyIdx][largeToSmallTable[c]]
+						curBit &= bitmap[keyIdx][c]
 						if curBit == 0 {
 							s.cursor = cursor
-							return decodeKeyNotFoundStream(s, start)
+							return decodeKeyNotFoundStream(d, s, start)
 						}
 						keyIdx++
 					}
 				default:
-					curBit &= bitmap[keyIdx][largeToSmallTable[c]]
+					curBit &= bitmap[keyIdx][c]
 					if curBit == 0 {
 						s.cursor = cursor
-						return decodeKeyNotFoundStream(s, start)
+						return decodeKeyNotFoundStream(d, s, start)
 					}
 					keyIdx++
 				}
@@ -505,6 +620,7 @@ func decodeKeyByBitmapUint16Stream(d *structDecoder, s *Stream) (*structFieldSet
 			}
 			keyIdx := 0
 			bitmap := d.keyBitmapUint16
+			escaped := false
 			for {
 				c := char(p, cursor)
 				switch c {
@@ -516,9 +632,13 @@ func decodeKeyByBitmapUint16Stream(d *structDecoder, s *Stream) (*structFieldSet
 					s.cursor = cursor
 					if keyLen < field.keyLen {
 						// early match
-						return nil, field.key, nil
+						return decodeKeyNotFoundStream(d, s, start)
+					}
+					if escaped {
+						return decodeKeyFoundStream(d, s, start, field)
 					}
-					return field, field.key, nil
+					key := s.buf[start : cursor-1]
+					return field, *(*string)(unsafe.Pointer(&key)), nil
 				case nul:
 					s.cursor = cursor
 					if s.read() {
@@ -527,6 +647,7 @@ func decodeKeyByBitmapUint16Stream(d *structDecoder, s *Stream) (*structFieldSet
 					}
 					return nil, "", errors.ErrUnexpectedEndOfJSON("string", s.totalOffset())
 				case '\\':
+					escaped = true
 					s.cursor = cursor + 1 // skip '\' char
 					chars, err := decodeKeyCharByEscapeCharStream(s)
 					if err != nil {
@@ -534,18 +655,18 @@ func decodeKeyByBitmapUint16Stream(d *structDecoder, s *Stream) (*structFieldSet
 					}
 					cursor = s.cursor
 					for _, c := range chars {
-						curBit &= bitmap[keyIdx][largeToSmallTable[c]]
+						curBit &= bitmap[keyIdx][c]
 						if curBit == 0 {
 							s.cursor = cursor
-							return decodeKeyNotFoundStream(s, start)
+							return decodeKeyNotFoundStream(d, s, start)
 						}
 						keyIdx++
 					}
 				default:
-					curBit &= bitmap[keyIdx][largeToSmallTable[c]]
+					curBit &= bitmap[keyIdx][c]
 					if curBit == 0 {
 						s.cursor = cursor
-						return decodeKeyNotFoundStream(s, start)
+						return decodeKeyNotFoundStream(d, s, start)
 					}
 					keyIdx++
 				}
@@ -621,34 +742,24 @@ RETRY:
 	}
 }
 
-func decodeKeyNotFoundStream(s *Stream, start int64) (*structFieldSet, string, error) {
-	buf, cursor, p := s.stat()
-	for {
-		cursor++
-		switch char(p, cursor) {
-		case '"':
-			b := buf[start:cursor]
-			key := *(*string)(unsafe.Pointer(&b))
-			cursor++
-			s.cursor = cursor
-			return nil, key, nil
-		case '\\':
-			cursor++
-			if char(p, cursor) == nul {
-				s.cursor = cursor
-				if !s.read() {
-					return nil, "", errors.ErrUnexpectedEndOfJSON("string", s.totalOffset())
-				}
-				buf, cursor, p = s.statForRetry()
-			}
-		case nul:
-			s.cursor = cursor
-			if !s.read() {
-				return nil, "", errors.ErrUnexpectedEndOfJSON("string", s.totalOffset())
-			}
-			buf, cursor, p = s.statForRetry()
-		}
+// decodeKeyNotFoundStream decodes the key that doesn't match any field again from start that is the position after the opening quote.
+// The bitmap decoders don't modify the buffer, so the key can be decoded with unescaping.
+func decodeKeyNotFoundStream(d *structDecoder, s *Stream, start int64) (*structFieldSet, string, error) {
+	s.cursor = start - 1
+	key, err := d.stringDecoder.decodeStreamByte(s)
+	if err != nil {
+		return nil, "", err
+	}
+	return nil, *(*string)(unsafe.Pointer(&key)), nil
+}
+
+// decodeKeyFoundStream decodes the escaped key of the matched field again to return the key in the document.
+func decodeKeyFoundStream(d *structDecoder, s *Stream, start int64, field *structFieldSet) (*structFieldSet, string, error) {
+	_, key, err := decodeKeyNotFoundStream(d, s, start)
+	if err != nil {
+		return nil, "", err
 	}
+	return field, key, nil
 }
 
 func decodeKeyStream(d *structDecoder, s *Stream) (*structFieldSet, string, error) {
@@ -660,6 +771,14 @@ func decodeKeyStream(d *structDecoder, s *Stream) (*structFieldSet, string, erro
 	return d.fieldMap[k], k, nil
 }
 
+// keyDecoderByOption returns the decoder that has the lookup tables of the keys for the option.
+func (d *structDecoder) keyDecoderByOption(opt *Option) *structDecoder {
+	if opt.Flags&CaseSensitiveOption != 0 && d.caseSensitiveDecoder != nil {
+		return d.caseSensitiveDecoder
+	}
+	return d
+}
+
 func (d *structDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) error {
 	depth++
 	if depth > maxDecodeNestingDepth {
@@ -684,16 +803,24 @@ func (d *structDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) e
 		return nil
 	}
 	var (
-		seenFields   map[int]struct{}
+		seenFields   fieldBitset
 		seenFieldNum int
 	)
 	firstWin := (s.Option.Flags & FirstWinOption) != 0
-	if firstWin {
-		seenFields = make(map[int]struct{}, d.fieldUniqueNameNum)
+	disallowDuplicateKeys := (s.Option.Flags & DisallowDuplicateKeysOption) != 0
+	trackFields := firstWin || disallowDuplicateKeys
+	if trackFields {
+		seenFields = newFieldBitset(d.fieldUniqueNameNum)
 	}
+	keyDecoder := d.keyDecoderByOption(s.Option)
 	for {
 		s.reset()
-		field, key, err := d.keyStreamDecoder(d, s)
+		var keyOffset int64
+		if disallowDuplicateKeys {
+			s.skipWhiteSpace()
+			keyOffset = s.totalOffset()
+		}
+		field, key, err := keyDecoder.keyStreamDecoder(keyDecoder, s)
 		if err != nil {
 			return err
 		}
@@ -705,27 +832,34 @@ func (d *structDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) e
 			if field.err != nil {
 				return field.err
 			}
-			if firstWin {
-				if _, exists := seenFields[field.fieldIdx]; exists {
+			if trackFields {
+				if seenFields.testAndSet(field.fieldIdx) {
+					if disallowDuplicateKeys {
+						return errors.ErrDuplicateKey(field.key, keyOffset)
+					}
 					if err := s.skipValue(depth); err != nil {
 						return err
 					}
 				} else {
 					if err := field.dec.DecodeStream(s, depth, unsafe.Pointer(uintptr(p)+field.offset)); err != nil {
-						return err
+						return withPointerToken(err, copyString(key))
 					}
 					seenFieldNum++
-					if d.fieldUniqueNameNum <= seenFieldNum {
+					if !disallowDuplicateKeys && d.fieldUniqueNameNum <= seenFieldNum {
 						return s.skipObject(depth)
 					}
-					seenFields[field.fieldIdx] = struct{}{}
 				}
 			} else {
 				if err := field.dec.DecodeStream(s, depth, unsafe.Pointer(uintptr(p)+field.offset)); err != nil {
-					return err
+					return withPointerToken(err, copyString(key))
 				}
 			}
-		} else if s.DisallowUnknownFields {
+		} else if d.unknownFields != nil {
+			// the key refers to the buffer of the stream, so it is copied before the value is read.
+			if err := d.unknownFields.DecodeStream(s, depth, p, copyString(key)); err != nil {
+				return err
+			}
+		} else if s.DisallowUnknownFields || (s.Option.Flags&DisallowUnknownFieldsOption) != 0 {
 			return fmt.Errorf("json: unknown field %q", key)
 		} else {
 			if err := s.skipValue(depth); err != nil {
@@ -771,18 +905,34 @@ func (d *structDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsaf
 		return cursor, nil
 	}
 	var (
-		seenFields   map[int]struct{}
+		seenFields   fieldBitset
 		seenFieldNum int
 	)
 	firstWin := (ctx.Option.Flags & FirstWinOption) != 0
-	if firstWin {
-		seenFields = make(map[int]struct{}, d.fieldUniqueNameNum)
+	disallowDuplicateKeys := (ctx.Option.Flags & DisallowDuplicateKeysOption) != 0
+	trackFields := firstWin || disallowDuplicateKeys
+	if trackFields {
+		seenFields = newFieldBitset(d.fieldUniqueNameNum)
 	}
+	disallowUnknownFields := (ctx.Option.Flags & DisallowUnknownFieldsOption) != 0
+	keyDecoder := d.keyDecoderByOption(ctx.Option)
 	for {
-		c, field, err := d.keyDecoder(d, buf, cursor)
+		keyCursor := cursor
+		c, field, err := keyDecoder.keyDecoder(keyDecoder, buf, cursor)
 		if err != nil {
 			return 0, err
 		}
+		keyEnd := c
+		var unknownKey string
+		if field == nil && d.unknownFields != nil {
+			key, err := d.documentKey(buf, keyCursor, c)
+			if err != nil {
+				return 0, err
+			}
+			unknownKey = string(key)
+		} else if field == nil && disallowUnknownFields {
+			return 0, d.errUnknownField(buf, keyCursor, c)
+		}
 		cursor = skipWhiteSpace(buf, c)
 		if char(b, cursor) != ':' {
 			return 0, errors.ErrExpected("colon after object key", cursor)
@@ -795,32 +945,60 @@ func (d *structDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsaf
 			if field.err != nil {
 				return 0, field.err
 			}
-			if firstWin {
-				if _, exists := seenFields[field.fieldIdx]; exists {
+			if trackFields {
+				if seenFields.testAndSet(field.fieldIdx) {
+					if disallowDuplicateKeys {
+						return 0, errors.ErrDuplicateKey(field.key, skipWhiteSpace(buf, keyCursor))
+					}
 					c, err := skipValue(buf, cursor, depth)
 					if err != nil {
 						return 0, err
 					}
 					cursor = c
 				} else {
+					mark := ctx.Option.errorMark()
 					c, err := field.dec.Decode(ctx, cursor, depth, unsafe.Pointer(uintptr(p)+field.offset))
+					if ctx.Option.collectedSince(mark) {
+						ctx.Option.addPointerToken(mark, d.pointerToken(buf, keyCursor, keyEnd, field))
+					}
 					if err != nil {
-						return 0, err
+						err = withPointerToken(err, d.pointerToken(buf, keyCursor, keyEnd, field))
+						if !ctx.Option.collectError(err) {
+							return 0, err
+						}
+						if c, err = skipValue(buf, cursor, depth); err != nil {
+							return 0, err
+						}
 					}
 					cursor = c
 					seenFieldNum++
-					if d.fieldUniqueNameNum <= seenFieldNum {
+					if !disallowDuplicateKeys && d.fieldUniqueNameNum <= seenFieldNum {
 						return skipObject(buf, cursor, depth)
 					}
-					seenFields[field.fieldIdx] = struct{}{}
 				}
 			} else {
+				mark := ctx.Option.errorMark()
 				c, err := field.dec.Decode(ctx, cursor, depth, unsafe.Pointer(uintptr(p)+field.offset))
+				if ctx.Option.collectedSince(mark) {
+					ctx.Option.addPointerToken(mark, d.pointerToken(buf, keyCursor, keyEnd, field))
+				}
 				if err != nil {
-					return 0, err
+					err = withPointerToken(err, d.pointerToken(buf, keyCursor, keyEnd, field))
+					if !ctx.Option.collectError(err) {
+						return 0, err
+					}
+					if c, err = skipValue(buf, cursor, depth); err != nil {
+						return 0, err
+					}
 				}
 				cursor = c
 			}
+		} else if d.unknownFields != nil {
+			c, err := d.unknownFields.Decode(ctx, cursor, depth, p, unknownKey)
+			if err != nil {
+				return 0, err
+			}
+			cursor = c
 		} else {
 			c, err := skipValue(buf, cursor, depth)
 			if err != nil {
@@ -840,6 +1018,55 @@ func (d *structDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsaf
 	}
 }
 
+// decodeKeyByte decodes the object key without unescaping it in the buffer,
+// so that documentKey can decode the key in the document again only when an error is reported.
+func (d *structDecoder) decodeKeyByte(buf []byte, cursor int64) ([]byte, int64, error) {
+	cursor = skipWhiteSpace(buf, cursor)
+	if buf[cursor] == '"' {
+		if end := indexSpecialChar(buf, cursor+1); buf[end] == '"' {
+			return buf[cursor+1 : end], end + 1, nil
+		}
+		// the escaped key is rare, so it is decoded from the copy.
+		return decodeRawPathString(d.stringDecoder, buf, cursor)
+	}
+	return d.stringDecoder.decodeByte(buf, cursor)
+}
+
+// documentKey returns the object key between start and end as it is written in the document.
+// The key decoders don't unescape the key in the buffer, so the key can be decoded again after the value is decoded.
+func (d *structDecoder) documentKey(buf []byte, start, end int64) ([]byte, error) {
+	start = skipWhiteSpace(buf, start)
+	if key := buf[start+1 : end-1]; bytes.IndexByte(key, '\\') < 0 {
+		return key, nil
+	}
+	key, _, err := decodeRawPathString(d.stringDecoder, buf, start)
+	return key, err
+}
+
+// pointerToken returns the reference token of the field value from the key in the document,
+// which may differ from the key of the field by the case or the escaped characters.
+func (d *structDecoder) pointerToken(buf []byte, start, end int64, field *structFieldSet) string {
+	key, err := d.documentKey(buf, start, end)
+	if err != nil || string(key) == field.key {
+		return field.key
+	}
+	return string(key)
+}
+
+func (d *structDecoder) errUnknownField(buf []byte, start, end int64) error {
+	key, err := d.documentKey(buf, start, end)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("json: unknown field %q", key)
+}
+
+func copyString(s string) string {
+	b := make([]byte, len(s))
+	copy(b, s)
+	return *(*string)(unsafe.Pointer(&b))
+}
+
 func (d *structDecoder) DecodePath(ctx *RuntimeContext, cursor, depth int64) ([][]byte, int64, error) {
 	return nil, 0, fmt.Errorf("json: struct decoder does not support decode path")
 }
@@ -0,0 +1,53 @@
+package decoder
+
+import (
+	"unsafe"
+)
+
+// unknownFieldsDecoder decodes the members that don't match any field of the struct
+// into the map field that has the unknown option.
+type unknownFieldsDecoder struct {
+	mapDecoder *mapDecoder
+	offset     uintptr
+}
+
+func newUnknownFieldsDecoder(mapDec *mapDecoder, offset uintptr) *unknownFieldsDecoder {
+	return &unknownFieldsDecoder{
+		mapDecoder: mapDec,
+		offset:     offset,
+	}
+}
+
+// mapValue returns the map of the struct p. The map is allocated if it is nil.
+func (d *unknownFieldsDecoder) mapValue(p unsafe.Pointer) unsafe.Pointer {
+	m := (*unsafe.Pointer)(unsafe.Pointer(uintptr(p) + d.offset))
+	if *m == nil {
+		*m = makemap(d.mapDecoder.mapType, 0)
+	}
+	return *m
+}
+
+func (d *unknownFieldsDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer, key string) error {
+	v := unsafe_New(d.mapDecoder.valueType)
+	if err := d.mapDecoder.valueDecoder.DecodeStream(s, depth, v); err != nil {
+		return withPointerToken(err, key)
+	}
+	d.mapDecoder.mapassign(d.mapDecoder.mapType, d.mapValue(p), unsafe.Pointer(&key), v)
+	return nil
+}
+
+func (d *unknownFieldsDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsafe.Pointer, key string) (int64, error) {
+	v := unsafe_New(d.mapDecoder.valueType)
+	mark := ctx.Option.errorMark()
+	c, err := d.mapDecoder.valueDecoder.Decode(ctx, cursor, depth, v)
+	ctx.Option.addPointerToken(mark, key)
+	if err != nil {
+		err = withPointerToken(err, key)
+		if !ctx.Option.collectError(err) {
+			return 0, err
+		}
+		return skipValue(ctx.Buf, cursor, depth)
+	}
+	d.mapDecoder.mapassign(d.mapDecoder.mapType, d.mapValue(p), unsafe.Pointer(&key), v)
+	return c, nil
+}
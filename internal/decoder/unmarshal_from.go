@@ -0,0 +1,334 @@
+package decoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/errors"
+	"github.com/goccy/go-json/internal/runtime"
+	"github.com/goccy/go-json/internal/scanner"
+)
+
+type unmarshalerFrom interface {
+	UnmarshalJSONFrom(*TokenDecoder) error
+}
+
+var unmarshalJSONFromType = reflect.TypeOf((*unmarshalerFrom)(nil)).Elem()
+
+// tokenState is the position of TokenDecoder in the grammar of the value.
+type tokenState int
+
+const (
+	tokenValue       tokenState = iota // a value is expected
+	tokenArrayStart                    // an element or ']' is expected
+	tokenArrayComma                    // ',' or ']' is expected after an element
+	tokenObjectStart                   // a key or '}' is expected
+	tokenObjectKey                     // a key is expected after ','
+	tokenObjectColon                   // ':' is expected after a key
+	tokenObjectComma                   // ',' or '}' is expected after a member
+)
+
+// TokenDecoder reads a JSON value from the stream for UnmarshalJSONFrom.
+// It reads exactly one value, so the tokens after the value are not returned.
+type TokenDecoder struct {
+	s      *Stream
+	depth  int64
+	delims []byte // the opened delimiters of the value
+	state  tokenState
+	read   bool // the value has been read completely
+}
+
+// Token returns the next token of the value.
+// The commas and colons are skipped like Decoder.Token, but they must be at the right positions.
+// It returns io.EOF after the value has been read completely.
+func (d *TokenDecoder) Token() (json.Token, error) {
+	if d.read {
+		return nil, io.EOF
+	}
+	c, err := d.skipSeparator()
+	if err != nil {
+		return nil, err
+	}
+	state := d.state
+	switch state {
+	case tokenValue, tokenArrayStart:
+		if c == '}' || (c == ']' && state == tokenValue) {
+			return nil, errors.ErrInvalidBeginningOfValue(c, d.s.totalOffset())
+		}
+	case tokenArrayComma:
+		if c != ']' {
+			return nil, errors.ErrExpected("comma after array value", d.s.totalOffset())
+		}
+	case tokenObjectStart, tokenObjectKey:
+		if c != '"' && (c != '}' || state == tokenObjectKey) {
+			return nil, errors.ErrInvalidCharacter(c, "object key", d.s.totalOffset())
+		}
+	case tokenObjectComma:
+		if c != '}' {
+			return nil, errors.ErrExpected("comma after object value", d.s.totalOffset())
+		}
+	}
+	switch c {
+	case '{', '[':
+		if d.depth+int64(len(d.delims)) >= maxDecodeNestingDepth {
+			return nil, errors.ErrExceededMaxDepth(c, d.s.totalOffset())
+		}
+	}
+	tok, err := d.s.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c == '{':
+		d.delims = append(d.delims, c)
+		d.state = tokenObjectStart
+	case c == '[':
+		d.delims = append(d.delims, c)
+		d.state = tokenArrayStart
+	case c == '}' || c == ']':
+		d.delims = d.delims[:len(d.delims)-1]
+		d.endValue()
+	case state == tokenObjectStart || state == tokenObjectKey:
+		d.state = tokenObjectColon
+	default:
+		d.endValue()
+	}
+	return tok, nil
+}
+
+// skipSeparator skips the comma or colon that is expected at the position, and returns the next character.
+func (d *TokenDecoder) skipSeparator() (byte, error) {
+	for {
+		c := d.s.skipWhiteSpace()
+		switch {
+		case c == ',' && d.state == tokenArrayComma:
+			d.state = tokenValue
+		case c == ',' && d.state == tokenObjectComma:
+			d.state = tokenObjectKey
+		case c == ':' && d.state == tokenObjectColon:
+			d.state = tokenValue
+		case c == nul:
+			return 0, errors.ErrUnexpectedEndOfJSON("value", d.s.totalOffset())
+		case d.state == tokenObjectColon:
+			return 0, errors.ErrExpected("colon after object key", d.s.totalOffset())
+		case (c == ',' || c == ':') && d.state == tokenArrayComma:
+			return 0, errors.ErrExpected("comma after array value", d.s.totalOffset())
+		case (c == ',' || c == ':') && d.state == tokenObjectComma:
+			return 0, errors.ErrExpected("comma after object value", d.s.totalOffset())
+		case (c == ',' || c == ':') && (d.state == tokenObjectStart || d.state == tokenObjectKey):
+			return 0, errors.ErrInvalidCharacter(c, "object key", d.s.totalOffset())
+		case c == ',' || c == ':':
+			return 0, errors.ErrInvalidBeginningOfValue(c, d.s.totalOffset())
+		default:
+			return c, nil
+		}
+		d.s.cursor++
+	}
+}
+
+// endValue moves the state to the end of the value.
+func (d *TokenDecoder) endValue() {
+	last := len(d.delims) - 1
+	switch {
+	case last < 0:
+		d.read = true
+	case d.delims[last] == '[':
+		d.state = tokenArrayComma
+	default:
+		d.state = tokenObjectComma
+	}
+}
+
+// More reports whether there is another element in the current array or object.
+func (d *TokenDecoder) More() bool {
+	if d.read || len(d.delims) == 0 {
+		return false
+	}
+	return d.s.More()
+}
+
+// Decode reads the next value, and stores it in the value pointed to by v.
+// The value is decoded with the same options as the value that has UnmarshalJSONFrom.
+func (d *TokenDecoder) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &errors.InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	if err := d.prepareValue(); err != nil {
+		return err
+	}
+	header := (*emptyInterface)(unsafe.Pointer(&v))
+	dec, err := CompileToGetDecoderWithOption(header.typ, d.s.Option)
+	if err != nil {
+		return err
+	}
+	if err := dec.DecodeStream(d.s, d.depth+int64(len(d.delims)), header.ptr); err != nil {
+		return err
+	}
+	d.endValue()
+	return nil
+}
+
+// ReadValue returns the next value as it is.
+func (d *TokenDecoder) ReadValue() ([]byte, error) {
+	if err := d.prepareValue(); err != nil {
+		return nil, err
+	}
+	src, err := d.skipValue()
+	if err != nil {
+		return nil, err
+	}
+	dst := make([]byte, len(src))
+	copy(dst, src)
+	d.endValue()
+	return dst, nil
+}
+
+// SkipValue skips the next value.
+func (d *TokenDecoder) SkipValue() error {
+	if err := d.prepareValue(); err != nil {
+		return err
+	}
+	if _, err := d.skipValue(); err != nil {
+		return err
+	}
+	d.endValue()
+	return nil
+}
+
+// skipValue skips the next value, and returns the bytes of the value in the buffer.
+// The stream checks only the brackets to skip the value, so the grammar of the value is validated here.
+func (d *TokenDecoder) skipValue() ([]byte, error) {
+	start := d.s.cursor
+	if err := d.s.skipValue(d.depth + int64(len(d.delims))); err != nil {
+		return nil, err
+	}
+	src := d.s.buf[start:d.s.cursor]
+	if err := scanner.Validate(src); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// InputOffset returns the input stream byte offset of the current decoder position.
+func (d *TokenDecoder) InputOffset() int64 {
+	return d.s.totalOffset()
+}
+
+// prepareValue moves the cursor to the beginning of the next value.
+func (d *TokenDecoder) prepareValue() error {
+	if d.read {
+		return io.EOF
+	}
+	c, err := d.skipSeparator()
+	if err != nil {
+		return err
+	}
+	switch {
+	case d.state == tokenArrayComma:
+		return errors.ErrExpected("comma after array value", d.s.totalOffset())
+	case d.state == tokenObjectComma:
+		return errors.ErrExpected("comma after object value", d.s.totalOffset())
+	case d.state != tokenValue && d.state != tokenArrayStart, c == '}', c == ']':
+		return errors.ErrNotAtBeginningOfValue(d.s.totalOffset())
+	}
+	return nil
+}
+
+type unmarshalFromDecoder struct {
+	typ        *runtime.Type
+	structName string
+	fieldName  string
+}
+
+func newUnmarshalFromDecoder(typ *runtime.Type, structName, fieldName string) *unmarshalFromDecoder {
+	return &unmarshalFromDecoder{
+		typ:        typ,
+		structName: structName,
+		fieldName:  fieldName,
+	}
+}
+
+func (d *unmarshalFromDecoder) annotateError(cursor int64, err error) {
+	switch e := err.(type) {
+	case *errors.UnmarshalTypeError:
+		e.Struct = d.structName
+		e.Field = d.fieldName
+	case *errors.SyntaxError:
+		e.Offset = cursor
+	}
+}
+
+func (d *unmarshalFromDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) error {
+	s.skipWhiteSpace()
+	v := *(*interface{})(unsafe.Pointer(&emptyInterface{
+		typ: d.typ,
+		ptr: p,
+	}))
+	if err := decodeStreamUnmarshalerFrom(s, depth, v.(unmarshalerFrom)); err != nil {
+		d.annotateError(s.totalOffset(), err)
+		return err
+	}
+	return nil
+}
+
+func (d *unmarshalFromDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsafe.Pointer) (int64, error) {
+	v := *(*interface{})(unsafe.Pointer(&emptyInterface{
+		typ: d.typ,
+		ptr: p,
+	}))
+	end, err := decodeUnmarshalerFrom(ctx, ctx.Buf, cursor, depth, v.(unmarshalerFrom))
+	if err != nil {
+		d.annotateError(cursor, err)
+		return 0, err
+	}
+	return end, nil
+}
+
+func (d *unmarshalFromDecoder) DecodePath(ctx *RuntimeContext, cursor, depth int64) ([][]byte, int64, error) {
+	return nil, 0, fmt.Errorf("json: unmarshal from decoder does not support decode path")
+}
+
+// decodeStreamUnmarshalerFrom calls UnmarshalJSONFrom with the stream, so the value is not buffered before decoding.
+func decodeStreamUnmarshalerFrom(s *Stream, depth int64, unmarshaler unmarshalerFrom) error {
+	dec := &TokenDecoder{s: s, depth: depth}
+	if err := unmarshaler.UnmarshalJSONFrom(dec); err != nil {
+		return err
+	}
+	if !dec.read {
+		return fmt.Errorf("json: %T.UnmarshalJSONFrom did not read the whole value", unmarshaler)
+	}
+	return nil
+}
+
+// decodeUnmarshalerFrom calls UnmarshalJSONFrom with the stream that reads the value in buf.
+func decodeUnmarshalerFrom(ctx *RuntimeContext, buf []byte, cursor, depth int64, unmarshaler unmarshalerFrom) (int64, error) {
+	cursor = skipWhiteSpace(buf, cursor)
+	start := cursor
+	end, err := skipValue(buf, cursor, depth)
+	if err != nil {
+		return 0, err
+	}
+	// skipValue checks only the brackets, so the grammar of the value is validated here.
+	if err := scanner.Validate(buf[start:end]); err != nil {
+		return 0, err
+	}
+	src := make([]byte, end-start+1) // append nul byte to the end
+	copy(src, buf[start:end])
+	s := &Stream{
+		buf:      src,
+		bufSize:  int64(len(src)),
+		length:   end - start,
+		allRead:  true,
+		Option:   ctx.Option,
+		base:     src,
+		baseLine: 1,
+	}
+	if err := decodeStreamUnmarshalerFrom(s, depth, unmarshaler); err != nil {
+		return 0, err
+	}
+	return end, nil
+}
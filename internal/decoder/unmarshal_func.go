@@ -0,0 +1,267 @@
+package decoder
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/errors"
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+// UnmarshalFunc decodes data into v instead of the default decoding of the type.
+// The dynamic type of v is the pointer to the type that the function is registered for.
+type UnmarshalFunc func(data []byte, v interface{}) error
+
+// TypeUnmarshalFunc is the function registered for the type by DecodeOption.
+type TypeUnmarshalFunc struct {
+	Type *runtime.Type
+	Func UnmarshalFunc
+}
+
+var (
+	registeredUnmarshalFuncsMu sync.Mutex
+	registeredUnmarshalFuncs   atomic.Pointer[map[*runtime.Type]UnmarshalFunc]
+)
+
+// RegisterUnmarshalFunc registers fn to decode the values of typ for all decodings.
+// The cached decoders are discarded because they may be compiled without fn.
+func RegisterUnmarshalFunc(typ *runtime.Type, fn UnmarshalFunc) {
+	registeredUnmarshalFuncsMu.Lock()
+	defer registeredUnmarshalFuncsMu.Unlock()
+
+	var old map[*runtime.Type]UnmarshalFunc
+	if m := registeredUnmarshalFuncs.Load(); m != nil {
+		old = *m
+	}
+	funcs := make(map[*runtime.Type]UnmarshalFunc, len(old)+1)
+	for k, v := range old {
+		funcs[k] = v
+	}
+	funcs[typ] = fn
+	registeredUnmarshalFuncs.Store(&funcs)
+	clearCachedDecoders()
+}
+
+func loadRegisteredUnmarshalFuncs() map[*runtime.Type]UnmarshalFunc {
+	if m := registeredUnmarshalFuncs.Load(); m != nil {
+		return *m
+	}
+	return nil
+}
+
+func clearCachedDecoders() {
+	initDecoder()
+	for i := range cachedDecoder {
+		cachedDecoder[i].Store(nil)
+	}
+	atomic.StorePointer(&cachedDecoderMap, nil)
+
+	unmarshalFuncsCacheMu.Lock()
+	unmarshalFuncsCache = map[string]*unmarshalFuncsCacheEntry{}
+	unmarshalFuncsCacheMu.Unlock()
+}
+
+// maxUnmarshalFuncsCacheSize is the max number of the sets of the functions that the decoders are cached for.
+// If the function options are created for each decoding, the cache is cleared when it reaches the size.
+const maxUnmarshalFuncsCacheSize = 1024
+
+// unmarshalFuncsCacheEntry has the decoders compiled with the functions given by DecodeOption.
+// The decoders only depend on the types of the functions because the functions are looked up at runtime,
+// so the entry is shared by the options that have the functions for the same types.
+type unmarshalFuncsCacheEntry struct {
+	decoders map[*runtime.Type]Decoder
+}
+
+var (
+	unmarshalFuncsCacheMu sync.RWMutex
+	unmarshalFuncsCache   = map[string]*unmarshalFuncsCacheEntry{}
+)
+
+// CompileToGetDecoderWithOption is the same as CompileToGetDecoder,
+// but the decoder is compiled with the functions given by the option if it has them.
+// The decoders are cached for each set of the functions, so they don't collide with the default ones.
+func CompileToGetDecoderWithOption(typ *runtime.Type, opt *Option) (Decoder, error) {
+	if opt.Flags&UnmarshalFuncOption == 0 {
+		return CompileToGetDecoder(typ)
+	}
+	key := opt.unmarshalFuncsKey
+	unmarshalFuncsCacheMu.RLock()
+	entry := unmarshalFuncsCache[string(key)]
+	var dec Decoder
+	if entry != nil {
+		dec = entry.decoders[typ]
+	}
+	unmarshalFuncsCacheMu.RUnlock()
+	if dec != nil {
+		return dec, nil
+	}
+
+	registered := loadRegisteredUnmarshalFuncs()
+	funcs := make(map[*runtime.Type]UnmarshalFunc, len(registered)+len(opt.unmarshalFuncs))
+	for t, fn := range registered {
+		funcs[t] = fn
+	}
+	for _, f := range opt.unmarshalFuncs {
+		funcs[f.Type] = f.Func
+	}
+	dec, err := compileHead(typ, newCompileContext(funcs))
+	if err != nil {
+		return nil, err
+	}
+
+	unmarshalFuncsCacheMu.Lock()
+	defer unmarshalFuncsCacheMu.Unlock()
+	entry = unmarshalFuncsCache[string(key)]
+	if entry == nil {
+		if len(unmarshalFuncsCache) >= maxUnmarshalFuncsCacheSize {
+			unmarshalFuncsCache = map[string]*unmarshalFuncsCacheEntry{}
+		}
+		entry = &unmarshalFuncsCacheEntry{
+			decoders: map[*runtime.Type]Decoder{},
+		}
+		unmarshalFuncsCache[string(key)] = entry
+	}
+	entry.decoders[typ] = dec
+	return dec, nil
+}
+
+// AddUnmarshalFunc adds f to the functions used by this decoding.
+// The function added later has priority over the previous one for the same type.
+// Adding the same function again does nothing, because the option of Decoder is kept between the decodings.
+func (o *Option) AddUnmarshalFunc(f *TypeUnmarshalFunc) {
+	if o.Flags&UnmarshalFuncOption == 0 {
+		o.Flags |= UnmarshalFuncOption
+		o.unmarshalFuncs = o.unmarshalFuncs[:0]
+		o.unmarshalFuncsKey = o.unmarshalFuncsKey[:0]
+	}
+	for _, added := range o.unmarshalFuncs {
+		if added == f {
+			return
+		}
+	}
+	o.unmarshalFuncs = append(o.unmarshalFuncs, f)
+	o.unmarshalFuncsKey = insertTypeKey(o.unmarshalFuncsKey, f.Type)
+}
+
+// insertTypeKey inserts the address of typ into key that has the sorted addresses of the types.
+// The key is the same for the same set of the types regardless of the functions and the order they are added in.
+func insertTypeKey(key []byte, typ *runtime.Type) []byte {
+	const size = int(unsafe.Sizeof(uintptr(0)))
+	addr := uintptr(unsafe.Pointer(typ))
+	idx := len(key)
+	for i := 0; i < len(key); i += size {
+		var v uintptr
+		for j := size - 1; j >= 0; j-- {
+			v = v<<8 | uintptr(key[i+j])
+		}
+		if v == addr {
+			return key
+		}
+		if v > addr {
+			idx = i
+			break
+		}
+	}
+	for i := 0; i < size; i++ {
+		key = append(key, 0)
+	}
+	copy(key[idx+size:], key[idx:])
+	for i := 0; i < size; i++ {
+		key[idx+i] = byte(addr >> (8 * i))
+	}
+	return key
+}
+
+// unmarshalFunc returns the function for typ that the decoder is compiled with.
+// The function is looked up at runtime, so the decoders are shared by the options that have the functions for the same types.
+func (o *Option) unmarshalFunc(typ *runtime.Type) UnmarshalFunc {
+	if o.Flags&UnmarshalFuncOption != 0 {
+		for i := len(o.unmarshalFuncs) - 1; i >= 0; i-- {
+			if f := o.unmarshalFuncs[i]; f.Type == typ {
+				return f.Func
+			}
+		}
+	}
+	if fn := loadRegisteredUnmarshalFuncs()[typ]; fn != nil {
+		return fn
+	}
+	return func([]byte, interface{}) error {
+		return fmt.Errorf("json: unmarshal function for %s is unregistered", typ)
+	}
+}
+
+type unmarshalFuncDecoder struct {
+	typ        *runtime.Type
+	ptrType    *runtime.Type
+	structName string
+	fieldName  string
+}
+
+func newUnmarshalFuncDecoder(typ *runtime.Type, structName, fieldName string) *unmarshalFuncDecoder {
+	return &unmarshalFuncDecoder{
+		typ:        typ,
+		ptrType:    runtime.PtrTo(typ),
+		structName: structName,
+		fieldName:  fieldName,
+	}
+}
+
+func (d *unmarshalFuncDecoder) annotateError(cursor int64, err error) {
+	switch e := err.(type) {
+	case *errors.UnmarshalTypeError:
+		e.Struct = d.structName
+		e.Field = d.fieldName
+	case *errors.SyntaxError:
+		e.Offset = cursor
+	}
+}
+
+func (d *unmarshalFuncDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) error {
+	s.skipWhiteSpace()
+	start := s.cursor
+	if err := s.skipValue(depth); err != nil {
+		return err
+	}
+	src := s.buf[start:s.cursor]
+	dst := make([]byte, len(src))
+	copy(dst, src)
+
+	v := *(*interface{})(unsafe.Pointer(&emptyInterface{
+		typ: d.ptrType,
+		ptr: p,
+	}))
+	if err := s.Option.unmarshalFunc(d.typ)(dst, v); err != nil {
+		d.annotateError(s.cursor, err)
+		return err
+	}
+	return nil
+}
+
+func (d *unmarshalFuncDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsafe.Pointer) (int64, error) {
+	buf := ctx.Buf
+	cursor = skipWhiteSpace(buf, cursor)
+	start := cursor
+	end, err := skipValue(buf, cursor, depth)
+	if err != nil {
+		return 0, err
+	}
+	src := buf[start:end]
+	dst := make([]byte, len(src))
+	copy(dst, src)
+
+	v := *(*interface{})(unsafe.Pointer(&emptyInterface{
+		typ: d.ptrType,
+		ptr: p,
+	}))
+	if err := ctx.Option.unmarshalFunc(d.typ)(dst, v); err != nil {
+		d.annotateError(cursor, err)
+		return 0, err
+	}
+	return end, nil
+}
+
+func (d *unmarshalFuncDecoder) DecodePath(ctx *RuntimeContext, cursor, depth int64) ([][]byte, int64, error) {
+	return nil, 0, fmt.Errorf("json: unmarshal func decoder does not support decode path")
+}
@@ -0,0 +1,287 @@
+package decoder
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/errors"
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+var (
+	valueStringDecoder = newStringDecoder("", "")
+	valueIntDecoder    = newIntDecoder(runtime.Type2RType(reflect.TypeOf(int64(0))), "", "", func(p unsafe.Pointer, v int64) {
+		*(*int64)(p) = v
+	})
+	valueFloatDecoder = newFloatDecoder("", "", func(p unsafe.Pointer, v float64) {
+		*(*float64)(p) = v
+	})
+	valueBoolDecoder = newBoolDecoder("", "")
+
+	valueObjectType = reflect.TypeOf(map[string]interface{}{})
+	valueArrayType  = reflect.TypeOf([]interface{}{})
+	valueStringType = reflect.TypeOf("")
+	valueIntType    = reflect.TypeOf(int64(0))
+	valueFloatType  = reflect.TypeOf(float64(0))
+	valueBoolType   = reflect.TypeOf(false)
+)
+
+// Value is the view of the JSON value in the buffer that ends with nul byte,
+// or in the validated buffer whose value ends with the closing character of object, array or string.
+// The children are scanned on demand, and the buffer is never modified,
+// so the values derived from the same buffer can be used independently.
+type Value struct {
+	buf        []byte
+	start, end int64
+	depth      int64
+}
+
+// NewValue scans the JSON value in the buffer that ends with nul byte, or in the validated buffer as described in Value.
+// It returns the cursor after the value to validate the rest of the buffer.
+func NewValue(buf []byte) (Value, int64, error) {
+	start := skipWhiteSpace(buf, 0)
+	end, err := skipValue(buf, start, 0)
+	if err != nil {
+		return Value{}, 0, err
+	}
+	return Value{buf: buf, start: start, end: end}, end, nil
+}
+
+// IsValid reports whether the value refers to the buffer.
+func (v Value) IsValid() bool {
+	return v.buf != nil
+}
+
+// Buf returns the whole buffer that the value refers to.
+func (v Value) Buf() []byte {
+	return v.buf
+}
+
+// Raw returns the bytes of the value.
+func (v Value) Raw() []byte {
+	return v.buf[v.start:v.end]
+}
+
+func (v Value) kindName() string {
+	switch v.buf[v.start] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "bool"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
+
+func (v Value) typeError(typ reflect.Type) *errors.UnmarshalTypeError {
+	return &errors.UnmarshalTypeError{Value: v.kindName(), Type: typ, Offset: v.start}
+}
+
+// expectKind returns the type error if the value is neither the kind nor null.
+func (v Value) expectKind(kind string, typ reflect.Type) error {
+	if k := v.kindName(); k != kind && k != "null" {
+		return v.typeError(typ)
+	}
+	return nil
+}
+
+// Member returns the member of object by name. If the object has duplicate names, the first one is used.
+// The members after the found member are not scanned.
+func (v Value) Member(name string) (Value, bool, error) {
+	iter, err := v.ObjectIter()
+	if err != nil {
+		return Value{}, false, err
+	}
+	for iter.Next() {
+		if string(iter.Key()) == name {
+			return iter.Value(), true, nil
+		}
+	}
+	return Value{}, false, iter.Err()
+}
+
+// Index returns the element of array by index. The elements after the found element are not scanned.
+func (v Value) Index(idx int) (Value, bool, error) {
+	iter, err := v.ArrayIter()
+	if err != nil {
+		return Value{}, false, err
+	}
+	if idx < 0 {
+		return Value{}, false, nil
+	}
+	for i := 0; iter.Next(); i++ {
+		if i == idx {
+			return iter.Value(), true, nil
+		}
+	}
+	return Value{}, false, iter.Err()
+}
+
+// DecodeString decodes the string value. The escaped string is decoded from the copy of the value.
+func (v Value) DecodeString() (string, error) {
+	if err := v.expectKind("string", valueStringType); err != nil {
+		return "", err
+	}
+	s, _, err := decodeRawPathString(valueStringDecoder, v.buf, v.start)
+	if err != nil {
+		return "", err
+	}
+	return string(s), nil
+}
+
+// DecodeInt decodes the number value as int64.
+func (v Value) DecodeInt() (int64, error) {
+	if err := v.expectKind("number", valueIntType); err != nil {
+		return 0, err
+	}
+	var i int64
+	if err := v.decode(valueIntDecoder, unsafe.Pointer(&i), valueIntType); err != nil {
+		return 0, err
+	}
+	return i, nil
+}
+
+// DecodeFloat decodes the number value as float64.
+func (v Value) DecodeFloat() (float64, error) {
+	if err := v.expectKind("number", valueFloatType); err != nil {
+		return 0, err
+	}
+	var f float64
+	if err := v.decode(valueFloatDecoder, unsafe.Pointer(&f), valueFloatType); err != nil {
+		return 0, err
+	}
+	return f, nil
+}
+
+// DecodeBool decodes the bool value.
+func (v Value) DecodeBool() (bool, error) {
+	if err := v.expectKind("bool", valueBoolType); err != nil {
+		return false, err
+	}
+	var b bool
+	if err := v.decode(valueBoolDecoder, unsafe.Pointer(&b), valueBoolType); err != nil {
+		return false, err
+	}
+	return b, nil
+}
+
+// decode decodes the value by the decoder that doesn't modify the buffer.
+// If the decoder stops in the middle of the value ( e.g. 1.5 for int64 ), it returns the type error.
+func (v Value) decode(dec Decoder, p unsafe.Pointer, typ reflect.Type) error {
+	ctx := &RuntimeContext{Buf: v.buf, Option: &Option{}}
+	cursor, err := dec.Decode(ctx, v.start, v.depth, p)
+	if err != nil {
+		return err
+	}
+	if cursor != v.end {
+		return v.typeError(typ)
+	}
+	return nil
+}
+
+// ObjectIter returns the iterator of the members of object.
+func (v Value) ObjectIter() (*ValueIter, error) {
+	if v.buf[v.start] != '{' {
+		return nil, v.typeError(valueObjectType)
+	}
+	return &ValueIter{v: v, cursor: v.start + 1, object: true}, nil
+}
+
+// ArrayIter returns the iterator of the elements of array.
+func (v Value) ArrayIter() (*ValueIter, error) {
+	if v.buf[v.start] != '[' {
+		return nil, v.typeError(valueArrayType)
+	}
+	return &ValueIter{v: v, cursor: v.start + 1}, nil
+}
+
+// ValueIter iterates the members of object or the elements of array by scanning them one by one.
+type ValueIter struct {
+	v      Value
+	cursor int64
+	object bool
+	key    []byte
+	value  Value
+	n      int
+	done   bool
+	err    error
+}
+
+// Next scans the next member or element. It returns false at the end of the value or on error.
+func (it *ValueIter) Next() bool {
+	if it.done {
+		return false
+	}
+	buf := it.v.buf
+	cursor := skipWhiteSpace(buf, it.cursor)
+	if it.n == 0 {
+		if (it.object && buf[cursor] == '}') || (!it.object && buf[cursor] == ']') {
+			it.done = true
+			return false
+		}
+	} else {
+		switch buf[cursor] {
+		case ',':
+			cursor = skipWhiteSpace(buf, cursor+1)
+		case '}', ']':
+			it.done = true
+			return false
+		default:
+			return it.fail(errors.ErrExpected("comma after value", cursor))
+		}
+	}
+	if it.object {
+		if buf[cursor] != '"' {
+			return it.fail(errors.ErrExpected("object key", cursor))
+		}
+		key, keyCursor, err := decodeRawPathString(valueStringDecoder, buf, cursor)
+		if err != nil {
+			return it.fail(err)
+		}
+		cursor = skipWhiteSpace(buf, keyCursor)
+		if buf[cursor] != ':' {
+			return it.fail(errors.ErrExpected("colon after object key", cursor))
+		}
+		cursor = skipWhiteSpace(buf, cursor+1)
+		it.key = key
+	}
+	end, err := skipValue(buf, cursor, it.v.depth+1)
+	if err != nil {
+		return it.fail(err)
+	}
+	it.value = Value{buf: buf, start: cursor, end: end, depth: it.v.depth + 1}
+	it.cursor = end
+	it.n++
+	return true
+}
+
+func (it *ValueIter) fail(err error) bool {
+	it.err = err
+	it.done = true
+	return false
+}
+
+// Key returns the name of the current member. It refers to the buffer if the name isn't escaped.
+func (it *ValueIter) Key() []byte {
+	return it.key
+}
+
+// Value returns the current member or element.
+func (it *ValueIter) Value() Value {
+	return it.value
+}
+
+// Index returns the index of the current member or element.
+func (it *ValueIter) Index() int {
+	return it.n - 1
+}
+
+// Err returns the error that occurred while scanning.
+func (it *ValueIter) Err() error {
+	return it.err
+}
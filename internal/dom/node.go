@@ -0,0 +1,373 @@
+// Package dom defines the ordered document tree that is shared by the encoder and the decoder.
+package dom
+
+import (
+	"encoding/json"
+
+	"github.com/goccy/go-json/internal/errors"
+)
+
+// Kind is the kind of JSON value that the node represents.
+type Kind uint8
+
+const (
+	Null Kind = iota
+	Bool
+	Number
+	String
+	Object
+	Array
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Null:
+		return "null"
+	case Bool:
+		return "bool"
+	case Number:
+		return "number"
+	case String:
+		return "string"
+	case Object:
+		return "object"
+	case Array:
+		return "array"
+	}
+	return "invalid"
+}
+
+// Member is the member of object node.
+type Member struct {
+	Key   string
+	Value *Node
+}
+
+// Node is the node of the document tree. The zero value is null.
+// The members of object keep the order and the duplicate names of the document,
+// and the number keeps the literal of the document.
+// The nil *Node is treated as null.
+type Node struct {
+	kind    Kind
+	value   string // literal of number or value of string
+	boolean bool
+	members []Member
+	elems   []*Node
+}
+
+func BoolOf(v bool) Node {
+	return Node{kind: Bool, boolean: v}
+}
+
+// NumberOf returns the number node of the literal. The literal is validated by IsNumber when it is encoded.
+func NumberOf(literal string) Node {
+	return Node{kind: Number, value: literal}
+}
+
+func StringOf(v string) Node {
+	return Node{kind: String, value: v}
+}
+
+func ObjectOf(members []Member) Node {
+	return Node{kind: Object, members: members}
+}
+
+func ArrayOf(elems []*Node) Node {
+	return Node{kind: Array, elems: elems}
+}
+
+// IsNumber reports whether s is the valid number literal of JSON.
+func IsNumber(s string) bool {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	switch {
+	case i == len(s):
+		return false
+	case s[i] == '0':
+		i++
+	case '1' <= s[i] && s[i] <= '9':
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+	default:
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		start := i
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return false
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		start := i
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return false
+		}
+	}
+	return i == len(s)
+}
+
+// Kind returns the kind of the node.
+func (n *Node) Kind() Kind {
+	if n == nil {
+		return Null
+	}
+	return n.kind
+}
+
+// String returns the value of string node.
+func (n *Node) String() (string, error) {
+	if n.Kind() != String {
+		return "", errors.ErrNodeKind("get string", n.Kind().String())
+	}
+	return n.value, nil
+}
+
+// Number returns the literal of number node.
+func (n *Node) Number() (json.Number, error) {
+	if n.Kind() != Number {
+		return "", errors.ErrNodeKind("get number", n.Kind().String())
+	}
+	return json.Number(n.value), nil
+}
+
+// Bool returns the value of bool node.
+func (n *Node) Bool() (bool, error) {
+	if n.Kind() != Bool {
+		return false, errors.ErrNodeKind("get bool", n.Kind().String())
+	}
+	return n.boolean, nil
+}
+
+// Len returns the number of the members of object or the elements of array. It returns 0 for the other kinds.
+func (n *Node) Len() int {
+	switch n.Kind() {
+	case Object:
+		return len(n.members)
+	case Array:
+		return len(n.elems)
+	}
+	return 0
+}
+
+// Keys returns the names of the members of object in order.
+func (n *Node) Keys() []string {
+	if n.Kind() != Object {
+		return nil
+	}
+	keys := make([]string, 0, len(n.members))
+	for _, m := range n.members {
+		keys = append(keys, m.Key)
+	}
+	return keys
+}
+
+// Key returns the name of the member of object at the position. It returns the empty string if there is no member.
+func (n *Node) Key(i int) string {
+	if n.Kind() != Object || i < 0 || i >= len(n.members) {
+		return ""
+	}
+	return n.members[i].Key
+}
+
+// IndexOf returns the position of the first member of object that has the name. It returns -1 if there is no member.
+func (n *Node) IndexOf(key string) int {
+	if n.Kind() != Object {
+		return -1
+	}
+	for i, m := range n.members {
+		if m.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get returns the value of the first member of object that has the name. It returns nil if there is no member.
+func (n *Node) Get(key string) *Node {
+	i := n.IndexOf(key)
+	if i < 0 {
+		return nil
+	}
+	return n.members[i].Value
+}
+
+// Index returns the element of array, or the value of the member of object at the position.
+// It returns nil if there is no element.
+func (n *Node) Index(i int) *Node {
+	if i < 0 || i >= n.Len() {
+		return nil
+	}
+	if n.kind == Object {
+		return n.members[i].Value
+	}
+	return n.elems[i]
+}
+
+// Set sets the value of the first member of object that has the name, or appends the member if there is no member.
+func (n *Node) Set(key string, v *Node) error {
+	if n.Kind() != Object {
+		return errors.ErrNodeKind("set member", n.Kind().String())
+	}
+	if i := n.IndexOf(key); i >= 0 {
+		n.members[i].Value = v
+		return nil
+	}
+	n.members = append(n.members, Member{Key: key, Value: v})
+	return nil
+}
+
+// SetIndex replaces the element of array, or the value of the member of object at the position.
+func (n *Node) SetIndex(i int, v *Node) error {
+	switch n.Kind() {
+	case Object:
+		if i < 0 || i >= len(n.members) {
+			return errors.ErrNodeIndex("set member", i, len(n.members))
+		}
+		n.members[i].Value = v
+	case Array:
+		if i < 0 || i >= len(n.elems) {
+			return errors.ErrNodeIndex("set element", i, len(n.elems))
+		}
+		n.elems[i] = v
+	default:
+		return errors.ErrNodeKind("set element", n.Kind().String())
+	}
+	return nil
+}
+
+// Delete removes all members of object that have the name.
+func (n *Node) Delete(key string) error {
+	if n.Kind() != Object {
+		return errors.ErrNodeKind("delete member", n.Kind().String())
+	}
+	members := n.members[:0]
+	for _, m := range n.members {
+		if m.Key != key {
+			members = append(members, m)
+		}
+	}
+	// release the references from the removed members.
+	for i := len(members); i < len(n.members); i++ {
+		n.members[i] = Member{}
+	}
+	n.members = members
+	return nil
+}
+
+// Append appends the elements to array.
+func (n *Node) Append(v ...*Node) error {
+	if n.Kind() != Array {
+		return errors.ErrNodeKind("append element", n.Kind().String())
+	}
+	n.elems = append(n.elems, v...)
+	return nil
+}
+
+// Insert inserts the element to array at the position. The position must be in the range [0:Len()].
+func (n *Node) Insert(i int, v *Node) error {
+	if n.Kind() != Array {
+		return errors.ErrNodeKind("insert element", n.Kind().String())
+	}
+	if i < 0 || i > len(n.elems) {
+		return errors.ErrNodeIndex("insert element", i, len(n.elems))
+	}
+	n.elems = insert(n.elems, i, v)
+	return nil
+}
+
+// InsertMember inserts the member to object at the position. The position must be in the range [0:Len()].
+// InsertMember doesn't remove the existing members that have the same name.
+func (n *Node) InsertMember(i int, key string, v *Node) error {
+	if n.Kind() != Object {
+		return errors.ErrNodeKind("insert member", n.Kind().String())
+	}
+	if i < 0 || i > len(n.members) {
+		return errors.ErrNodeIndex("insert member", i, len(n.members))
+	}
+	n.members = insert(n.members, i, Member{Key: key, Value: v})
+	return nil
+}
+
+// Remove removes the element of array, or the member of object at the position.
+func (n *Node) Remove(i int) error {
+	switch n.Kind() {
+	case Object:
+		if i < 0 || i >= len(n.members) {
+			return errors.ErrNodeIndex("remove member", i, len(n.members))
+		}
+		n.members = remove(n.members, i)
+	case Array:
+		if i < 0 || i >= len(n.elems) {
+			return errors.ErrNodeIndex("remove element", i, len(n.elems))
+		}
+		n.elems = remove(n.elems, i)
+	default:
+		return errors.ErrNodeKind("remove element", n.Kind().String())
+	}
+	return nil
+}
+
+// Move moves the element of array, or the member of object from the position to the other position.
+// The elements between the positions are shifted.
+func (n *Node) Move(from, to int) error {
+	switch n.Kind() {
+	case Object:
+		if from < 0 || from >= len(n.members) {
+			return errors.ErrNodeIndex("move member", from, len(n.members))
+		}
+		if to < 0 || to >= len(n.members) {
+			return errors.ErrNodeIndex("move member", to, len(n.members))
+		}
+		move(n.members, from, to)
+	case Array:
+		if from < 0 || from >= len(n.elems) {
+			return errors.ErrNodeIndex("move element", from, len(n.elems))
+		}
+		if to < 0 || to >= len(n.elems) {
+			return errors.ErrNodeIndex("move element", to, len(n.elems))
+		}
+		move(n.elems, from, to)
+	default:
+		return errors.ErrNodeKind("move element", n.Kind().String())
+	}
+	return nil
+}
+
+func insert[T any](s []T, i int, v T) []T {
+	var zero T
+	s = append(s, zero)
+	copy(s[i+1:], s[i:])
+	s[i] = v
+	return s
+}
+
+func remove[T any](s []T, i int) []T {
+	var zero T
+	copy(s[i:], s[i+1:])
+	s[len(s)-1] = zero
+	return s[:len(s)-1]
+}
+
+func move[T any](s []T, from, to int) {
+	v := s[from]
+	if from < to {
+		copy(s[from:to], s[from+1:to+1])
+	} else {
+		copy(s[to+1:from+1], s[to:from])
+	}
+	s[to] = v
+}
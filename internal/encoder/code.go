@@ -56,6 +56,11 @@ const (
 	CodeKindMarshalJSON
 	CodeKindMarshalText
 	CodeKindRecursive
+	CodeKindNode
+	CodeKindOrderedMap
+	CodeKindInlineMap
+	CodeKindFormat
+	CodeKindMarshalFunc
 )
 
 type IntCode struct {
@@ -543,6 +548,30 @@ func (c *StructCode) removeFieldsByTags(tags runtime.StructTags) {
 	c.fields = fields
 }
 
+// setInlineMapFieldKeys sets the keys of the fields to the inline maps of the struct including the embedded structs.
+func (c *StructCode) setInlineMapFieldKeys() {
+	keys := map[string]struct{}{}
+	for _, inlineMap := range c.inlineMaps(keys, nil) {
+		inlineMap.fieldKeys = keys
+	}
+}
+
+// inlineMaps collects the keys of the fields into keys, and returns the inline maps.
+func (c *StructCode) inlineMaps(keys map[string]struct{}, maps []*InlineMapCode) []*InlineMapCode {
+	for _, field := range c.fields {
+		if inlineMap, ok := field.value.(*InlineMapCode); ok {
+			maps = append(maps, inlineMap)
+			continue
+		}
+		if structCode := field.getAnonymousStruct(); structCode != nil {
+			maps = structCode.inlineMaps(keys, maps)
+			continue
+		}
+		keys[field.key] = struct{}{}
+	}
+	return maps
+}
+
 func (c *StructCode) enableIndirect() {
 	if c.isIndirect {
 		return
@@ -581,6 +610,7 @@ func (c *StructCode) Filter(query *FieldQuery) Code {
 			isNilCheck:         field.isNilCheck,
 			isAddrForMarshaler: field.isAddrForMarshaler,
 			isNextOpPtrType:    field.isNextOpPtrType,
+			isInline:           field.isInline,
 		}
 		if len(query.Fields) > 0 {
 			fieldCode.value = fieldCode.value.Filter(query)
@@ -610,6 +640,7 @@ type StructFieldCode struct {
 	isAddrForMarshaler bool
 	isNextOpPtrType    bool
 	isMarshalerContext bool
+	isInline           bool
 }
 
 func (c *StructFieldCode) getStruct() *StructCode {
@@ -634,7 +665,7 @@ func (c *StructFieldCode) getAnonymousStruct() *StructCode {
 
 func optimizeStructHeader(code *Opcode, tag *runtime.StructTag) OpType {
 	headType := code.ToHeaderType(tag.IsString)
-	if tag.IsOmitEmpty {
+	if tag.IsOmitEmpty || tag.IsOmitZero {
 		headType = headType.HeadToOmitEmptyHead()
 	}
 	return headType
@@ -642,22 +673,70 @@ func optimizeStructHeader(code *Opcode, tag *runtime.StructTag) OpType {
 
 func optimizeStructField(code *Opcode, tag *runtime.StructTag) OpType {
 	fieldType := code.ToFieldType(tag.IsString)
-	if tag.IsOmitEmpty {
+	if tag.IsOmitEmpty || tag.IsOmitZero {
 		fieldType = fieldType.FieldToOmitEmptyField()
 	}
 	return fieldType
 }
 
+// isOmitZero reports whether the field needs the generic omitzero operation.
+// If the zero value of the field is the same as the empty value, OmitEmpty operations are used instead.
+func (c *StructFieldCode) isOmitZero() bool {
+	if c.tag == nil || c.isAnonymous {
+		return false
+	}
+	switch c.value.Kind() {
+	case CodeKindFormat, CodeKindMarshalFunc:
+		if c.tag.IsOmitEmpty {
+			// the value is omitted by the empty value of the field type, not by the encoded value.
+			return true
+		}
+	case CodeKindOrderedMap:
+		if c.tag.IsOmitEmpty && c.typ.Kind() != reflect.Ptr {
+			// OmitEmpty operations don't check the entries of OrderedMap.
+			return true
+		}
+	}
+	if !c.tag.IsOmitZero {
+		return false
+	}
+	return !isOmitZeroFastPath(c.typ, c.value)
+}
+
+func (c *StructFieldCode) setupOmitZeroOpcode(field, value *Opcode) {
+	storeZeroFuncs(c.typ)
+	if c.tag.IsOmitEmpty {
+		field.Flags |= OmitEmptyFlags
+	}
+	if c.tag.IsOmitZero {
+		field.Flags |= OmitZeroFlags
+	}
+	if value.Op == OpMap {
+		// OmitZero operation passes the address of the field to the next operation.
+		value.Op = OpMapPtr
+		value.PtrNum = 1
+	}
+}
+
 func (c *StructFieldCode) headerOpcodes(ctx *compileContext, field *Opcode, valueCodes Opcodes) Opcodes {
 	value := valueCodes.First()
 	op := optimizeStructHeader(value, c.tag)
+	if c.isOmitZero() {
+		op = OpStructHeadOmitZero
+	}
 	field.Op = op
 	if value.Flags&MarshalerContextFlags != 0 {
 		field.Flags |= MarshalerContextFlags
 	}
+	if value.Flags&AppendMarshalerFlags != 0 {
+		field.Flags |= AppendMarshalerFlags
+	}
 	field.NumBitSize = value.NumBitSize
 	field.PtrNum = value.PtrNum
 	field.FieldQuery = value.FieldQuery
+	if op == OpStructHeadOmitZero {
+		c.setupOmitZeroOpcode(field, value)
+	}
 	fieldCodes := Opcodes{field}
 	if op.IsMultipleOpHead() {
 		field.Next = value
@@ -671,13 +750,22 @@ func (c *StructFieldCode) headerOpcodes(ctx *compileContext, field *Opcode, valu
 func (c *StructFieldCode) fieldOpcodes(ctx *compileContext, field *Opcode, valueCodes Opcodes) Opcodes {
 	value := valueCodes.First()
 	op := optimizeStructField(value, c.tag)
+	if c.isOmitZero() {
+		op = OpStructFieldOmitZero
+	}
 	field.Op = op
 	if value.Flags&MarshalerContextFlags != 0 {
 		field.Flags |= MarshalerContextFlags
 	}
+	if value.Flags&AppendMarshalerFlags != 0 {
+		field.Flags |= AppendMarshalerFlags
+	}
 	field.NumBitSize = value.NumBitSize
 	field.PtrNum = value.PtrNum
 	field.FieldQuery = value.FieldQuery
+	if op == OpStructFieldOmitZero {
+		c.setupOmitZeroOpcode(field, value)
+	}
 
 	fieldCodes := Opcodes{field}
 	if op.IsMultipleOpField() {
@@ -736,7 +824,8 @@ func (c *StructFieldCode) flags() OpFlags {
 	if c.isNextOpPtrType {
 		flags |= IsNextOpPtrTypeFlags
 	}
-	if c.isAnonymous {
+	if c.isAnonymous || c.isInline {
+		// the key is not written because the members of the value are written in the struct.
 		flags |= AnonymousKeyFlags
 	}
 	if c.isMarshalerContext {
@@ -777,7 +866,7 @@ func (c *StructFieldCode) ToOpcode(ctx *compileContext, isFirstField, isEndField
 	}
 	codes := c.fieldOpcodes(ctx, field, valueCodes)
 	if isEndField {
-		if isEnableStructEndOptimization(c.value) {
+		if isEnableStructEndOptimization(c.value) && !c.isOmitZero() {
 			field.Op = field.Op.FieldToEnd()
 		} else {
 			codes = c.addStructEndCode(ctx, codes)
@@ -861,6 +950,7 @@ type MarshalJSONCode struct {
 	isAddrForMarshaler bool
 	isNilableType      bool
 	isMarshalerContext bool
+	isAppendMarshaler  bool
 }
 
 func (c *MarshalJSONCode) Kind() CodeKind {
@@ -876,6 +966,9 @@ func (c *MarshalJSONCode) ToOpcode(ctx *compileContext) Opcodes {
 	if c.isMarshalerContext {
 		code.Flags |= MarshalerContextFlags
 	}
+	if c.isAppendMarshaler {
+		code.Flags |= AppendMarshalerFlags
+	}
 	if c.isNilableType {
 		code.Flags |= IsNilableTypeFlags
 	} else {
@@ -892,6 +985,7 @@ func (c *MarshalJSONCode) Filter(query *FieldQuery) Code {
 		isAddrForMarshaler: c.isAddrForMarshaler,
 		isNilableType:      c.isNilableType,
 		isMarshalerContext: c.isMarshalerContext,
+		isAppendMarshaler:  c.isAppendMarshaler,
 	}
 }
 
@@ -930,6 +1024,189 @@ func (c *MarshalTextCode) Filter(query *FieldQuery) Code {
 	}
 }
 
+type NodeCode struct {
+	typ   *runtime.Type
+	isPtr bool
+}
+
+func (c *NodeCode) Kind() CodeKind {
+	return CodeKindNode
+}
+
+func (c *NodeCode) ToOpcode(ctx *compileContext) Opcodes {
+	var code *Opcode
+	if c.isPtr {
+		code = newOpCode(ctx, c.typ, OpNodePtr)
+	} else {
+		code = newOpCode(ctx, c.typ, OpNode)
+	}
+	ctx.incIndex()
+	return Opcodes{code}
+}
+
+func (c *NodeCode) Filter(_ *FieldQuery) Code {
+	return c
+}
+
+type OrderedMapCode struct {
+	typ    *runtime.Type
+	layout *runtime.OrderedMapType
+	key    Code
+	value  Code
+	isPtr  bool
+}
+
+func (c *OrderedMapCode) Kind() CodeKind {
+	return CodeKindOrderedMap
+}
+
+func (c *OrderedMapCode) ToOpcode(ctx *compileContext) Opcodes {
+	// header => code => value => code => key => end
+	//            ^                        |
+	//            |________________________|
+	// the entries are iterated like slice elements, and the key and the value of each entry are encoded like map.
+	size := c.layout.Entry.Size()
+	header := newOrderedMapHeaderCode(ctx, c.typ)
+	if c.isPtr {
+		header.Op = OpOrderedMapPtr
+	}
+	ctx.incIndex()
+
+	// the indentation of the keys is taken from the key codes.
+	ctx.incIndent()
+	keyCodes := c.key.ToOpcode(ctx)
+	ctx.decIndent()
+
+	value := newOrderedMapValueCode(ctx, c.layout.Value, header, size, c.layout.ValueOffset)
+	ctx.incIndex()
+
+	ctx.incIndent()
+	valueCodes := c.value.ToOpcode(ctx)
+	ctx.decIndent()
+
+	valueCodes.First().Flags |= IndirectFlags
+
+	key := newOrderedMapKeyCode(ctx, c.layout.Key, header, size)
+	ctx.incIndex()
+
+	end := newOpCode(ctx, c.typ, OpOrderedMapEnd)
+	ctx.incIndex()
+
+	header.Next = keyCodes.First()
+	keyCodes.Last().Next = value
+	value.Next = valueCodes.First()
+	valueCodes.Last().Next = key
+	key.Next = keyCodes.First()
+
+	header.End = end
+	key.End = end
+	value.End = end
+	return Opcodes{header}.Add(keyCodes...).Add(value).Add(valueCodes...).Add(key).Add(end)
+}
+
+func (c *OrderedMapCode) Filter(_ *FieldQuery) Code {
+	return c
+}
+
+// FormatCode writes the value of the field that has the format option.
+type FormatCode struct {
+	typ    *runtime.Type
+	format runtime.FieldFormat
+}
+
+func (c *FormatCode) Kind() CodeKind {
+	return CodeKindFormat
+}
+
+func (c *FormatCode) ToOpcode(ctx *compileContext) Opcodes {
+	var op OpType
+	switch c.format {
+	case runtime.FormatUnix:
+		op = OpTimeUnix
+	case runtime.FormatUnixMilli:
+		op = OpTimeUnixMilli
+	case runtime.FormatHex:
+		op = OpBytesHex
+	case runtime.FormatBase64URL:
+		op = OpBytesBase64URL
+	case runtime.FormatUnits:
+		op = OpDurationUnits
+	}
+	code := newOpCode(ctx, c.typ, op)
+	ctx.incIndex()
+	return Opcodes{code}
+}
+
+func (c *FormatCode) Filter(_ *FieldQuery) Code {
+	return c
+}
+
+// MarshalFuncCode writes the value by the function registered for the type.
+type MarshalFuncCode struct {
+	typ           *runtime.Type
+	isNilableType bool
+}
+
+func (c *MarshalFuncCode) Kind() CodeKind {
+	return CodeKindMarshalFunc
+}
+
+func (c *MarshalFuncCode) ToOpcode(ctx *compileContext) Opcodes {
+	code := newOpCode(ctx, c.typ, OpMarshalFunc)
+	if c.isNilableType {
+		code.Flags |= IsNilableTypeFlags
+	} else {
+		code.Flags &= ^IsNilableTypeFlags
+	}
+	ctx.incIndex()
+	return Opcodes{code}
+}
+
+func (c *MarshalFuncCode) Filter(_ *FieldQuery) Code {
+	return c
+}
+
+// InlineMapCode writes the entries of the map as the members of the struct that has the map field.
+// The map is encoded as usual, and the braces of it are removed at the end.
+type InlineMapCode struct {
+	typ   *runtime.Type
+	value *MapCode
+
+	// fieldKeys are the keys of the fields of the struct.
+	// The entries of these keys are not written to keep the members of the object unique.
+	fieldKeys map[string]struct{}
+}
+
+func (c *InlineMapCode) Kind() CodeKind {
+	return CodeKindInlineMap
+}
+
+func (c *InlineMapCode) ToOpcode(ctx *compileContext) Opcodes {
+	// header => map codes => end
+	header := newInlineMapHeaderCode(ctx, c.typ)
+	if len(c.fieldKeys) != 0 {
+		header.Size = storeInlineMapFieldKeys(c.fieldKeys)
+	}
+	ctx.incIndex()
+
+	// the keys of the map are written at the indentation of the fields.
+	ctx.decIndent()
+	mapCodes := c.value.ToOpcode(ctx)
+	ctx.incIndent()
+
+	end := newInlineMapEndCode(ctx, c.typ, header, mapCodes.First())
+	ctx.incIndex()
+
+	header.Next = mapCodes.First()
+	mapCodes.Last().Next = end
+	header.End = end
+	return Opcodes{header}.Add(mapCodes...).Add(end)
+}
+
+func (c *InlineMapCode) Filter(_ *FieldQuery) Code {
+	return c
+}
+
 type PtrCode struct {
 	typ    *runtime.Type
 	value  Code
@@ -1008,6 +1285,22 @@ func convertPtrOp(code *Opcode) OpType {
 		return OpInterfacePtr
 	case OpRecursive:
 		return OpRecursivePtr
+	case OpNode:
+		return OpNodePtr
+	case OpOrderedMap:
+		return OpOrderedMapPtr
+	case OpTimeUnix:
+		return OpTimeUnixPtr
+	case OpTimeUnixMilli:
+		return OpTimeUnixMilliPtr
+	case OpBytesHex:
+		return OpBytesHexPtr
+	case OpBytesBase64URL:
+		return OpBytesBase64URLPtr
+	case OpDurationUnits:
+		return OpDurationUnitsPtr
+	case OpMarshalFunc:
+		return OpMarshalFuncPtr
 	}
 	return code.Op
 }
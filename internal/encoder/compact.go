@@ -7,6 +7,7 @@ import (
 	"unsafe"
 
 	"github.com/goccy/go-json/internal/errors"
+	"github.com/goccy/go-json/internal/scanner"
 )
 
 var (
@@ -24,46 +25,25 @@ var (
 	nul = byte('\000')
 )
 
+// Compact appends to buf the src without the insignificant spaces.
+// The output is built in the buffer of the runtime context, so buf is not changed if src is invalid.
 func Compact(buf *bytes.Buffer, src []byte, escape bool) error {
-	if len(src) == 0 {
-		return errors.ErrUnexpectedEndOfJSON("", 0)
-	}
-	buf.Grow(len(src))
-	dst := buf.Bytes()
-
 	ctx := TakeRuntimeContext()
-	ctxBuf := ctx.Buf[:0]
-	ctxBuf = append(append(ctxBuf, src...), nul)
-	ctx.Buf = ctxBuf
-
-	if err := compactAndWrite(buf, dst, ctxBuf, escape); err != nil {
-		ReleaseRuntimeContext(ctx)
-		return err
+	dst, err := scanner.Compact(ctx.Buf[:0], src, escape)
+	ctx.Buf = dst
+	if err == nil {
+		buf.Write(dst)
 	}
 	ReleaseRuntimeContext(ctx)
-	return nil
-}
-
-func compactAndWrite(buf *bytes.Buffer, dst []byte, src []byte, escape bool) error {
-	dst, err := compact(dst, src, escape)
-	if err != nil {
-		return err
-	}
-	if _, err := buf.Write(dst); err != nil {
-		return err
-	}
-	return nil
+	return err
 }
 
-func compact(dst, src []byte, escape bool) ([]byte, error) {
-	buf, cursor, err := compactValue(dst, src, 0, escape)
-	if err != nil {
-		return nil, err
-	}
-	if err := validateEndBuf(src, cursor); err != nil {
-		return nil, err
-	}
-	return buf, nil
+// HTMLEscape appends to buf the src with the HTML characters escaped. src is not validated.
+func HTMLEscape(buf *bytes.Buffer, src []byte) {
+	ctx := TakeRuntimeContext()
+	ctx.Buf = scanner.HTMLEscape(ctx.Buf[:0], src)
+	buf.Write(ctx.Buf)
+	ReleaseRuntimeContext(ctx)
 }
 
 func validateEndBuf(src []byte, cursor int64) error {
@@ -91,110 +71,6 @@ LOOP:
 	return cursor
 }
 
-func compactValue(dst, src []byte, cursor int64, escape bool) ([]byte, int64, error) {
-	for {
-		switch src[cursor] {
-		case ' ', '\t', '\n', '\r':
-			cursor++
-			continue
-		case '{':
-			return compactObject(dst, src, cursor, escape)
-		case '}':
-			return nil, 0, errors.ErrSyntax("unexpected character '}'", cursor)
-		case '[':
-			return compactArray(dst, src, cursor, escape)
-		case ']':
-			return nil, 0, errors.ErrSyntax("unexpected character ']'", cursor)
-		case '"':
-			return compactString(dst, src, cursor, escape)
-		case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-			return compactNumber(dst, src, cursor)
-		case 't':
-			return compactTrue(dst, src, cursor)
-		case 'f':
-			return compactFalse(dst, src, cursor)
-		case 'n':
-			return compactNull(dst, src, cursor)
-		default:
-			return nil, 0, errors.ErrSyntax(fmt.Sprintf("unexpected character '%c'", src[cursor]), cursor)
-		}
-	}
-}
-
-func compactObject(dst, src []byte, cursor int64, escape bool) ([]byte, int64, error) {
-	if src[cursor] == '{' {
-		dst = append(dst, '{')
-	} else {
-		return nil, 0, errors.ErrExpected("expected { character for object value", cursor)
-	}
-	cursor = skipWhiteSpace(src, cursor+1)
-	if src[cursor] == '}' {
-		dst = append(dst, '}')
-		return dst, cursor + 1, nil
-	}
-	var err error
-	for {
-		cursor = skipWhiteSpace(src, cursor)
-		dst, cursor, err = compactString(dst, src, cursor, escape)
-		if err != nil {
-			return nil, 0, err
-		}
-		cursor = skipWhiteSpace(src, cursor)
-		if src[cursor] != ':' {
-			return nil, 0, errors.ErrExpected("colon after object key", cursor)
-		}
-		dst = append(dst, ':')
-		dst, cursor, err = compactValue(dst, src, cursor+1, escape)
-		if err != nil {
-			return nil, 0, err
-		}
-		cursor = skipWhiteSpace(src, cursor)
-		switch src[cursor] {
-		case '}':
-			dst = append(dst, '}')
-			cursor++
-			return dst, cursor, nil
-		case ',':
-			dst = append(dst, ',')
-		default:
-			return nil, 0, errors.ErrExpected("comma after object value", cursor)
-		}
-		cursor++
-	}
-}
-
-func compactArray(dst, src []byte, cursor int64, escape bool) ([]byte, int64, error) {
-	if src[cursor] == '[' {
-		dst = append(dst, '[')
-	} else {
-		return nil, 0, errors.ErrExpected("expected [ character for array value", cursor)
-	}
-	cursor = skipWhiteSpace(src, cursor+1)
-	if src[cursor] == ']' {
-		dst = append(dst, ']')
-		return dst, cursor + 1, nil
-	}
-	var err error
-	for {
-		dst, cursor, err = compactValue(dst, src, cursor, escape)
-		if err != nil {
-			return nil, 0, err
-		}
-		cursor = skipWhiteSpace(src, cursor)
-		switch src[cursor] {
-		case ']':
-			dst = append(dst, ']')
-			cursor++
-			return dst, cursor, nil
-		case ',':
-			dst = append(dst, ',')
-		default:
-			return nil, 0, errors.ErrExpected("comma after array value", cursor)
-		}
-		cursor++
-	}
-}
-
 func compactString(dst, src []byte, cursor int64, escape bool) ([]byte, int64, error) {
 	if src[cursor] != '"' {
 		return nil, 0, errors.ErrInvalidCharacter(src[cursor], "string", cursor)
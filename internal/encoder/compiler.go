@@ -15,6 +15,13 @@ import (
 
 func CompileToGetCodeSet(ctx *RuntimeContext, typeptr uintptr) (*OpcodeSet, error) {
 	initEncoder()
+	if (ctx.Option.Flag & MarshalFuncOption) != 0 {
+		codeSet, err := compileToGetCodeSetWithMarshalFuncs(ctx, typeptr)
+		if err != nil {
+			return nil, err
+		}
+		return getFilteredCodeSetIfNeeded(ctx, codeSet)
+	}
 	if typeptr > typeAddr.MaxTypeAddr || typeptr < typeAddr.BaseTypeAddr {
 		codeSet, err := compileToGetCodeSetSlowPath(typeptr)
 		if err != nil {
@@ -46,9 +53,15 @@ type marshalerContext interface {
 	MarshalJSON(context.Context) ([]byte, error)
 }
 
+// appendMarshaler is the marshaler that appends the encoding to the buffer of the encoder.
+type appendMarshaler interface {
+	AppendJSON([]byte) ([]byte, error)
+}
+
 var (
 	marshalJSONType        = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
 	marshalJSONContextType = reflect.TypeOf((*marshalerContext)(nil)).Elem()
+	appendMarshalerType    = reflect.TypeOf((*appendMarshaler)(nil)).Elem()
 	marshalTextType        = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
 	jsonNumberType         = reflect.TypeOf(json.Number(""))
 	cachedOpcodeSets       []atomic.Pointer[OpcodeSet]
@@ -119,11 +132,13 @@ func getFilteredCodeSetIfNeeded(ctx *RuntimeContext, codeSet *OpcodeSet) (*Opcod
 
 type Compiler struct {
 	structTypeToCode map[uintptr]*StructCode
+	marshalFuncs     map[*runtime.Type]MarshalFunc
 }
 
 func newCompiler() *Compiler {
 	return &Compiler{
 		structTypeToCode: map[uintptr]*StructCode{},
+		marshalFuncs:     loadRegisteredMarshalFuncs(),
 	}
 }
 
@@ -172,6 +187,18 @@ func (c *Compiler) codeToOpcodeSet(typ *runtime.Type, code Code) (*OpcodeSet, er
 
 func (c *Compiler) typeToCode(typ *runtime.Type) (Code, error) {
 	switch {
+	case c.marshalFuncs[typ] != nil:
+		return c.marshalFuncCode(typ)
+	case typ.Kind() == reflect.Ptr && c.marshalFuncs[typ.Elem()] != nil:
+		return c.ptrCode(typ)
+	case typ == runtime.Type2RType(nodeType):
+		return c.nodeCode(typ, false)
+	case typ.Kind() == reflect.Ptr && typ.Elem() == runtime.Type2RType(nodeType):
+		return c.nodeCode(typ.Elem(), true)
+	case runtime.ToOrderedMapType(typ) != nil:
+		return c.orderedMapCode(typ, false)
+	case typ.Kind() == reflect.Ptr && runtime.ToOrderedMapType(typ.Elem()) != nil:
+		return c.orderedMapCode(typ.Elem(), true)
 	case c.implementsMarshalJSON(typ):
 		return c.marshalJSONCode(typ)
 	case c.implementsMarshalText(typ):
@@ -247,6 +274,14 @@ func (c *Compiler) typeToCode(typ *runtime.Type) (Code, error) {
 
 func (c *Compiler) typeToCodeWithPtr(typ *runtime.Type, isPtr bool) (Code, error) {
 	switch {
+	case c.marshalFuncs[typ] != nil:
+		return c.marshalFuncCode(typ)
+	case typ.Kind() == reflect.Ptr && c.marshalFuncs[typ.Elem()] != nil:
+		return c.ptrCode(typ)
+	case typ == runtime.Type2RType(nodeType):
+		return c.nodeCode(typ, false)
+	case runtime.ToOrderedMapType(typ) != nil:
+		return c.orderedMapCode(typ, false)
 	case c.implementsMarshalJSON(typ):
 		return c.marshalJSONCode(typ)
 	case c.implementsMarshalText(typ):
@@ -445,6 +480,7 @@ func (c *Compiler) marshalJSONCode(typ *runtime.Type) (*MarshalJSONCode, error)
 		isAddrForMarshaler: c.isPtrMarshalJSONType(typ),
 		isNilableType:      c.isNilableType(typ),
 		isMarshalerContext: typ.Implements(marshalJSONContextType) || runtime.PtrTo(typ).Implements(marshalJSONContextType),
+		isAppendMarshaler:  typ.Implements(appendMarshalerType) || runtime.PtrTo(typ).Implements(appendMarshalerType),
 	}, nil
 }
 
@@ -457,6 +493,44 @@ func (c *Compiler) marshalTextCode(typ *runtime.Type) (*MarshalTextCode, error)
 	}, nil
 }
 
+//nolint:unparam
+func (c *Compiler) marshalFuncCode(typ *runtime.Type) (*MarshalFuncCode, error) {
+	return &MarshalFuncCode{
+		typ:           typ,
+		isNilableType: c.isNilableType(typ),
+	}, nil
+}
+
+func (c *Compiler) nodeCode(typ *runtime.Type, isPtr bool) (*NodeCode, error) {
+	return &NodeCode{typ: typ, isPtr: isPtr}, nil
+}
+
+func (c *Compiler) orderedMapCode(typ *runtime.Type, isPtr bool) (*OrderedMapCode, error) {
+	layout := runtime.ToOrderedMapType(typ)
+	keyCode, err := c.mapKeyCode(layout.Key)
+	if err != nil {
+		return nil, err
+	}
+	valueCode, err := c.mapValueCode(layout.Value)
+	if err != nil {
+		return nil, err
+	}
+	if valueCode.Kind() == CodeKindStruct {
+		structCode := valueCode.(*StructCode)
+		structCode.enableIndirect()
+	}
+	return &OrderedMapCode{typ: typ, layout: layout, key: keyCode, value: valueCode, isPtr: isPtr}, nil
+}
+
+// formatCode returns the code of the value that has the format option.
+// The format option can be used for the pointer to the value.
+func (c *Compiler) formatCode(typ *runtime.Type, format runtime.FieldFormat) Code {
+	if typ.Kind() == reflect.Ptr {
+		return &PtrCode{typ: typ, value: &FormatCode{typ: typ.Elem(), format: format}, ptrNum: 1}
+	}
+	return &FormatCode{typ: typ, format: format}
+}
+
 func (c *Compiler) ptrCode(typ *runtime.Type) (*PtrCode, error) {
 	code, err := c.typeToCodeWithPtr(typ.Elem(), true)
 	if err != nil {
@@ -513,6 +587,8 @@ func (c *Compiler) mapCode(typ *runtime.Type) (*MapCode, error) {
 
 func (c *Compiler) listElemCode(typ *runtime.Type) (Code, error) {
 	switch {
+	case c.hasMarshalFunc(typ):
+		return c.typeToCodeWithPtr(typ, true)
 	case c.implementsMarshalJSONType(typ) || c.implementsMarshalJSONType(runtime.PtrTo(typ)):
 		return c.marshalJSONCode(typ)
 	case !typ.Implements(marshalTextType) && runtime.PtrTo(typ).Implements(marshalTextType):
@@ -574,6 +650,9 @@ func (c *Compiler) mapKeyCode(typ *runtime.Type) (Code, error) {
 }
 
 func (c *Compiler) mapValueCode(typ *runtime.Type) (Code, error) {
+	if c.hasMarshalFunc(typ) {
+		return c.typeToCodeWithPtr(typ, false)
+	}
 	switch typ.Kind() {
 	case reflect.Map:
 		return c.ptrCode(runtime.PtrTo(typ))
@@ -642,7 +721,8 @@ func (c *Compiler) structCode(typ *runtime.Type, isPtr bool) (*StructCode, error
 	}
 	fieldMap := c.getFieldMap(fields)
 	duplicatedFieldMap := c.getDuplicatedFieldMap(fieldMap)
-	code.fields = c.filteredDuplicatedFields(fields, duplicatedFieldMap)
+	code.fields = moveInlineFieldsToEnd(c.filteredDuplicatedFields(fields, duplicatedFieldMap))
+	code.setInlineMapFieldKeys()
 	if !code.disableIndirectConversion && !indirect && isPtr {
 		code.enableIndirect()
 	}
@@ -666,12 +746,49 @@ func (c *Compiler) structFieldCode(structCode *StructCode, tag *runtime.StructTa
 		key:           tag.Key,
 		tag:           tag,
 		offset:        field.Offset,
-		isAnonymous:   field.Anonymous && !tag.IsTaggedKey && toElemType(fieldType).Kind() == reflect.Struct,
-		isTaggedKey:   tag.IsTaggedKey,
+		isAnonymous:   (field.Anonymous && !tag.IsTaggedKey && toElemType(fieldType).Kind() == reflect.Struct) || tag.IsInlineStruct(),
+		isTaggedKey:   tag.IsTaggedKey && !tag.IsInline,
 		isNilableType: c.isNilableType(fieldType),
 		isNilCheck:    true,
 	}
+	format, ok := tag.FieldFormat()
+	if !ok {
+		return nil, errors.ErrFieldFormat(field, tag.Format)
+	}
 	switch {
+	case format != runtime.FormatNone:
+		code := c.formatCode(fieldType, format)
+		if code.Kind() == CodeKindPtr {
+			fieldCode.isNextOpPtrType = true
+		}
+		fieldCode.value = code
+	case tag.IsInlineMap():
+		if !runtime.IsUnknownFieldsType(field.Type) {
+			if tag.IsUnknown {
+				return nil, errors.ErrUnknownFieldsType(field)
+			}
+			return nil, errors.ErrInlineFieldType(field)
+		}
+		code, err := c.mapCode(fieldType)
+		if err != nil {
+			return nil, err
+		}
+		fieldCode.value = &InlineMapCode{typ: fieldType, value: code}
+		fieldCode.isInline = true
+		fieldCode.isTaggedKey = false
+	case tag.IsInline && !tag.IsInlineStruct():
+		return nil, errors.ErrInlineFieldType(field)
+	case c.hasMarshalFunc(fieldType):
+		code, err := c.typeToCodeWithPtr(fieldType, isPtr)
+		if err != nil {
+			return nil, err
+		}
+		// the embedded struct is encoded by the function as the value of the field.
+		fieldCode.isAnonymous = false
+		if code.Kind() == CodeKindPtr {
+			fieldCode.isNextOpPtrType = true
+		}
+		fieldCode.value = code
 	case c.isMovePointerPositionFromHeadToFirstMarshalJSONFieldCase(fieldType, isIndirectSpecialCase):
 		code, err := c.marshalJSONCode(fieldType)
 		if err != nil {
@@ -740,9 +857,46 @@ func (c *Compiler) isAssignableIndirect(fieldCode *StructFieldCode, isPtr bool)
 	return true
 }
 
+// moveInlineFieldsToEnd moves the field that has the unknown option to the end,
+// so that the members of it are written after the other fields.
+// The field of the embedded struct is also moved, and only the first one is used
+// if there are multiple fields like the decoder.
+func moveInlineFieldsToEnd(fields []*StructFieldCode) []*StructFieldCode {
+	sorted := make([]*StructFieldCode, 0, len(fields))
+	var inlineFields, embeddedInlineFields []*StructFieldCode
+	for _, field := range fields {
+		if field.isInline {
+			inlineFields = append(inlineFields, field)
+			continue
+		}
+		structCode, ok := field.value.(*StructCode)
+		if ok && field.isAnonymous && !structCode.isRecursive {
+			if last := len(structCode.fields) - 1; last >= 0 && structCode.fields[last].isInline {
+				inlineField := *structCode.fields[last]
+				inlineField.offset += field.offset
+				embeddedInlineFields = append(embeddedInlineFields, &inlineField)
+				structCode.fields = structCode.fields[:last]
+				if last == 0 {
+					continue
+				}
+			}
+		}
+		sorted = append(sorted, field)
+	}
+	inlineFields = append(inlineFields, embeddedInlineFields...)
+	if len(inlineFields) > 0 {
+		sorted = append(sorted, inlineFields[0])
+	}
+	return sorted
+}
+
 func (c *Compiler) getFieldMap(fields []*StructFieldCode) map[string][]*StructFieldCode {
 	fieldMap := map[string][]*StructFieldCode{}
 	for _, field := range fields {
+		if field.isInline {
+			// the name of the inline field is not used as the key.
+			continue
+		}
 		if field.isAnonymous {
 			for k, v := range c.getAnonymousFieldMap(field) {
 				fieldMap[k] = append(fieldMap[k], v...)
@@ -863,6 +1017,14 @@ func (c *Compiler) isMovePointerPositionFromHeadToFirstMarshalTextFieldCase(typ
 	return isIndirectSpecialCase && !c.isNilableType(typ) && c.isPtrMarshalTextType(typ)
 }
 
+// hasMarshalFunc reports whether the function is registered for the type or the element type of the pointer.
+func (c *Compiler) hasMarshalFunc(typ *runtime.Type) bool {
+	if c.marshalFuncs[typ] != nil {
+		return true
+	}
+	return typ.Kind() == reflect.Ptr && c.marshalFuncs[typ.Elem()] != nil
+}
+
 func (c *Compiler) implementsMarshalJSON(typ *runtime.Type) bool {
 	if !c.implementsMarshalJSONType(typ) {
 		return false
@@ -910,7 +1072,7 @@ func (c *Compiler) isNilableType(typ *runtime.Type) bool {
 }
 
 func (c *Compiler) implementsMarshalJSONType(typ *runtime.Type) bool {
-	return typ.Implements(marshalJSONType) || typ.Implements(marshalJSONContextType)
+	return typ.Implements(marshalJSONType) || typ.Implements(marshalJSONContextType) || typ.Implements(appendMarshalerType)
 }
 
 func (c *Compiler) isPtrMarshalJSONType(typ *runtime.Type) bool {
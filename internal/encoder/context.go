@@ -2,6 +2,7 @@ package encoder
 
 import (
 	"context"
+	"io"
 	"sync"
 	"unsafe"
 
@@ -79,6 +80,14 @@ type RuntimeContext struct {
 	Prefix     []byte
 	IndentStr  []byte
 	Option     *Option
+
+	// FlushWriter receives the encoded bytes when the buffer grows to FlushThreshold.
+	// The buffer is not flushed if FlushThreshold is 0.
+	FlushWriter    io.Writer
+	FlushThreshold int
+	// flushBarrier is the number of the open values that refer to the offsets of the buffer,
+	// like the sorted maps. The buffer is not flushed while it is not 0.
+	flushBarrier int
 }
 
 func (c *RuntimeContext) Init(p uintptr, codelen int) {
@@ -89,6 +98,29 @@ func (c *RuntimeContext) Init(p uintptr, codelen int) {
 	c.KeepRefs = c.KeepRefs[:0]
 	c.SeenPtr = c.SeenPtr[:0]
 	c.BaseIndent = 0
+	c.flushBarrier = 0
+}
+
+// IncFlushBarrier prevents the buffer from being flushed until DecFlushBarrier is called.
+func (c *RuntimeContext) IncFlushBarrier() {
+	c.flushBarrier++
+}
+
+// DecFlushBarrier releases the barrier added by IncFlushBarrier.
+func (c *RuntimeContext) DecFlushBarrier() {
+	c.flushBarrier--
+}
+
+// Flush writes b to FlushWriter and returns the empty buffer to reuse its memory.
+// It must be called only at the boundary of the values, because the bytes before it can't be rewritten after that.
+func (c *RuntimeContext) Flush(b []byte) ([]byte, error) {
+	if c.flushBarrier > 0 {
+		return b, nil
+	}
+	if _, err := c.FlushWriter.Write(b); err != nil {
+		return nil, err
+	}
+	return b[:0], nil
 }
 
 func (c *RuntimeContext) Ptr() uintptr {
@@ -3,6 +3,8 @@ package encoder
 import (
 	"testing"
 	"unsafe"
+
+	"github.com/goccy/go-json/internal/runtime"
 )
 
 func TestDumpOpcode(t *testing.T) {
@@ -18,3 +20,24 @@ func TestDumpOpcode(t *testing.T) {
 	}
 	codeSet.EscapeKeyCode.Dump()
 }
+
+func TestMarshalFuncsKey(t *testing.T) {
+	typeOf := func(v interface{}) *runtime.Type {
+		return (*emptyInterface)(unsafe.Pointer(&v)).typ
+	}
+	fn := func(interface{}) ([]byte, error) { return nil, nil }
+	intType, stringType := typeOf(1), typeOf("")
+
+	var a, b Option
+	a.AddMarshalFunc(&TypeMarshalFunc{Type: intType, Func: fn})
+	a.AddMarshalFunc(&TypeMarshalFunc{Type: stringType, Func: fn})
+	b.AddMarshalFunc(&TypeMarshalFunc{Type: stringType, Func: fn})
+	b.AddMarshalFunc(&TypeMarshalFunc{Type: intType, Func: fn})
+	b.AddMarshalFunc(&TypeMarshalFunc{Type: intType, Func: fn})
+	if string(a.marshalFuncsKey) != string(b.marshalFuncsKey) {
+		t.Fatal("expected the same key for the functions of the same types")
+	}
+	if len(a.marshalFuncsKey) != 2*int(unsafe.Sizeof(uintptr(0))) {
+		t.Fatalf("unexpected key length %d", len(a.marshalFuncsKey))
+	}
+}
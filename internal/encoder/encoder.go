@@ -15,6 +15,7 @@ import (
 
 	"github.com/goccy/go-json/internal/errors"
 	"github.com/goccy/go-json/internal/runtime"
+	"github.com/goccy/go-json/internal/scanner"
 )
 
 func (t OpType) IsMultipleOpHead() bool {
@@ -51,6 +52,8 @@ func (t OpType) IsMultipleOpHead() bool {
 		return true
 	case OpStructHeadOmitEmptyMapPtr:
 		return true
+	case OpStructHeadOmitZero:
+		return true
 	}
 	return false
 }
@@ -89,6 +92,8 @@ func (t OpType) IsMultipleOpField() bool {
 		return true
 	case OpStructFieldOmitEmptyMapPtr:
 		return true
+	case OpStructFieldOmitZero:
+		return true
 	}
 	return false
 }
@@ -412,6 +417,11 @@ func AppendMarshalJSON(ctx *RuntimeContext, code *Opcode, b []byte, v interface{
 	}
 
 	v = rv.Interface()
+	if (code.Flags & AppendMarshalerFlags) != 0 {
+		if marshaler, ok := v.(appendMarshaler); ok {
+			return appendJSON(ctx, b, marshaler, v)
+		}
+	}
 	var bb []byte
 	if (code.Flags & MarshalerContextFlags) != 0 {
 		marshaler, ok := v.(marshalerContext)
@@ -438,16 +448,33 @@ func AppendMarshalJSON(ctx *RuntimeContext, code *Opcode, b []byte, v interface{
 		}
 		bb = b
 	}
-	marshalBuf := ctx.MarshalBuf[:0]
-	marshalBuf = append(append(marshalBuf, bb...), nul)
-	compactedBuf, err := compact(b, marshalBuf, (ctx.Option.Flag&HTMLEscapeOption) != 0)
+	compactedBuf, err := scanner.Compact(b, bb, (ctx.Option.Flag&HTMLEscapeOption) != 0)
 	if err != nil {
 		return nil, &errors.MarshalerError{Type: reflect.TypeOf(v), Err: err}
 	}
-	ctx.MarshalBuf = marshalBuf
 	return compactedBuf, nil
 }
 
+// appendJSON appends the output of AppendJSON to b directly.
+// The output is trusted to be the compact JSON value unless ValidateAppendJSONOption is set,
+// so it is neither compacted nor escaped for HTML unlike the output of MarshalJSON.
+func appendJSON(ctx *RuntimeContext, b []byte, marshaler appendMarshaler, v interface{}) ([]byte, error) {
+	start := len(b)
+	bb, err := marshaler.AppendJSON(b)
+	if err != nil {
+		return nil, &errors.MarshalerError{Type: reflect.TypeOf(v), Err: err}
+	}
+	if len(bb) < start {
+		return nil, &errors.MarshalerError{Type: reflect.TypeOf(v), Err: fmt.Errorf("AppendJSON must return the buffer that the value is appended to")}
+	}
+	if (ctx.Option.Flag & ValidateAppendJSONOption) != 0 {
+		if err := scanner.Validate(bb[start:]); err != nil {
+			return nil, &errors.MarshalerError{Type: reflect.TypeOf(v), Err: err}
+		}
+	}
+	return bb, nil
+}
+
 func AppendMarshalJSONIndent(ctx *RuntimeContext, code *Opcode, b []byte, v interface{}) ([]byte, error) {
 	rv := reflect.ValueOf(v) // convert by dynamic interface type
 	if (code.Flags & AddrForMarshalerFlags) != 0 {
@@ -460,6 +487,16 @@ func AppendMarshalJSONIndent(ctx *RuntimeContext, code *Opcode, b []byte, v inte
 		}
 	}
 	v = rv.Interface()
+	if (code.Flags & AppendMarshalerFlags) != 0 {
+		if marshaler, ok := v.(appendMarshaler); ok {
+			// the output is indented from MarshalBuf, so it doesn't need to be appended to b.
+			bb, err := marshaler.AppendJSON(ctx.MarshalBuf[:0])
+			if err != nil {
+				return nil, &errors.MarshalerError{Type: reflect.TypeOf(v), Err: err}
+			}
+			return appendIndentedMarshalerOutput(ctx, code, b, bb, v, "MarshalJSON")
+		}
+	}
 	var bb []byte
 	if (code.Flags & MarshalerContextFlags) != 0 {
 		marshaler, ok := v.(marshalerContext)
@@ -482,6 +519,12 @@ func AppendMarshalJSONIndent(ctx *RuntimeContext, code *Opcode, b []byte, v inte
 		}
 		bb = b
 	}
+	return appendIndentedMarshalerOutput(ctx, code, b, bb, v, "MarshalJSON")
+}
+
+// appendIndentedMarshalerOutput appends the output of the marshaler with the indentation of the code.
+// srcFunc is the name of the function that returned bb, which is reported in the error.
+func appendIndentedMarshalerOutput(ctx *RuntimeContext, code *Opcode, b, bb []byte, v interface{}, srcFunc string) ([]byte, error) {
 	marshalBuf := ctx.MarshalBuf[:0]
 	marshalBuf = append(append(marshalBuf, bb...), nul)
 	indentedBuf, err := doIndent(
@@ -492,12 +535,33 @@ func AppendMarshalJSONIndent(ctx *RuntimeContext, code *Opcode, b []byte, v inte
 		(ctx.Option.Flag&HTMLEscapeOption) != 0,
 	)
 	if err != nil {
-		return nil, &errors.MarshalerError{Type: reflect.TypeOf(v), Err: err}
+		return nil, errors.ErrMarshaler(reflect.TypeOf(v), err, srcFunc)
 	}
 	ctx.MarshalBuf = marshalBuf
 	return indentedBuf, nil
 }
 
+// AppendMarshalFunc appends the output of the function given by MarshalFunc option.
+func AppendMarshalFunc(ctx *RuntimeContext, code *Opcode, b []byte, v interface{}) ([]byte, error) {
+	bb, err := ctx.Option.marshalFunc(code.Type)(v)
+	if err != nil {
+		return nil, errors.ErrMarshaler(reflect.TypeOf(v), err, "MarshalFunc")
+	}
+	compactedBuf, err := scanner.Compact(b, bb, (ctx.Option.Flag&HTMLEscapeOption) != 0)
+	if err != nil {
+		return nil, errors.ErrMarshaler(reflect.TypeOf(v), err, "MarshalFunc")
+	}
+	return compactedBuf, nil
+}
+
+func AppendMarshalFuncIndent(ctx *RuntimeContext, code *Opcode, b []byte, v interface{}) ([]byte, error) {
+	bb, err := ctx.Option.marshalFunc(code.Type)(v)
+	if err != nil {
+		return nil, errors.ErrMarshaler(reflect.TypeOf(v), err, "MarshalFunc")
+	}
+	return appendIndentedMarshalerOutput(ctx, code, b, bb, v, "MarshalFunc")
+}
+
 func AppendMarshalText(ctx *RuntimeContext, code *Opcode, b []byte, v interface{}) ([]byte, error) {
 	rv := reflect.ValueOf(v) // convert by dynamic interface type
 	if (code.Flags & AddrForMarshalerFlags) != 0 {
@@ -0,0 +1,50 @@
+package encoder
+
+import (
+	"encoding/base64"
+	"strconv"
+	"time"
+	"unsafe"
+)
+
+// The values of the fields that have the format option are encoded by the following functions.
+// p is the address of the value.
+
+func AppendTimeUnix(_ *RuntimeContext, b []byte, p uintptr) []byte {
+	return strconv.AppendInt(b, (*time.Time)(*(*unsafe.Pointer)(unsafe.Pointer(&p))).Unix(), 10)
+}
+
+func AppendTimeUnixMilli(_ *RuntimeContext, b []byte, p uintptr) []byte {
+	return strconv.AppendInt(b, (*time.Time)(*(*unsafe.Pointer)(unsafe.Pointer(&p))).UnixMilli(), 10)
+}
+
+func AppendBytesHex(_ *RuntimeContext, b []byte, p uintptr) []byte {
+	src := **(**[]byte)(unsafe.Pointer(&p))
+	if src == nil {
+		return append(b, `null`...)
+	}
+	b = append(b, '"')
+	for _, c := range src {
+		b = append(b, hex[c>>4], hex[c&0xF])
+	}
+	return append(b, '"')
+}
+
+func AppendBytesBase64URL(_ *RuntimeContext, b []byte, p uintptr) []byte {
+	src := **(**[]byte)(unsafe.Pointer(&p))
+	if src == nil {
+		return append(b, `null`...)
+	}
+	b = append(b, '"')
+	pos := len(b)
+	b = append(b, make([]byte, base64.URLEncoding.EncodedLen(len(src)))...)
+	base64.URLEncoding.Encode(b[pos:], src)
+	return append(b, '"')
+}
+
+func AppendDurationUnits(_ *RuntimeContext, b []byte, p uintptr) []byte {
+	// the string of time.Duration doesn't have the characters to be escaped.
+	b = append(b, '"')
+	b = append(b, (**(**time.Duration)(unsafe.Pointer(&p))).String()...)
+	return append(b, '"')
+}
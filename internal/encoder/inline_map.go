@@ -0,0 +1,69 @@
+package encoder
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+// inlineMapFieldKeys has the keys of the fields of the struct for each inline map.
+// The header of the inline map keeps the 1-based index of the keys in Size, so that Opcode doesn't grow.
+var (
+	inlineMapFieldKeys   atomic.Pointer[[]map[string]struct{}]
+	inlineMapFieldKeysMu sync.Mutex
+)
+
+func storeInlineMapFieldKeys(keys map[string]struct{}) uint32 {
+	inlineMapFieldKeysMu.Lock()
+	defer inlineMapFieldKeysMu.Unlock()
+
+	var old []map[string]struct{}
+	if v := inlineMapFieldKeys.Load(); v != nil {
+		old = *v
+	}
+	list := make([]map[string]struct{}, len(old), len(old)+1)
+	copy(list, old)
+	list = append(list, keys)
+	inlineMapFieldKeys.Store(&list)
+	return uint32(len(list))
+}
+
+// FilterInlineMap returns the map at p without the entries whose keys are written from the fields of the struct.
+// The map is copied only if it has such entries, and the copy is kept in ctx until the encoding ends.
+func FilterInlineMap(ctx *RuntimeContext, code *Opcode, p uintptr) uintptr {
+	if code.Size == 0 {
+		return p
+	}
+	keys := (*inlineMapFieldKeys.Load())[code.Size-1]
+	m := *(*unsafe.Pointer)(unsafe.Pointer(&p))
+	if !hasInlineMapFieldKey(code.Type, m, keys) {
+		return p
+	}
+	src := reflect.NewAt(runtime.RType2Type(code.Type), unsafe.Pointer(&m)).Elem()
+	dst := reflect.MakeMapWithSize(src.Type(), src.Len())
+	iter := src.MapRange()
+	for iter.Next() {
+		if _, exists := keys[iter.Key().String()]; exists {
+			continue
+		}
+		dst.SetMapIndex(iter.Key(), iter.Value())
+	}
+	filtered := dst.UnsafePointer()
+	ctx.KeepRefs = append(ctx.KeepRefs, filtered)
+	return uintptr(filtered)
+}
+
+func hasInlineMapFieldKey(typ *runtime.Type, m unsafe.Pointer, keys map[string]struct{}) bool {
+	var iter mapIter
+	MapIterInit(typ, m, &iter)
+	for key := MapIterKey(&iter); key != nil; key = MapIterKey(&iter) {
+		if _, exists := keys[*(*string)(key)]; exists {
+			return true
+		}
+		MapIterNext(&iter)
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package encoder
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+// MarshalFunc encodes v instead of the default encoding of the type.
+// The dynamic type of v is the type that the function is registered for.
+type MarshalFunc func(v interface{}) ([]byte, error)
+
+// TypeMarshalFunc is the function registered for the type by EncodeOption.
+type TypeMarshalFunc struct {
+	Type *runtime.Type
+	Func MarshalFunc
+}
+
+var (
+	registeredMarshalFuncsMu sync.Mutex
+	registeredMarshalFuncs   atomic.Pointer[map[*runtime.Type]MarshalFunc]
+)
+
+// RegisterMarshalFunc registers fn to encode the values of typ for all encodings.
+// The cached opcodes are discarded because they may be compiled without fn.
+func RegisterMarshalFunc(typ *runtime.Type, fn MarshalFunc) {
+	registeredMarshalFuncsMu.Lock()
+	defer registeredMarshalFuncsMu.Unlock()
+
+	var old map[*runtime.Type]MarshalFunc
+	if m := registeredMarshalFuncs.Load(); m != nil {
+		old = *m
+	}
+	funcs := make(map[*runtime.Type]MarshalFunc, len(old)+1)
+	for k, v := range old {
+		funcs[k] = v
+	}
+	funcs[typ] = fn
+	registeredMarshalFuncs.Store(&funcs)
+	clearCachedOpcodeSets()
+}
+
+func loadRegisteredMarshalFuncs() map[*runtime.Type]MarshalFunc {
+	if m := registeredMarshalFuncs.Load(); m != nil {
+		return *m
+	}
+	return nil
+}
+
+func clearCachedOpcodeSets() {
+	initEncoder()
+	for i := range cachedOpcodeSets {
+		cachedOpcodeSets[i].Store(nil)
+	}
+	atomic.StorePointer(&cachedOpcodeMap, nil)
+
+	marshalFuncsCacheMu.Lock()
+	marshalFuncsCache = map[string]*marshalFuncsCacheEntry{}
+	marshalFuncsCacheMu.Unlock()
+}
+
+// maxMarshalFuncsCacheSize is the max number of the sets of the functions that the opcodes are cached for.
+// If the function options are created for each encoding, the cache is cleared when it reaches the size.
+const maxMarshalFuncsCacheSize = 1024
+
+// marshalFuncsCacheEntry has the opcodes compiled with the functions given by EncodeOption.
+// The opcodes only depend on the types of the functions because the functions are looked up at runtime,
+// so the entry is shared by the options that have the functions for the same types.
+type marshalFuncsCacheEntry struct {
+	codeSets map[uintptr]*OpcodeSet
+}
+
+var (
+	marshalFuncsCacheMu sync.RWMutex
+	marshalFuncsCache   = map[string]*marshalFuncsCacheEntry{}
+)
+
+// compileToGetCodeSetWithMarshalFuncs compiles the type with the functions given by EncodeOption.
+// The opcodes are cached for each set of the functions, so they don't collide with the default ones.
+func compileToGetCodeSetWithMarshalFuncs(ctx *RuntimeContext, typeptr uintptr) (*OpcodeSet, error) {
+	key := ctx.Option.marshalFuncsKey
+	marshalFuncsCacheMu.RLock()
+	entry := marshalFuncsCache[string(key)]
+	var codeSet *OpcodeSet
+	if entry != nil {
+		codeSet = entry.codeSets[typeptr]
+	}
+	marshalFuncsCacheMu.RUnlock()
+	if codeSet != nil {
+		return codeSet, nil
+	}
+
+	c := newCompiler()
+	funcs := make(map[*runtime.Type]MarshalFunc, len(c.marshalFuncs)+len(ctx.Option.marshalFuncs))
+	for typ, fn := range c.marshalFuncs {
+		funcs[typ] = fn
+	}
+	for _, f := range ctx.Option.marshalFuncs {
+		funcs[f.Type] = f.Func
+	}
+	c.marshalFuncs = funcs
+	codeSet, err := c.compile(typeptr)
+	if err != nil {
+		return nil, err
+	}
+
+	marshalFuncsCacheMu.Lock()
+	defer marshalFuncsCacheMu.Unlock()
+	entry = marshalFuncsCache[string(key)]
+	if entry == nil {
+		if len(marshalFuncsCache) >= maxMarshalFuncsCacheSize {
+			marshalFuncsCache = map[string]*marshalFuncsCacheEntry{}
+		}
+		entry = &marshalFuncsCacheEntry{
+			codeSets: map[uintptr]*OpcodeSet{},
+		}
+		marshalFuncsCache[string(key)] = entry
+	}
+	entry.codeSets[typeptr] = codeSet
+	return codeSet, nil
+}
+
+// marshalFunc returns the function for typ that the opcodes are compiled with.
+// The function is looked up at runtime instead of being kept in the opcode to keep the size of Opcode.
+func (o *Option) marshalFunc(typ *runtime.Type) MarshalFunc {
+	if o.Flag&MarshalFuncOption != 0 {
+		for i := len(o.marshalFuncs) - 1; i >= 0; i-- {
+			if f := o.marshalFuncs[i]; f.Type == typ {
+				return f.Func
+			}
+		}
+	}
+	if fn := loadRegisteredMarshalFuncs()[typ]; fn != nil {
+		return fn
+	}
+	return func(interface{}) ([]byte, error) {
+		return nil, fmt.Errorf("json: marshal function for %s is unregistered", typ)
+	}
+}
+
+// AddMarshalFunc adds f to the functions used by this encoding.
+// The function added later has priority over the previous one for the same type.
+func (o *Option) AddMarshalFunc(f *TypeMarshalFunc) {
+	if o.Flag&MarshalFuncOption == 0 {
+		o.Flag |= MarshalFuncOption
+		o.marshalFuncs = o.marshalFuncs[:0]
+		o.marshalFuncsKey = o.marshalFuncsKey[:0]
+	}
+	for _, added := range o.marshalFuncs {
+		if added == f {
+			return
+		}
+	}
+	o.marshalFuncs = append(o.marshalFuncs, f)
+	o.marshalFuncsKey = insertTypeKey(o.marshalFuncsKey, f.Type)
+}
+
+// insertTypeKey inserts the address of typ into key that has the sorted addresses of the types.
+// The key is the same for the same set of the types regardless of the functions and the order they are added in.
+func insertTypeKey(key []byte, typ *runtime.Type) []byte {
+	const size = int(unsafe.Sizeof(uintptr(0)))
+	addr := uintptr(unsafe.Pointer(typ))
+	idx := len(key)
+	for i := 0; i < len(key); i += size {
+		var v uintptr
+		for j := size - 1; j >= 0; j-- {
+			v = v<<8 | uintptr(key[i+j])
+		}
+		if v == addr {
+			return key
+		}
+		if v > addr {
+			idx = i
+			break
+		}
+	}
+	for i := 0; i < size; i++ {
+		key = append(key, 0)
+	}
+	copy(key[idx+size:], key[idx:])
+	for i := 0; i < size; i++ {
+		key[idx+i] = byte(addr >> (8 * i))
+	}
+	return key
+}
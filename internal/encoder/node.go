@@ -0,0 +1,149 @@
+package encoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/goccy/go-json/internal/dom"
+	"github.com/goccy/go-json/internal/errors"
+)
+
+// maxNodeDepth is the same as the max nesting depth of the decoder.
+// It stops the encoding of the node that is inserted into itself.
+const maxNodeDepth = 10000
+
+var nodeType = reflect.TypeOf(dom.Node{})
+
+// NodeAppender has the functions to append the keys and the scalar values of node.
+// Each VM passes the functions that it uses for the other opcodes, so that the node is colorized by the same scheme.
+type NodeAppender struct {
+	Key    func(*RuntimeContext, []byte, string) []byte
+	String func(*RuntimeContext, []byte, string) []byte
+	Number func(*RuntimeContext, []byte, json.Number) ([]byte, error)
+	Bool   func(*RuntimeContext, []byte, bool) []byte
+	Null   func(*RuntimeContext, []byte) []byte
+}
+
+// AppendNode appends the node of document tree. The members of object are appended in order.
+func AppendNode(ctx *RuntimeContext, app *NodeAppender, b []byte, n *dom.Node) ([]byte, error) {
+	return appendNode(ctx, app, b, n, 0)
+}
+
+func appendNode(ctx *RuntimeContext, app *NodeAppender, b []byte, n *dom.Node, depth int) ([]byte, error) {
+	if depth > maxNodeDepth {
+		return nil, errNodeDepth(n)
+	}
+	switch n.Kind() {
+	case dom.Object:
+		if n.Len() == 0 {
+			return append(b, '{', '}'), nil
+		}
+		b = append(b, '{')
+		for i := 0; i < n.Len(); i++ {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = app.Key(ctx, b, n.Key(i))
+			b = append(b, ':')
+			var err error
+			if b, err = appendNode(ctx, app, b, n.Index(i), depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return append(b, '}'), nil
+	case dom.Array:
+		if n.Len() == 0 {
+			return append(b, '[', ']'), nil
+		}
+		b = append(b, '[')
+		for i := 0; i < n.Len(); i++ {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			var err error
+			if b, err = appendNode(ctx, app, b, n.Index(i), depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return append(b, ']'), nil
+	}
+	return appendNodeScalar(ctx, app, b, n)
+}
+
+// AppendNodeIndent appends the node of document tree with the indentation of the operation.
+func AppendNodeIndent(ctx *RuntimeContext, app *NodeAppender, code *Opcode, b []byte, n *dom.Node) ([]byte, error) {
+	return appendNodeIndent(ctx, app, b, n, code.Indent, 0)
+}
+
+func appendNodeIndent(ctx *RuntimeContext, app *NodeAppender, b []byte, n *dom.Node, indent uint32, depth int) ([]byte, error) {
+	if depth > maxNodeDepth {
+		return nil, errNodeDepth(n)
+	}
+	switch n.Kind() {
+	case dom.Object:
+		if n.Len() == 0 {
+			return append(b, '{', '}'), nil
+		}
+		b = append(b, '{', '\n')
+		for i := 0; i < n.Len(); i++ {
+			if i > 0 {
+				b = append(b, ',', '\n')
+			}
+			b = AppendIndent(ctx, b, indent+1)
+			b = app.Key(ctx, b, n.Key(i))
+			b = append(b, ':', ' ')
+			var err error
+			if b, err = appendNodeIndent(ctx, app, b, n.Index(i), indent+1, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		b = append(b, '\n')
+		b = AppendIndent(ctx, b, indent)
+		return append(b, '}'), nil
+	case dom.Array:
+		if n.Len() == 0 {
+			return append(b, '[', ']'), nil
+		}
+		b = append(b, '[', '\n')
+		for i := 0; i < n.Len(); i++ {
+			if i > 0 {
+				b = append(b, ',', '\n')
+			}
+			b = AppendIndent(ctx, b, indent+1)
+			var err error
+			if b, err = appendNodeIndent(ctx, app, b, n.Index(i), indent+1, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		b = append(b, '\n')
+		b = AppendIndent(ctx, b, indent)
+		return append(b, ']'), nil
+	}
+	return appendNodeScalar(ctx, app, b, n)
+}
+
+func appendNodeScalar(ctx *RuntimeContext, app *NodeAppender, b []byte, n *dom.Node) ([]byte, error) {
+	switch n.Kind() {
+	case dom.Bool:
+		v, _ := n.Bool()
+		return app.Bool(ctx, b, v), nil
+	case dom.Number:
+		v, _ := n.Number()
+		if !dom.IsNumber(string(v)) {
+			return nil, fmt.Errorf("json: invalid number literal %q", v)
+		}
+		return app.Number(ctx, b, json.Number(v))
+	case dom.String:
+		v, _ := n.String()
+		return app.String(ctx, b, v), nil
+	}
+	return app.Null(ctx, b), nil
+}
+
+func errNodeDepth(n *dom.Node) *errors.UnsupportedValueError {
+	return &errors.UnsupportedValueError{
+		Value: reflect.ValueOf(n),
+		Str:   fmt.Sprintf("exceeded max depth %d of node", maxNodeDepth),
+	}
+}
@@ -25,6 +25,9 @@ const (
 	IsNilableTypeFlags     OpFlags = 1 << 7
 	MarshalerContextFlags  OpFlags = 1 << 8
 	NonEmptyInterfaceFlags OpFlags = 1 << 9
+	AppendMarshalerFlags   OpFlags = 1 << 10
+	OmitEmptyFlags         OpFlags = 1 << 11
+	OmitZeroFlags          OpFlags = 1 << 12
 )
 
 type Opcode struct {
@@ -739,6 +742,77 @@ func newMapEndCode(ctx *compileContext, typ *runtime.Type, head *Opcode) *Opcode
 	}
 }
 
+func newInlineMapHeaderCode(ctx *compileContext, typ *runtime.Type) *Opcode {
+	idx := opcodeOffset(ctx.ptrIndex)
+	ctx.incPtrIndex()
+	// ElemIdx keeps the length of the buffer before the map is written.
+	elemIdx := opcodeOffset(ctx.ptrIndex)
+	return &Opcode{
+		Op:         OpInlineMap,
+		Type:       typ,
+		Idx:        idx,
+		ElemIdx:    elemIdx,
+		DisplayIdx: ctx.opcodeIndex,
+		Indent:     ctx.indent,
+	}
+}
+
+func newInlineMapEndCode(ctx *compileContext, typ *runtime.Type, head, mapHead *Opcode) *Opcode {
+	return &Opcode{
+		Op:         OpInlineMapEnd,
+		Type:       typ,
+		Idx:        head.Idx,
+		ElemIdx:    head.ElemIdx,
+		DisplayIdx: ctx.opcodeIndex,
+		Indent:     mapHead.Indent,
+		Next:       newEndOp(ctx, typ),
+	}
+}
+
+func newOrderedMapHeaderCode(ctx *compileContext, typ *runtime.Type) *Opcode {
+	idx := opcodeOffset(ctx.ptrIndex)
+	ctx.incPtrIndex()
+	elemIdx := opcodeOffset(ctx.ptrIndex)
+	ctx.incPtrIndex()
+	length := opcodeOffset(ctx.ptrIndex)
+	return &Opcode{
+		Op:         OpOrderedMap,
+		Type:       typ,
+		Idx:        idx,
+		DisplayIdx: ctx.opcodeIndex,
+		ElemIdx:    elemIdx,
+		Length:     length,
+		Indent:     ctx.indent,
+	}
+}
+
+func newOrderedMapKeyCode(ctx *compileContext, typ *runtime.Type, head *Opcode, size uintptr) *Opcode {
+	return &Opcode{
+		Op:         OpOrderedMapKey,
+		Type:       typ,
+		Idx:        head.Idx,
+		DisplayIdx: ctx.opcodeIndex,
+		ElemIdx:    head.ElemIdx,
+		Length:     head.Length,
+		Indent:     ctx.indent,
+		Size:       uint32(size),
+	}
+}
+
+func newOrderedMapValueCode(ctx *compileContext, typ *runtime.Type, head *Opcode, size, offset uintptr) *Opcode {
+	return &Opcode{
+		Op:         OpOrderedMapValue,
+		Type:       typ,
+		Idx:        head.Idx,
+		DisplayIdx: ctx.opcodeIndex,
+		ElemIdx:    head.ElemIdx,
+		Length:     head.Length,
+		Indent:     ctx.indent,
+		Size:       uint32(size),
+		Offset:     uint32(offset),
+	}
+}
+
 func newRecursiveCode(ctx *compileContext, typ *runtime.Type, jmp *CompiledCode) *Opcode {
 	return &Opcode{
 		Op:         OpRecursive,
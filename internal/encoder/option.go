@@ -5,7 +5,7 @@ import (
 	"io"
 )
 
-type OptionFlag uint8
+type OptionFlag uint16
 
 const (
 	HTMLEscapeOption OptionFlag = 1 << iota
@@ -16,6 +16,8 @@ const (
 	ContextOption
 	NormalizeUTF8Option
 	FieldQueryOption
+	MarshalFuncOption
+	ValidateAppendJSONOption
 )
 
 type Option struct {
@@ -24,6 +26,9 @@ type Option struct {
 	Context     context.Context
 	DebugOut    io.Writer
 	DebugDOTOut io.WriteCloser
+
+	marshalFuncs    []*TypeMarshalFunc // functions added by AddMarshalFunc
+	marshalFuncsKey []byte             // sorted addresses of the types of marshalFuncs to look up the cached opcodes
 }
 
 type EncodeFormat struct {
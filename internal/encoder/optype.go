@@ -22,7 +22,7 @@ const (
 	CodeStructEnd   CodeType = 11
 )
 
-var opTypeStrings = [400]string{
+var opTypeStrings = [424]string{
 	"End",
 	"Interface",
 	"Ptr",
@@ -423,6 +423,30 @@ var opTypeStrings = [400]string{
 	"StructFieldOmitEmpty",
 	"StructEnd",
 	"StructEndOmitEmpty",
+	"StructHeadOmitZero",
+	"StructFieldOmitZero",
+	"StructPtrHeadOmitZero",
+	"Node",
+	"NodePtr",
+	"OrderedMap",
+	"OrderedMapPtr",
+	"OrderedMapKey",
+	"OrderedMapValue",
+	"OrderedMapEnd",
+	"InlineMap",
+	"InlineMapEnd",
+	"TimeUnix",
+	"TimeUnixPtr",
+	"TimeUnixMilli",
+	"TimeUnixMilliPtr",
+	"BytesHex",
+	"BytesHexPtr",
+	"BytesBase64URL",
+	"BytesBase64URLPtr",
+	"DurationUnits",
+	"DurationUnitsPtr",
+	"MarshalFunc",
+	"MarshalFuncPtr",
 }
 
 type OpType uint16
@@ -828,10 +852,34 @@ const (
 	OpStructFieldOmitEmpty                   OpType = 397
 	OpStructEnd                              OpType = 398
 	OpStructEndOmitEmpty                     OpType = 399
+	OpStructHeadOmitZero                     OpType = 400
+	OpStructFieldOmitZero                    OpType = 401
+	OpStructPtrHeadOmitZero                  OpType = 402
+	OpNode                                   OpType = 403
+	OpNodePtr                                OpType = 404
+	OpOrderedMap                             OpType = 405
+	OpOrderedMapPtr                          OpType = 406
+	OpOrderedMapKey                          OpType = 407
+	OpOrderedMapValue                        OpType = 408
+	OpOrderedMapEnd                          OpType = 409
+	OpInlineMap                              OpType = 410
+	OpInlineMapEnd                           OpType = 411
+	OpTimeUnix                               OpType = 412
+	OpTimeUnixPtr                            OpType = 413
+	OpTimeUnixMilli                          OpType = 414
+	OpTimeUnixMilliPtr                       OpType = 415
+	OpBytesHex                               OpType = 416
+	OpBytesHexPtr                            OpType = 417
+	OpBytesBase64URL                         OpType = 418
+	OpBytesBase64URLPtr                      OpType = 419
+	OpDurationUnits                          OpType = 420
+	OpDurationUnitsPtr                       OpType = 421
+	OpMarshalFunc                            OpType = 422
+	OpMarshalFuncPtr                         OpType = 423
 )
 
 func (t OpType) String() string {
-	if int(t) >= 400 {
+	if int(t) >= 424 {
 		return ""
 	}
 	return opTypeStrings[int(t)]
@@ -861,6 +909,14 @@ func (t OpType) CodeType() CodeType {
 		return CodeMapValue
 	case OpMapEnd:
 		return CodeMapEnd
+	case OpOrderedMap, OpOrderedMapPtr:
+		return CodeSliceHead
+	case OpOrderedMapKey:
+		return CodeSliceElem
+	case OpOrderedMapValue:
+		return CodeMapValue
+	case OpOrderedMapEnd:
+		return CodeMapEnd
 	}
 
 	return CodeOp
@@ -26,8 +26,6 @@ package encoder
 
 import (
 	"math/bits"
-	"reflect"
-	"unsafe"
 )
 
 const (
@@ -37,13 +35,97 @@ const (
 
 var hex = "0123456789abcdef"
 
-//nolint:govet
-func stringToUint64Slice(s string) []uint64 {
-	return *(*[]uint64)(unsafe.Pointer(&reflect.SliceHeader{
-		Data: ((*reflect.StringHeader)(unsafe.Pointer(&s))).Data,
-		Len:  len(s) / 8,
-		Cap:  len(s) / 8,
-	}))
+// load64 loads the 8 bytes from s[j:] as little endian regardless of the platform,
+// so the lowest byte of the result is s[j].
+func load64(s string, j int) uint64 {
+	_ = s[j+7]
+	return uint64(s[j]) | uint64(s[j+1])<<8 | uint64(s[j+2])<<16 | uint64(s[j+3])<<24 |
+		uint64(s[j+4])<<32 | uint64(s[j+5])<<40 | uint64(s[j+6])<<48 | uint64(s[j+7])<<56
+}
+
+// hasLess sets the MSB of each byte of n that is less than c ( c must be 0x80 or less ).
+// The borrow may set the MSB of the byte above the matched byte, but never the MSB of the lowest matched byte,
+// so the lowest set bit always points to the first matched byte.
+func hasLess(n uint64, c byte) uint64 {
+	return (n - lsb*uint64(c)) &^ n & msb
+}
+
+// hasByte sets the MSB of each byte of n that is equal to c.
+func hasByte(n uint64, c byte) uint64 {
+	return hasLess(n^(lsb*uint64(c)), 1)
+}
+
+func escapeMask(n uint64) uint64 {
+	return hasLess(n, 0x20) | hasByte(n, '"') | hasByte(n, '\\')
+}
+
+func escapeHTMLMask(n uint64) uint64 {
+	return escapeMask(n) | hasByte(n, '<') | hasByte(n, '>') | hasByte(n, '&')
+}
+
+// indexEscape returns the index of the first byte in s[j:] that needs to be escaped, or len(s) if there is none.
+// It checks 8 bytes at a time, and the rest of them by needEscape table.
+func indexEscape(s string, j int) int {
+	for ; j+8 <= len(s); j += 8 {
+		if mask := escapeMask(load64(s, j)); mask != 0 {
+			return j + bits.TrailingZeros64(mask)/8
+		}
+	}
+	for ; j < len(s); j++ {
+		if needEscape[s[j]] {
+			return j
+		}
+	}
+	return j
+}
+
+// indexEscapeHTML is the same as indexEscape, but it also finds '<', '>' and '&'.
+func indexEscapeHTML(s string, j int) int {
+	for ; j+8 <= len(s); j += 8 {
+		if mask := escapeHTMLMask(load64(s, j)); mask != 0 {
+			return j + bits.TrailingZeros64(mask)/8
+		}
+	}
+	for ; j < len(s); j++ {
+		if needEscapeHTML[s[j]] {
+			return j
+		}
+	}
+	return j
+}
+
+// indexEscapeNormalizeUTF8 is the same as indexEscape, but it also finds the bytes outside the ASCII range
+// to validate the multi bytes characters.
+func indexEscapeNormalizeUTF8(s string, j int) int {
+	for ; j+8 <= len(s); j += 8 {
+		n := load64(s, j)
+		if mask := escapeMask(n) | n&msb; mask != 0 {
+			return j + bits.TrailingZeros64(mask)/8
+		}
+	}
+	for ; j < len(s); j++ {
+		if needEscapeNormalizeUTF8[s[j]] {
+			return j
+		}
+	}
+	return j
+}
+
+// indexEscapeHTMLNormalizeUTF8 is the same as indexEscapeHTML, but it also finds the bytes outside the ASCII range
+// to validate the multi bytes characters.
+func indexEscapeHTMLNormalizeUTF8(s string, j int) int {
+	for ; j+8 <= len(s); j += 8 {
+		n := load64(s, j)
+		if mask := escapeHTMLMask(n) | n&msb; mask != 0 {
+			return j + bits.TrailingZeros64(mask)/8
+		}
+	}
+	for ; j < len(s); j++ {
+		if needEscapeHTMLNormalizeUTF8[s[j]] {
+			return j
+		}
+	}
+	return j
 }
 
 func AppendString(ctx *RuntimeContext, buf []byte, s string) []byte {
@@ -65,42 +147,17 @@ func appendNormalizedHTMLString(buf []byte, s string) []byte {
 		return append(buf, `""`...)
 	}
 	buf = append(buf, '"')
-	var (
-		i, j int
-	)
-	if valLen >= 8 {
-		chunks := stringToUint64Slice(s)
-		for _, n := range chunks {
-			// combine masks before checking for the MSB of each byte. We include
-			// `n` in the mask to check whether any of the *input* byte MSBs were
-			// set (i.e. the byte was outside the ASCII range).
-			mask := n | (n - (lsb * 0x20)) |
-				((n ^ (lsb * '"')) - lsb) |
-				((n ^ (lsb * '\\')) - lsb) |
-				((n ^ (lsb * '<')) - lsb) |
-				((n ^ (lsb * '>')) - lsb) |
-				((n ^ (lsb * '&')) - lsb)
-			if (mask & msb) != 0 {
-				j = bits.TrailingZeros64(mask&msb) / 8
-				goto ESCAPE_END
-			}
-		}
-		for i := len(chunks) * 8; i < valLen; i++ {
-			if needEscapeHTMLNormalizeUTF8[s[i]] {
-				j = i
-				goto ESCAPE_END
-			}
-		}
+	i, j := 0, indexEscapeHTMLNormalizeUTF8(s, 0)
+	if j == valLen {
 		// no found any escape characters.
 		return append(append(buf, s...), '"')
 	}
-ESCAPE_END:
 	for j < valLen {
 		c := s[j]
 
 		if !needEscapeHTMLNormalizeUTF8[c] {
-			// fast path: most of the time, printable ascii characters are used
-			j++
+			// fast path: skip the bytes that don't need to be escaped by 8 bytes at a time
+			j = indexEscapeHTMLNormalizeUTF8(s, j+1)
 			continue
 		}
 
@@ -190,42 +247,17 @@ func appendHTMLString(buf []byte, s string) []byte {
 		return append(buf, `""`...)
 	}
 	buf = append(buf, '"')
-	var (
-		i, j int
-	)
-	if valLen >= 8 {
-		chunks := stringToUint64Slice(s)
-		for _, n := range chunks {
-			// combine masks before checking for the MSB of each byte. We include
-			// `n` in the mask to check whether any of the *input* byte MSBs were
-			// set (i.e. the byte was outside the ASCII range).
-			mask := n | (n - (lsb * 0x20)) |
-				((n ^ (lsb * '"')) - lsb) |
-				((n ^ (lsb * '\\')) - lsb) |
-				((n ^ (lsb * '<')) - lsb) |
-				((n ^ (lsb * '>')) - lsb) |
-				((n ^ (lsb * '&')) - lsb)
-			if (mask & msb) != 0 {
-				j = bits.TrailingZeros64(mask&msb) / 8
-				goto ESCAPE_END
-			}
-		}
-		for i := len(chunks) * 8; i < valLen; i++ {
-			if needEscapeHTML[s[i]] {
-				j = i
-				goto ESCAPE_END
-			}
-		}
+	i, j := 0, indexEscapeHTML(s, 0)
+	if j == valLen {
 		// no found any escape characters.
 		return append(append(buf, s...), '"')
 	}
-ESCAPE_END:
 	for j < valLen {
 		c := s[j]
 
 		if !needEscapeHTML[c] {
-			// fast path: most of the time, printable ascii characters are used
-			j++
+			// fast path: skip the bytes that don't need to be escaped by 8 bytes at a time
+			j = indexEscapeHTML(s, j+1)
 			continue
 		}
 
@@ -287,39 +319,17 @@ func appendNormalizedString(buf []byte, s string) []byte {
 		return append(buf, `""`...)
 	}
 	buf = append(buf, '"')
-	var (
-		i, j int
-	)
-	if valLen >= 8 {
-		chunks := stringToUint64Slice(s)
-		for _, n := range chunks {
-			// combine masks before checking for the MSB of each byte. We include
-			// `n` in the mask to check whether any of the *input* byte MSBs were
-			// set (i.e. the byte was outside the ASCII range).
-			mask := n | (n - (lsb * 0x20)) |
-				((n ^ (lsb * '"')) - lsb) |
-				((n ^ (lsb * '\\')) - lsb)
-			if (mask & msb) != 0 {
-				j = bits.TrailingZeros64(mask&msb) / 8
-				goto ESCAPE_END
-			}
-		}
-		valLen := len(s)
-		for i := len(chunks) * 8; i < valLen; i++ {
-			if needEscapeNormalizeUTF8[s[i]] {
-				j = i
-				goto ESCAPE_END
-			}
-		}
+	i, j := 0, indexEscapeNormalizeUTF8(s, 0)
+	if j == valLen {
+		// no found any escape characters.
 		return append(append(buf, s...), '"')
 	}
-ESCAPE_END:
 	for j < valLen {
 		c := s[j]
 
 		if !needEscapeNormalizeUTF8[c] {
-			// fast path: most of the time, printable ascii characters are used
-			j++
+			// fast path: skip the bytes that don't need to be escaped by 8 bytes at a time
+			j = indexEscapeNormalizeUTF8(s, j+1)
 			continue
 		}
 
@@ -402,39 +412,17 @@ func appendString(buf []byte, s string) []byte {
 		return append(buf, `""`...)
 	}
 	buf = append(buf, '"')
-	var (
-		i, j int
-	)
-	if valLen >= 8 {
-		chunks := stringToUint64Slice(s)
-		for _, n := range chunks {
-			// combine masks before checking for the MSB of each byte. We include
-			// `n` in the mask to check whether any of the *input* byte MSBs were
-			// set (i.e. the byte was outside the ASCII range).
-			mask := n | (n - (lsb * 0x20)) |
-				((n ^ (lsb * '"')) - lsb) |
-				((n ^ (lsb * '\\')) - lsb)
-			if (mask & msb) != 0 {
-				j = bits.TrailingZeros64(mask&msb) / 8
-				goto ESCAPE_END
-			}
-		}
-		valLen := len(s)
-		for i := len(chunks) * 8; i < valLen; i++ {
-			if needEscape[s[i]] {
-				j = i
-				goto ESCAPE_END
-			}
-		}
+	i, j := 0, indexEscape(s, 0)
+	if j == valLen {
+		// no found any escape characters.
 		return append(append(buf, s...), '"')
 	}
-ESCAPE_END:
 	for j < valLen {
 		c := s[j]
 
 		if !needEscape[c] {
-			// fast path: most of the time, printable ascii characters are used
-			j++
+			// fast path: skip the bytes that don't need to be escaped by 8 bytes at a time
+			j = indexEscape(s, j+1)
 			continue
 		}
 
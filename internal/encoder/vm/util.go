@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"unsafe"
 
+	"github.com/goccy/go-json/internal/dom"
 	"github.com/goccy/go-json/internal/encoder"
 	"github.com/goccy/go-json/internal/runtime"
 )
@@ -12,20 +13,25 @@ import (
 const uintptrSize = 4 << (^uintptr(0) >> 63)
 
 var (
-	appendInt           = encoder.AppendInt
-	appendUint          = encoder.AppendUint
-	appendFloat32       = encoder.AppendFloat32
-	appendFloat64       = encoder.AppendFloat64
-	appendString        = encoder.AppendString
-	appendByteSlice     = encoder.AppendByteSlice
-	appendNumber        = encoder.AppendNumber
-	errUnsupportedValue = encoder.ErrUnsupportedValue
-	errUnsupportedFloat = encoder.ErrUnsupportedFloat
-	mapiterinit         = encoder.MapIterInit
-	mapiterkey          = encoder.MapIterKey
-	mapitervalue        = encoder.MapIterValue
-	mapiternext         = encoder.MapIterNext
-	maplen              = encoder.MapLen
+	appendInt            = encoder.AppendInt
+	appendUint           = encoder.AppendUint
+	appendFloat32        = encoder.AppendFloat32
+	appendFloat64        = encoder.AppendFloat64
+	appendString         = encoder.AppendString
+	appendByteSlice      = encoder.AppendByteSlice
+	appendTimeUnix       = encoder.AppendTimeUnix
+	appendTimeUnixMilli  = encoder.AppendTimeUnixMilli
+	appendBytesHex       = encoder.AppendBytesHex
+	appendBytesBase64URL = encoder.AppendBytesBase64URL
+	appendDurationUnits  = encoder.AppendDurationUnits
+	appendNumber         = encoder.AppendNumber
+	errUnsupportedValue  = encoder.ErrUnsupportedValue
+	errUnsupportedFloat  = encoder.ErrUnsupportedFloat
+	mapiterinit          = encoder.MapIterInit
+	mapiterkey           = encoder.MapIterKey
+	mapitervalue         = encoder.MapIterValue
+	mapiternext          = encoder.MapIterNext
+	maplen               = encoder.MapLen
 )
 
 type emptyInterface struct {
@@ -111,6 +117,10 @@ func ptrToInterface(code *encoder.Opcode, p uintptr) interface{} {
 	}))
 }
 
+func ptrToNode(p uintptr) *dom.Node {
+	return *(**dom.Node)(unsafe.Pointer(&p))
+}
+
 func appendBool(_ *encoder.RuntimeContext, b []byte, v bool) []byte {
 	if v {
 		return append(b, "true"...)
@@ -152,6 +162,22 @@ func appendMarshalJSON(ctx *encoder.RuntimeContext, code *encoder.Opcode, b []by
 	return encoder.AppendMarshalJSON(ctx, code, b, v)
 }
 
+func appendMarshalFunc(ctx *encoder.RuntimeContext, code *encoder.Opcode, b []byte, v interface{}) ([]byte, error) {
+	return encoder.AppendMarshalFunc(ctx, code, b, v)
+}
+
+var nodeAppender = &encoder.NodeAppender{
+	Key:    appendString,
+	String: appendString,
+	Number: appendNumber,
+	Bool:   appendBool,
+	Null:   appendNull,
+}
+
+func appendNode(ctx *encoder.RuntimeContext, _ *encoder.Opcode, b []byte, n *dom.Node) ([]byte, error) {
+	return encoder.AppendNode(ctx, nodeAppender, b, n)
+}
+
 func appendMarshalText(ctx *encoder.RuntimeContext, code *encoder.Opcode, b []byte, v interface{}) ([]byte, error) {
 	return encoder.AppendMarshalText(ctx, code, b, v)
 }
@@ -180,6 +206,14 @@ func appendObjectEnd(_ *encoder.RuntimeContext, _ *encoder.Opcode, b []byte) []b
 	return append(b, ',')
 }
 
+// appendInlineObjectEnd removes the braces of the object written from start,
+// so that the members of the object are written as the members of the enclosing object.
+func appendInlineObjectEnd(_ *encoder.RuntimeContext, _ *encoder.Opcode, b []byte, start int) []byte {
+	// {<members>}, => <members>,
+	n := copy(b[start:], b[start+1:len(b)-2])
+	return append(b[:start+n], ',')
+}
+
 func appendStructHead(_ *encoder.RuntimeContext, b []byte) []byte {
 	return append(b, '{')
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"unsafe"
 
+	"github.com/goccy/go-json/internal/dom"
 	"github.com/goccy/go-json/internal/encoder"
 	"github.com/goccy/go-json/internal/runtime"
 )
@@ -104,6 +105,10 @@ func ptrToInterface(code *encoder.Opcode, p uintptr) interface{} {
 	}))
 }
 
+func ptrToNode(p uintptr) *dom.Node {
+	return *(**dom.Node)(unsafe.Pointer(&p))
+}
+
 func appendInt(ctx *encoder.RuntimeContext, b []byte, p uintptr, code *encoder.Opcode) []byte {
 	format := ctx.Option.ColorScheme.Int
 	b = append(b, format.Header...)
@@ -146,6 +151,41 @@ func appendByteSlice(ctx *encoder.RuntimeContext, b []byte, src []byte) []byte {
 	return append(b, format.Footer...)
 }
 
+func appendTimeUnix(ctx *encoder.RuntimeContext, b []byte, p uintptr) []byte {
+	format := ctx.Option.ColorScheme.Int
+	b = append(b, format.Header...)
+	b = encoder.AppendTimeUnix(ctx, b, p)
+	return append(b, format.Footer...)
+}
+
+func appendTimeUnixMilli(ctx *encoder.RuntimeContext, b []byte, p uintptr) []byte {
+	format := ctx.Option.ColorScheme.Int
+	b = append(b, format.Header...)
+	b = encoder.AppendTimeUnixMilli(ctx, b, p)
+	return append(b, format.Footer...)
+}
+
+func appendBytesHex(ctx *encoder.RuntimeContext, b []byte, p uintptr) []byte {
+	format := ctx.Option.ColorScheme.Binary
+	b = append(b, format.Header...)
+	b = encoder.AppendBytesHex(ctx, b, p)
+	return append(b, format.Footer...)
+}
+
+func appendBytesBase64URL(ctx *encoder.RuntimeContext, b []byte, p uintptr) []byte {
+	format := ctx.Option.ColorScheme.Binary
+	b = append(b, format.Header...)
+	b = encoder.AppendBytesBase64URL(ctx, b, p)
+	return append(b, format.Footer...)
+}
+
+func appendDurationUnits(ctx *encoder.RuntimeContext, b []byte, p uintptr) []byte {
+	format := ctx.Option.ColorScheme.String
+	b = append(b, format.Header...)
+	b = encoder.AppendDurationUnits(ctx, b, p)
+	return append(b, format.Footer...)
+}
+
 func appendNumber(ctx *encoder.RuntimeContext, b []byte, n json.Number) ([]byte, error) {
 	format := ctx.Option.ColorScheme.Int
 	b = append(b, format.Header...)
@@ -208,6 +248,29 @@ func appendMarshalJSON(ctx *encoder.RuntimeContext, code *encoder.Opcode, b []by
 	return encoder.AppendMarshalJSON(ctx, code, b, v)
 }
 
+func appendMarshalFunc(ctx *encoder.RuntimeContext, code *encoder.Opcode, b []byte, v interface{}) ([]byte, error) {
+	return encoder.AppendMarshalFunc(ctx, code, b, v)
+}
+
+var nodeAppender = &encoder.NodeAppender{
+	Key:    appendNodeKey,
+	String: appendString,
+	Number: appendNumber,
+	Bool:   appendBool,
+	Null:   appendNull,
+}
+
+func appendNodeKey(ctx *encoder.RuntimeContext, b []byte, key string) []byte {
+	format := ctx.Option.ColorScheme.ObjectKey
+	b = append(b, format.Header...)
+	b = encoder.AppendString(ctx, b, key)
+	return append(b, format.Footer...)
+}
+
+func appendNode(ctx *encoder.RuntimeContext, _ *encoder.Opcode, b []byte, n *dom.Node) ([]byte, error) {
+	return encoder.AppendNode(ctx, nodeAppender, b, n)
+}
+
 func appendMarshalText(ctx *encoder.RuntimeContext, code *encoder.Opcode, b []byte, v interface{}) ([]byte, error) {
 	format := ctx.Option.ColorScheme.String
 	b = append(b, format.Header...)
@@ -242,6 +305,14 @@ func appendObjectEnd(_ *encoder.RuntimeContext, _ *encoder.Opcode, b []byte) []b
 	return append(b, ',')
 }
 
+// appendInlineObjectEnd removes the braces of the object written from start,
+// so that the members of the object are written as the members of the enclosing object.
+func appendInlineObjectEnd(_ *encoder.RuntimeContext, _ *encoder.Opcode, b []byte, start int) []byte {
+	// {<members>}, => <members>,
+	n := copy(b[start:], b[start+1:len(b)-2])
+	return append(b[:start+n], ',')
+}
+
 func appendStructHead(_ *encoder.RuntimeContext, b []byte) []byte {
 	return append(b, '{')
 }
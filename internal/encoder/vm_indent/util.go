@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"unsafe"
 
+	"github.com/goccy/go-json/internal/dom"
 	"github.com/goccy/go-json/internal/encoder"
 	"github.com/goccy/go-json/internal/runtime"
 )
@@ -12,22 +13,27 @@ import (
 const uintptrSize = 4 << (^uintptr(0) >> 63)
 
 var (
-	appendInt           = encoder.AppendInt
-	appendUint          = encoder.AppendUint
-	appendFloat32       = encoder.AppendFloat32
-	appendFloat64       = encoder.AppendFloat64
-	appendString        = encoder.AppendString
-	appendByteSlice     = encoder.AppendByteSlice
-	appendNumber        = encoder.AppendNumber
-	appendStructEnd     = encoder.AppendStructEndIndent
-	appendIndent        = encoder.AppendIndent
-	errUnsupportedValue = encoder.ErrUnsupportedValue
-	errUnsupportedFloat = encoder.ErrUnsupportedFloat
-	mapiterinit         = encoder.MapIterInit
-	mapiterkey          = encoder.MapIterKey
-	mapitervalue        = encoder.MapIterValue
-	mapiternext         = encoder.MapIterNext
-	maplen              = encoder.MapLen
+	appendInt            = encoder.AppendInt
+	appendUint           = encoder.AppendUint
+	appendFloat32        = encoder.AppendFloat32
+	appendFloat64        = encoder.AppendFloat64
+	appendString         = encoder.AppendString
+	appendByteSlice      = encoder.AppendByteSlice
+	appendTimeUnix       = encoder.AppendTimeUnix
+	appendTimeUnixMilli  = encoder.AppendTimeUnixMilli
+	appendBytesHex       = encoder.AppendBytesHex
+	appendBytesBase64URL = encoder.AppendBytesBase64URL
+	appendDurationUnits  = encoder.AppendDurationUnits
+	appendNumber         = encoder.AppendNumber
+	appendStructEnd      = encoder.AppendStructEndIndent
+	appendIndent         = encoder.AppendIndent
+	errUnsupportedValue  = encoder.ErrUnsupportedValue
+	errUnsupportedFloat  = encoder.ErrUnsupportedFloat
+	mapiterinit          = encoder.MapIterInit
+	mapiterkey           = encoder.MapIterKey
+	mapitervalue         = encoder.MapIterValue
+	mapiternext          = encoder.MapIterNext
+	maplen               = encoder.MapLen
 )
 
 type emptyInterface struct {
@@ -113,6 +119,10 @@ func ptrToInterface(code *encoder.Opcode, p uintptr) interface{} {
 	}))
 }
 
+func ptrToNode(p uintptr) *dom.Node {
+	return *(**dom.Node)(unsafe.Pointer(&p))
+}
+
 func appendBool(_ *encoder.RuntimeContext, b []byte, v bool) []byte {
 	if v {
 		return append(b, "true"...)
@@ -183,10 +193,36 @@ func appendMarshalJSON(ctx *encoder.RuntimeContext, code *encoder.Opcode, b []by
 	return encoder.AppendMarshalJSONIndent(ctx, code, b, v)
 }
 
+func appendMarshalFunc(ctx *encoder.RuntimeContext, code *encoder.Opcode, b []byte, v interface{}) ([]byte, error) {
+	return encoder.AppendMarshalFuncIndent(ctx, code, b, v)
+}
+
+var nodeAppender = &encoder.NodeAppender{
+	Key:    appendString,
+	String: appendString,
+	Number: appendNumber,
+	Bool:   appendBool,
+	Null:   appendNull,
+}
+
+func appendNode(ctx *encoder.RuntimeContext, code *encoder.Opcode, b []byte, n *dom.Node) ([]byte, error) {
+	return encoder.AppendNodeIndent(ctx, nodeAppender, code, b, n)
+}
+
 func appendMarshalText(ctx *encoder.RuntimeContext, code *encoder.Opcode, b []byte, v interface{}) ([]byte, error) {
 	return encoder.AppendMarshalTextIndent(ctx, code, b, v)
 }
 
+// appendInlineObjectEnd removes the braces of the object written from start,
+// so that the members of the object are written as the members of the enclosing object.
+func appendInlineObjectEnd(ctx *encoder.RuntimeContext, code *encoder.Opcode, b []byte, start int) []byte {
+	// {\n<members>\n<indent>},\n => <members>,\n
+	indentLen := len(ctx.Prefix) + len(ctx.IndentStr)*int(ctx.BaseIndent+code.Indent)
+	end := len(b) - len("},\n") - indentLen - 1
+	n := copy(b[start:], b[start+2:end])
+	return append(b[:start+n], ',', '\n')
+}
+
 func appendStructHead(_ *encoder.RuntimeContext, b []byte) []byte {
 	return append(b, '{', '\n')
 }
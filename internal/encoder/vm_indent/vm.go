@@ -138,6 +138,125 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			b = appendByteSlice(ctx, b, ptrToBytes(load(ctxptr, code.Idx)))
 			b = appendComma(ctx, b)
 			code = code.Next
+		case encoder.OpTimeUnixPtr:
+			p := load(ctxptr, code.Idx)
+			if p != 0 && (code.Flags&encoder.IndirectFlags) != 0 {
+				// the struct that has only the pointer field passes the pointer itself.
+				p = ptrToPtr(p)
+			}
+			if p == 0 {
+				b = appendNullComma(ctx, b)
+				code = code.Next
+				break
+			}
+			store(ctxptr, code.Idx, p)
+			fallthrough
+		case encoder.OpTimeUnix:
+			b = appendTimeUnix(ctx, b, load(ctxptr, code.Idx))
+			b = appendComma(ctx, b)
+			code = code.Next
+		case encoder.OpTimeUnixMilliPtr:
+			p := load(ctxptr, code.Idx)
+			if p != 0 && (code.Flags&encoder.IndirectFlags) != 0 {
+				// the struct that has only the pointer field passes the pointer itself.
+				p = ptrToPtr(p)
+			}
+			if p == 0 {
+				b = appendNullComma(ctx, b)
+				code = code.Next
+				break
+			}
+			store(ctxptr, code.Idx, p)
+			fallthrough
+		case encoder.OpTimeUnixMilli:
+			b = appendTimeUnixMilli(ctx, b, load(ctxptr, code.Idx))
+			b = appendComma(ctx, b)
+			code = code.Next
+		case encoder.OpBytesHexPtr:
+			p := load(ctxptr, code.Idx)
+			if p != 0 && (code.Flags&encoder.IndirectFlags) != 0 {
+				// the struct that has only the pointer field passes the pointer itself.
+				p = ptrToPtr(p)
+			}
+			if p == 0 {
+				b = appendNullComma(ctx, b)
+				code = code.Next
+				break
+			}
+			store(ctxptr, code.Idx, p)
+			fallthrough
+		case encoder.OpBytesHex:
+			b = appendBytesHex(ctx, b, load(ctxptr, code.Idx))
+			b = appendComma(ctx, b)
+			code = code.Next
+		case encoder.OpBytesBase64URLPtr:
+			p := load(ctxptr, code.Idx)
+			if p != 0 && (code.Flags&encoder.IndirectFlags) != 0 {
+				// the struct that has only the pointer field passes the pointer itself.
+				p = ptrToPtr(p)
+			}
+			if p == 0 {
+				b = appendNullComma(ctx, b)
+				code = code.Next
+				break
+			}
+			store(ctxptr, code.Idx, p)
+			fallthrough
+		case encoder.OpBytesBase64URL:
+			b = appendBytesBase64URL(ctx, b, load(ctxptr, code.Idx))
+			b = appendComma(ctx, b)
+			code = code.Next
+		case encoder.OpDurationUnitsPtr:
+			p := load(ctxptr, code.Idx)
+			if p != 0 && (code.Flags&encoder.IndirectFlags) != 0 {
+				// the struct that has only the pointer field passes the pointer itself.
+				p = ptrToPtr(p)
+			}
+			if p == 0 {
+				b = appendNullComma(ctx, b)
+				code = code.Next
+				break
+			}
+			store(ctxptr, code.Idx, p)
+			fallthrough
+		case encoder.OpDurationUnits:
+			b = appendDurationUnits(ctx, b, load(ctxptr, code.Idx))
+			b = appendComma(ctx, b)
+			code = code.Next
+		case encoder.OpMarshalFuncPtr:
+			p := load(ctxptr, code.Idx)
+			if p != 0 && (code.Flags&encoder.IndirectFlags) != 0 {
+				// the struct that has only the pointer field passes the pointer itself.
+				p = ptrToPtr(p)
+			}
+			if code.PtrNum > 1 {
+				p = ptrToNPtr(p, code.PtrNum-1)
+			}
+			if p == 0 {
+				b = appendNullComma(ctx, b)
+				code = code.Next
+				break
+			}
+			if (code.Flags & encoder.IsNilableTypeFlags) != 0 {
+				p = ptrToPtr(p)
+			}
+			bb, err := appendMarshalFunc(ctx, code, b, ptrToInterface(code, p))
+			if err != nil {
+				return nil, err
+			}
+			b = appendComma(ctx, bb)
+			code = code.Next
+		case encoder.OpMarshalFunc:
+			p := load(ctxptr, code.Idx)
+			if (code.Flags&encoder.IsNilableTypeFlags) != 0 && (code.Flags&encoder.IndirectFlags) != 0 {
+				p = ptrToPtr(p)
+			}
+			bb, err := appendMarshalFunc(ctx, code, b, ptrToInterface(code, p))
+			if err != nil {
+				return nil, err
+			}
+			b = appendComma(ctx, bb)
+			code = code.Next
 		case encoder.OpNumberPtr:
 			p := loadNPtr(ctxptr, code.Idx, code.PtrNum)
 			if p == 0 {
@@ -274,6 +393,23 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			}
 			b = appendComma(ctx, bb)
 			code = code.Next
+		case encoder.OpNodePtr:
+			p := loadNPtr(ctxptr, code.Idx, code.PtrNum)
+			if p == 0 {
+				b = appendNullComma(ctx, b)
+				code = code.Next
+				break
+			}
+			store(ctxptr, code.Idx, p)
+			fallthrough
+		case encoder.OpNode:
+			p := load(ctxptr, code.Idx)
+			bb, err := appendNode(ctx, code, b, ptrToNode(p))
+			if err != nil {
+				return nil, err
+			}
+			b = appendComma(ctx, bb)
+			code = code.Next
 		case encoder.OpMarshalTextPtr:
 			p := load(ctxptr, code.Idx)
 			if p == 0 {
@@ -333,6 +469,13 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			length := load(ctxptr, code.Length)
 			idx++
 			if idx < length {
+				if ctx.FlushThreshold > 0 && len(b) >= ctx.FlushThreshold {
+					fb, err := ctx.Flush(b)
+					if err != nil {
+						return nil, err
+					}
+					b = fb
+				}
 				b = appendArrayElemIndent(ctx, code, b)
 				store(ctxptr, code.ElemIdx, idx)
 				data := load(ctxptr, code.Idx)
@@ -372,6 +515,13 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			idx := load(ctxptr, code.ElemIdx)
 			idx++
 			if idx < uintptr(code.Length) {
+				if ctx.FlushThreshold > 0 && len(b) >= ctx.FlushThreshold {
+					fb, err := ctx.Flush(b)
+					if err != nil {
+						return nil, err
+					}
+					b = fb
+				}
 				b = appendArrayElemIndent(ctx, code, b)
 				store(ctxptr, code.ElemIdx, idx)
 				p := load(ctxptr, code.Idx)
@@ -414,6 +564,8 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if unorderedMap {
 				b = appendMapKeyIndent(ctx, code.Next, b)
 			} else {
+				// the entries refer to the buffer until they are sorted at OpMapEnd.
+				ctx.IncFlushBarrier()
 				mapCtx.Start = len(b)
 				mapCtx.First = len(b)
 			}
@@ -426,6 +578,13 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			idx++
 			if (ctx.Option.Flag & encoder.UnorderedMapOption) != 0 {
 				if idx < mapCtx.Len {
+					if ctx.FlushThreshold > 0 && len(b) >= ctx.FlushThreshold {
+						fb, err := ctx.Flush(b)
+						if err != nil {
+							return nil, err
+						}
+						b = fb
+					}
 					b = appendMapKeyIndent(ctx, code, b)
 					mapCtx.Idx = int(idx)
 					key := mapiterkey(&mapCtx.Iter)
@@ -473,6 +632,86 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			b = append(b, buf...)
 			mapCtx.Buf = buf
 			encoder.ReleaseMapContext(mapCtx)
+			ctx.DecFlushBarrier()
+			code = code.Next
+		case encoder.OpOrderedMapPtr:
+			p := loadNPtr(ctxptr, code.Idx, code.PtrNum)
+			if p == 0 {
+				b = appendNullComma(ctx, b)
+				code = code.End.Next
+				break
+			}
+			store(ctxptr, code.Idx, p)
+			fallthrough
+		case encoder.OpOrderedMap:
+			p := load(ctxptr, code.Idx)
+			if p == 0 {
+				b = appendNullComma(ctx, b)
+				code = code.End.Next
+				break
+			}
+			// the entries of OrderedMap is the first field.
+			entries := ptrToSlice(p)
+			if entries.Len == 0 {
+				b = appendEmptyObject(ctx, b)
+				code = code.End.Next
+				break
+			}
+			b = appendStructHead(ctx, b)
+			b = appendMapKeyIndent(ctx, code.Next, b)
+			store(ctxptr, code.ElemIdx, 0)
+			store(ctxptr, code.Length, uintptr(entries.Len))
+			store(ctxptr, code.Idx, uintptr(entries.Data))
+			code = code.Next
+			store(ctxptr, code.Idx, uintptr(entries.Data))
+		case encoder.OpOrderedMapValue:
+			b = appendColon(ctx, b)
+			idx := load(ctxptr, code.ElemIdx)
+			entry := load(ctxptr, code.Idx) + idx*uintptr(code.Size)
+			offset := uintptr(code.Offset)
+			code = code.Next
+			store(ctxptr, code.Idx, entry+offset)
+		case encoder.OpOrderedMapKey:
+			idx := load(ctxptr, code.ElemIdx)
+			length := load(ctxptr, code.Length)
+			idx++
+			if idx < length {
+				if ctx.FlushThreshold > 0 && len(b) >= ctx.FlushThreshold {
+					fb, err := ctx.Flush(b)
+					if err != nil {
+						return nil, err
+					}
+					b = fb
+				}
+				b = appendMapKeyIndent(ctx, code.Next, b)
+				store(ctxptr, code.ElemIdx, idx)
+				data := load(ctxptr, code.Idx)
+				size := uintptr(code.Size)
+				code = code.Next
+				store(ctxptr, code.Idx, data+idx*size)
+			} else {
+				b = appendObjectEnd(ctx, code, b)
+				code = code.End.Next
+			}
+		case encoder.OpInlineMap:
+			p := load(ctxptr, code.Idx)
+			if p != 0 && (code.Flags&encoder.IndirectFlags) != 0 {
+				p = ptrToPtr(p)
+			}
+			if p != 0 {
+				p = encoder.FilterInlineMap(ctx, code, p)
+			}
+			if p == 0 || maplen(ptrToUnsafePtr(p)) == 0 {
+				code = code.End.Next
+				break
+			}
+			ctx.IncFlushBarrier()
+			store(ctxptr, code.ElemIdx, uintptr(len(b)))
+			code = code.Next
+			store(ctxptr, code.Idx, p)
+		case encoder.OpInlineMapEnd:
+			b = appendInlineObjectEnd(ctx, code, b, int(load(ctxptr, code.ElemIdx)))
+			ctx.DecFlushBarrier()
 			code = code.Next
 		case encoder.OpRecursivePtr:
 			p := load(ctxptr, code.Idx)
@@ -582,7 +821,39 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				b = appendStructHead(ctx, b)
 			}
 			p += uintptr(code.Offset)
-			if p == 0 || (ptrToPtr(p) == 0 && (code.Flags&encoder.IsNextOpPtrTypeFlags) != 0) {
+			// if the struct is not indirect, p is the value of the pointer field itself.
+			if p == 0 || ((code.Flags&encoder.IndirectFlags) != 0 && ptrToPtr(p) == 0 && (code.Flags&encoder.IsNextOpPtrTypeFlags) != 0) {
+				code = code.NextField
+			} else {
+				b = appendStructKey(ctx, code, b)
+				code = code.Next
+				store(ctxptr, code.Idx, p)
+			}
+		case encoder.OpStructPtrHeadOmitZero:
+			p := load(ctxptr, code.Idx)
+			if p == 0 {
+				if code.Flags&encoder.AnonymousHeadFlags == 0 {
+					b = appendNullComma(ctx, b)
+				}
+				code = code.End.Next
+				break
+			}
+			store(ctxptr, code.Idx, ptrToNPtr(p, code.PtrNum))
+			fallthrough
+		case encoder.OpStructHeadOmitZero:
+			p := load(ctxptr, code.Idx)
+			if p == 0 && ((code.Flags&encoder.IndirectFlags) != 0 || code.Next.Op == encoder.OpStructEnd) {
+				if code.Flags&encoder.AnonymousHeadFlags == 0 {
+					b = appendNullComma(ctx, b)
+				}
+				code = code.End.Next
+				break
+			}
+			if code.Flags&encoder.AnonymousHeadFlags == 0 {
+				b = appendStructHead(ctx, b)
+			}
+			p += uintptr(code.Offset)
+			if p == 0 || encoder.IsZeroField(code, p) {
 				code = code.NextField
 			} else {
 				b = appendStructKey(ctx, code, b)
@@ -3274,6 +3545,16 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				code = code.Next
 				store(ctxptr, code.Idx, p)
 			}
+		case encoder.OpStructFieldOmitZero:
+			p := load(ctxptr, code.Idx)
+			p += uintptr(code.Offset)
+			if encoder.IsZeroField(code, p) {
+				code = code.NextField
+			} else {
+				b = appendStructKey(ctx, code, b)
+				code = code.Next
+				store(ctxptr, code.Idx, p)
+			}
 		case encoder.OpStructFieldInt:
 			p := load(ctxptr, code.Idx)
 			b = appendStructKey(ctx, code, b)
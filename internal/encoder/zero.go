@@ -0,0 +1,305 @@
+package encoder
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+// IsZeroFunc reports whether the value stored at the address p should be omitted by omitzero option.
+type IsZeroFunc func(p uintptr) bool
+
+type isZeroer interface {
+	IsZero() bool
+}
+
+var isZeroerType = reflect.TypeOf((*isZeroer)(nil)).Elem()
+
+func hasIsZeroMethod(typ *runtime.Type) bool {
+	return typ.Implements(isZeroerType) || runtime.PtrTo(typ).Implements(isZeroerType)
+}
+
+// isOmitZeroFastPath reports whether the omitzero option for the value can be handled by OmitEmpty operations.
+// For these kinds, the zero value and the empty value are the same.
+func isOmitZeroFastPath(typ *runtime.Type, value Code) bool {
+	if hasIsZeroMethod(typ) {
+		return false
+	}
+	switch value.Kind() {
+	case CodeKindMarshalJSON, CodeKindMarshalText:
+		// OmitEmpty operations for marshaler only check nil.
+		switch typ.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Func:
+			return true
+		}
+		return false
+	case CodeKindFormat, CodeKindMarshalFunc:
+		// OmitEmpty operations don't check the value that isn't a pointer.
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Bool, reflect.String, reflect.Ptr, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// zeroFuncs has the zero value checkers of the type for the omitzero operations.
+// The checkers are kept by the type instead of Opcode, so that Opcode doesn't grow for the fields without omitzero.
+type zeroFuncs struct {
+	isZero        IsZeroFunc
+	isEmpty       IsZeroFunc // for the field that has only omitempty option
+	isEmptyOrZero IsZeroFunc // for the field that also has omitempty option
+}
+
+var (
+	cachedZeroFuncs   []atomic.Pointer[zeroFuncs]
+	cachedZeroFuncMap atomic.Pointer[map[*runtime.Type]*zeroFuncs] // for the types out of the range of typeAddr
+	zeroFuncMapMu     sync.Mutex
+	initZeroFuncsOnce sync.Once
+)
+
+func initZeroFuncs() {
+	initZeroFuncsOnce.Do(func() {
+		initEncoder()
+		cachedZeroFuncs = make([]atomic.Pointer[zeroFuncs], typeAddr.AddrRange>>typeAddr.AddrShift+1)
+	})
+}
+
+// storeZeroFuncs creates the zero value checkers of typ for IsZeroField.
+// It is called when the omitzero operation is compiled, so IsZeroField always finds the checkers.
+func storeZeroFuncs(typ *runtime.Type) {
+	initZeroFuncs()
+	if loadZeroFuncs(typ) != nil {
+		return
+	}
+	isZero := newIsZeroFunc(typ)
+	funcs := &zeroFuncs{
+		isZero:        isZero,
+		isEmpty:       newIsEmptyFunc(typ),
+		isEmptyOrZero: newIsEmptyOrZeroFunc(typ, isZero),
+	}
+	typeptr := uintptr(unsafe.Pointer(typ))
+	if typeptr > typeAddr.MaxTypeAddr || typeptr < typeAddr.BaseTypeAddr {
+		zeroFuncMapMu.Lock()
+		defer zeroFuncMapMu.Unlock()
+
+		var old map[*runtime.Type]*zeroFuncs
+		if m := cachedZeroFuncMap.Load(); m != nil {
+			old = *m
+		}
+		m := make(map[*runtime.Type]*zeroFuncs, len(old)+1)
+		for k, v := range old {
+			m[k] = v
+		}
+		m[typ] = funcs
+		cachedZeroFuncMap.Store(&m)
+		return
+	}
+	cachedZeroFuncs[(typeptr-typeAddr.BaseTypeAddr)>>typeAddr.AddrShift].Store(funcs)
+}
+
+func loadZeroFuncs(typ *runtime.Type) *zeroFuncs {
+	typeptr := uintptr(unsafe.Pointer(typ))
+	if typeptr > typeAddr.MaxTypeAddr || typeptr < typeAddr.BaseTypeAddr {
+		if m := cachedZeroFuncMap.Load(); m != nil {
+			return (*m)[typ]
+		}
+		return nil
+	}
+	return cachedZeroFuncs[(typeptr-typeAddr.BaseTypeAddr)>>typeAddr.AddrShift].Load()
+}
+
+// IsZeroField reports whether the field value at p is omitted by the omitzero operation.
+// The field that has only omitempty option uses the operation to check the value of the field type
+// instead of the encoded value, e.g. the field with the format or the MarshalFunc option.
+func IsZeroField(code *Opcode, p uintptr) bool {
+	funcs := loadZeroFuncs(code.Type)
+	switch code.Flags & (OmitEmptyFlags | OmitZeroFlags) {
+	case OmitEmptyFlags:
+		return funcs.isEmpty(p)
+	case OmitEmptyFlags | OmitZeroFlags:
+		return funcs.isEmptyOrZero(p)
+	}
+	return funcs.isZero(p)
+}
+
+// newIsEmptyFunc returns the checker of the empty value of omitempty option as same as encoding/json.
+// The struct and the array that has elements are never empty.
+func newIsEmptyFunc(typ *runtime.Type) IsZeroFunc {
+	if runtime.ToOrderedMapType(typ) != nil {
+		// the entries of OrderedMap is the first field.
+		return func(p uintptr) bool {
+			return (*(**runtime.SliceHeader)(unsafe.Pointer(&p))).Len == 0
+		}
+	}
+	switch typ.Kind() {
+	case reflect.Array:
+		if typ.Len() == 0 {
+			return func(uintptr) bool { return true }
+		}
+	case reflect.Map:
+		return func(p uintptr) bool {
+			m := **(**unsafe.Pointer)(unsafe.Pointer(&p))
+			return m == nil || MapLen(m) == 0
+		}
+	case reflect.Slice:
+		return func(p uintptr) bool {
+			return (*(**runtime.SliceHeader)(unsafe.Pointer(&p))).Len == 0
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Bool, reflect.String, reflect.Ptr, reflect.Interface:
+		return newZeroValueFunc(typ)
+	}
+	return func(uintptr) bool { return false }
+}
+
+func newIsEmptyOrZeroFunc(typ *runtime.Type, isZero IsZeroFunc) IsZeroFunc {
+	if runtime.ToOrderedMapType(typ) != nil {
+		// the entries of OrderedMap is the first field.
+		return func(p uintptr) bool {
+			return (*(**runtime.SliceHeader)(unsafe.Pointer(&p))).Len == 0 || isZero(p)
+		}
+	}
+	switch typ.Kind() {
+	case reflect.Array:
+		if typ.Len() == 0 {
+			return func(uintptr) bool { return true }
+		}
+	case reflect.Map:
+		return func(p uintptr) bool {
+			m := **(**unsafe.Pointer)(unsafe.Pointer(&p))
+			return m == nil || MapLen(m) == 0 || isZero(p)
+		}
+	case reflect.Slice, reflect.String:
+		// the length of the string is at the same offset as the slice.
+		return func(p uintptr) bool {
+			return (*(**runtime.SliceHeader)(unsafe.Pointer(&p))).Len == 0 || isZero(p)
+		}
+	}
+	return isZero
+}
+
+func newIsZeroFunc(typ *runtime.Type) IsZeroFunc {
+	switch {
+	case typ.Kind() == reflect.Interface && typ.Implements(isZeroerType):
+		rtype := runtime.RType2Type(typ)
+		return func(p uintptr) bool {
+			// avoid panics calling IsZero on a nil interface or non-nil interface with nil pointer.
+			v := reflect.NewAt(rtype, ptrToUnsafePtr(p)).Elem()
+			if v.IsNil() {
+				return true
+			}
+			elem := v.Elem()
+			if elem.Kind() == reflect.Ptr && elem.IsNil() {
+				return true
+			}
+			return v.Interface().(isZeroer).IsZero()
+		}
+	case typ.Kind() == reflect.Ptr && typ.Implements(isZeroerType):
+		return func(p uintptr) bool {
+			v := *(*unsafe.Pointer)(ptrToUnsafePtr(p))
+			if v == nil {
+				// avoid panics calling IsZero on nil pointer.
+				return true
+			}
+			return ptrToIsZeroer(typ, v).IsZero()
+		}
+	case hasIsZeroMethod(typ):
+		// *T has all methods of T, so IsZero can be called through the address of the value.
+		ptrType := runtime.PtrTo(typ)
+		return func(p uintptr) bool {
+			return ptrToIsZeroer(ptrType, ptrToUnsafePtr(p)).IsZero()
+		}
+	}
+	return newZeroValueFunc(typ)
+}
+
+// newZeroValueFunc returns the checker that compares the value with the zero value of typ like reflect.Value.IsZero.
+// The value is compared by ==, so the floating-point negative zero is the zero value, and the blank fields are ignored.
+func newZeroValueFunc(typ *runtime.Type) IsZeroFunc {
+	switch typ.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return func(p uintptr) bool { return **(**uint8)(unsafe.Pointer(&p)) == 0 }
+	case reflect.Int16, reflect.Uint16:
+		return func(p uintptr) bool { return **(**uint16)(unsafe.Pointer(&p)) == 0 }
+	case reflect.Int32, reflect.Uint32:
+		return func(p uintptr) bool { return **(**uint32)(unsafe.Pointer(&p)) == 0 }
+	case reflect.Int64, reflect.Uint64:
+		return func(p uintptr) bool { return **(**uint64)(unsafe.Pointer(&p)) == 0 }
+	case reflect.Float32:
+		return func(p uintptr) bool { return **(**float32)(unsafe.Pointer(&p)) == 0 }
+	case reflect.Float64:
+		return func(p uintptr) bool { return **(**float64)(unsafe.Pointer(&p)) == 0 }
+	case reflect.Complex64:
+		return func(p uintptr) bool { return **(**complex64)(unsafe.Pointer(&p)) == 0 }
+	case reflect.Complex128:
+		return func(p uintptr) bool { return **(**complex128)(unsafe.Pointer(&p)) == 0 }
+	case reflect.Int, reflect.Uint, reflect.Uintptr,
+		reflect.Ptr, reflect.UnsafePointer, reflect.Map, reflect.Chan, reflect.Func,
+		reflect.Slice, reflect.Interface:
+		// the first word is the value, the pointer to the data or the type of the interface.
+		return func(p uintptr) bool { return **(**uintptr)(unsafe.Pointer(&p)) == 0 }
+	case reflect.String:
+		return func(p uintptr) bool { return (*(**runtime.SliceHeader)(unsafe.Pointer(&p))).Len == 0 }
+	case reflect.Array:
+		n := uintptr(typ.Len())
+		size := typ.Elem().Size()
+		isZero := newZeroValueFunc(typ.Elem())
+		return func(p uintptr) bool {
+			for i := uintptr(0); i < n; i++ {
+				if !isZero(p + i*size) {
+					return false
+				}
+			}
+			return true
+		}
+	case reflect.Struct:
+		type field struct {
+			offset uintptr
+			isZero IsZeroFunc
+		}
+		fields := make([]field, 0, typ.NumField())
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.Name == "_" {
+				continue
+			}
+			fields = append(fields, field{
+				offset: f.Offset,
+				isZero: newZeroValueFunc(runtime.Type2RType(f.Type)),
+			})
+		}
+		return func(p uintptr) bool {
+			for _, f := range fields {
+				if !f.isZero(p + f.offset) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	rtype := runtime.RType2Type(typ)
+	return func(p uintptr) bool {
+		return reflect.NewAt(rtype, ptrToUnsafePtr(p)).Elem().IsZero()
+	}
+}
+
+func ptrToIsZeroer(typ *runtime.Type, p unsafe.Pointer) isZeroer {
+	return (*(*interface{})(unsafe.Pointer(&emptyInterface{
+		typ: typ,
+		ptr: p,
+	}))).(isZeroer)
+}
+
+func ptrToUnsafePtr(p uintptr) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&p))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
 type InvalidUTF8Error struct {
@@ -51,6 +52,9 @@ func (e *MarshalerError) Unwrap() error { return e.Err }
 type SyntaxError struct {
 	msg    string // description of error
 	Offset int64  // error occurred after reading Offset bytes
+	Line   int    // 1-based line number of the byte at Offset. 0 if unknown
+	Column int    // 1-based column ( in bytes ) of the byte at Offset. 0 if unknown
+	snippet
 }
 
 func (e *SyntaxError) Error() string { return e.msg }
@@ -74,11 +78,15 @@ func (e *UnmarshalFieldError) Error() string {
 // An UnmarshalTypeError describes a JSON value that was
 // not appropriate for a value of a specific Go type.
 type UnmarshalTypeError struct {
-	Value  string       // description of JSON value - "bool", "array", "number -5"
-	Type   reflect.Type // type of Go value it could not be assigned to
-	Offset int64        // error occurred after reading Offset bytes
-	Struct string       // name of the struct type containing the field
-	Field  string       // the full path from root node to the field
+	Value   string       // description of JSON value - "bool", "array", "number -5"
+	Type    reflect.Type // type of Go value it could not be assigned to
+	Offset  int64        // error occurred after reading Offset bytes
+	Struct  string       // name of the struct type containing the field
+	Field   string       // the full path from root node to the field
+	Pointer string       // RFC 6901 JSON Pointer to the value. e.g.) "/items/3/price"
+	Line    int          // 1-based line number of the byte at Offset. 0 if unknown
+	Column  int          // 1-based column ( in bytes ) of the byte at Offset. 0 if unknown
+	snippet
 }
 
 func (e *UnmarshalTypeError) Error() string {
@@ -90,6 +98,39 @@ func (e *UnmarshalTypeError) Error() string {
 	return fmt.Sprintf("json: cannot unmarshal %s into Go value of type %s", e.Value, e.Type)
 }
 
+// UnmarshalErrors is the list of errors collected while decoding the whole input.
+type UnmarshalErrors struct {
+	Errors []error
+}
+
+func (e *UnmarshalErrors) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		if typeErr, ok := err.(*UnmarshalTypeError); ok && typeErr.Pointer != "" {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", typeErr.Pointer, err.Error()))
+			continue
+		}
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the collected errors in the same form as errors.Join.
+func (e *UnmarshalErrors) Unwrap() []error { return e.Errors }
+
+// A DuplicateKeyError describes an object key that appeared more than once in the same object.
+type DuplicateKeyError struct {
+	Key    string // the duplicate key. For struct, the key of the field.
+	Offset int64  // offset of the second occurrence of the key
+	Line   int    // 1-based line number of the byte at Offset. 0 if unknown
+	Column int    // 1-based column ( in bytes ) of the byte at Offset. 0 if unknown
+	snippet
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("json: duplicate key %s", strconv.Quote(e.Key))
+}
+
 // An UnsupportedTypeError is returned by Marshal when attempting
 // to encode an unsupported value type.
 type UnsupportedTypeError struct {
@@ -121,6 +162,22 @@ func ErrMarshaler(typ reflect.Type, err error, msg string) *MarshalerError {
 	}
 }
 
+func ErrDuplicateKey(key string, offset int64) *DuplicateKeyError {
+	return &DuplicateKeyError{Key: key, Offset: offset}
+}
+
+func ErrUnknownFieldsType(field reflect.StructField) error {
+	return fmt.Errorf("json: field %s with unknown option must be map with string keys, but got %v", field.Name, field.Type)
+}
+
+func ErrInlineFieldType(field reflect.StructField) error {
+	return fmt.Errorf("json: field %s with inline option must be struct or map with string keys, but got %v", field.Name, field.Type)
+}
+
+func ErrFieldFormat(field reflect.StructField, format string) error {
+	return fmt.Errorf("json: field %s has unsupported format %q for %v", field.Name, format, field.Type)
+}
+
 func ErrExceededMaxDepth(c byte, cursor int64) *SyntaxError {
 	return &SyntaxError{
 		msg:    fmt.Sprintf(`invalid character "%c" exceeded max depth`, c),
@@ -163,6 +220,19 @@ func ErrInvalidBeginningOfValue(c byte, cursor int64) *SyntaxError {
 	}
 }
 
+// A LineError describes an error that occurred while decoding a line of JSON Lines input.
+type LineError struct {
+	Line int   // 1-based line number
+	Err  error // error that occurred on the line
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("json: line %d: %s", e.Line, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *LineError) Unwrap() error { return e.Err }
+
 type PathError struct {
 	msg string
 }
@@ -181,3 +251,54 @@ func ErrInvalidPath(msg string, args ...interface{}) *PathError {
 func ErrEmptyPath() *PathError {
 	return &PathError{msg: "path is empty"}
 }
+
+type PointerError struct {
+	msg string
+}
+
+func (e *PointerError) Error() string {
+	return fmt.Sprintf("json: %s", e.msg)
+}
+
+func ErrInvalidPointer(ptr string, msg string) *PointerError {
+	return &PointerError{msg: fmt.Sprintf("invalid JSON Pointer %q: %s", ptr, msg)}
+}
+
+func ErrPointerNotFound(ptr string) *PointerError {
+	return &PointerError{msg: fmt.Sprintf("JSON Pointer %q does not reference any value", ptr)}
+}
+
+// A PatchError describes an operation of JSON Patch ( RFC 6902 ) that could not be applied.
+type PatchError struct {
+	Index int        // index of the operation in the patch document
+	Op    string     // operation name. e.g.) "add"
+	Path  string     // JSON Pointer of the operation
+	Err   *PathError // reason why the operation could not be applied
+}
+
+func (e *PatchError) Error() string {
+	if e.Op == "" {
+		return fmt.Sprintf("json: patch operation %d: %s", e.Index, e.Err.msg)
+	}
+	return fmt.Sprintf("json: patch operation %d (%s %q): %s", e.Index, e.Op, e.Path, e.Err.msg)
+}
+
+// Unwrap returns the underlying PathError.
+func (e *PatchError) Unwrap() error { return e.Err }
+
+// A NodeError describes an operation that is not applicable to the Node.
+type NodeError struct {
+	msg string
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("json: %s", e.msg)
+}
+
+func ErrNodeKind(op string, kind string) *NodeError {
+	return &NodeError{msg: fmt.Sprintf("cannot %s on %s node", op, kind)}
+}
+
+func ErrNodeIndex(op string, idx, length int) *NodeError {
+	return &NodeError{msg: fmt.Sprintf("cannot %s: index %d out of range [0:%d]", op, idx, length)}
+}
@@ -0,0 +1,155 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSnippetContext is the max number of bytes shown before and after the offending byte in the snippet.
+const maxSnippetContext = 40
+
+// snippet keeps a part of the line that contains the offending byte to render it with a caret.
+type snippet struct {
+	text   string // part of the source line
+	caret  int    // 0-based position ( in runes ) of the offending byte in text
+	hasPre bool   // whether text is truncated at the beginning of the line
+	hasSuf bool   // whether text is truncated at the end of the line
+}
+
+func (s snippet) render(line int) string {
+	if line == 0 {
+		return ""
+	}
+	prefix, suffix := "", ""
+	if s.hasPre {
+		prefix = "..."
+	}
+	if s.hasSuf {
+		suffix = "..."
+	}
+	gutter := fmt.Sprint(line)
+	return fmt.Sprintf(
+		"%s | %s%s%s\n%s | %s^",
+		gutter, prefix, s.text, suffix,
+		strings.Repeat(" ", len(gutter)), strings.Repeat(" ", len(prefix)+s.caret),
+	)
+}
+
+// Snippet renders the source line that contains the error with a caret under the offending byte.
+// It returns an empty string if the position of the error is unknown.
+//
+//	3 | {"a": 1,, "b": 2}
+//	  |         ^
+func (e *SyntaxError) Snippet() string {
+	return e.snippet.render(e.Line)
+}
+
+// Snippet renders the source line that contains the error with a caret under the offending byte.
+// It returns an empty string if the position of the error is unknown.
+func (e *UnmarshalTypeError) Snippet() string {
+	return e.snippet.render(e.Line)
+}
+
+// Snippet renders the source line that contains the error with a caret under the duplicate key.
+// It returns an empty string if the position of the error is unknown.
+func (e *DuplicateKeyError) Snippet() string {
+	return e.snippet.render(e.Line)
+}
+
+// A Source describes a window of the input used to locate the position of errors.
+// The line information is only computed when an error occurs.
+type Source struct {
+	Buf       []byte // input bytes that start at Offset
+	Offset    int64  // offset of Buf[0] in the whole input
+	Line      int    // 1-based line number of Buf[0]
+	LineStart int64  // offset of the beginning of the line that contains Buf[0]
+}
+
+// Locate sets the line, column and snippet to err if err is *SyntaxError, *UnmarshalTypeError or *DuplicateKeyError.
+// It returns err as it is.
+func (src *Source) Locate(err error) error {
+	switch e := err.(type) {
+	case *SyntaxError:
+		return src.LocateAt(e, e.Offset)
+	case *UnmarshalTypeError:
+		return src.LocateAt(e, e.Offset)
+	case *DuplicateKeyError:
+		return src.LocateAt(e, e.Offset)
+	}
+	return err
+}
+
+// LocateAt is the same as Locate, but uses the byte at offset instead of the Offset of err.
+// This is useful for errors whose Offset points after the offending byte.
+func (src *Source) LocateAt(err error, offset int64) error {
+	switch e := err.(type) {
+	case *SyntaxError:
+		if e.Line == 0 {
+			e.Line, e.Column, e.snippet = src.position(offset)
+		}
+	case *UnmarshalTypeError:
+		if e.Line == 0 {
+			e.Line, e.Column, e.snippet = src.position(offset)
+		}
+	case *DuplicateKeyError:
+		if e.Line == 0 {
+			e.Line, e.Column, e.snippet = src.position(offset)
+		}
+	}
+	return err
+}
+
+func (src *Source) position(offset int64) (int, int, snippet) {
+	pos := offset - src.Offset
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > int64(len(src.Buf)) {
+		pos = int64(len(src.Buf))
+	}
+	line := src.Line
+	lineStart := src.LineStart
+	if n := bytes.Count(src.Buf[:pos], []byte{'\n'}); n > 0 {
+		line += n
+		lineStart = src.Offset + int64(bytes.LastIndexByte(src.Buf[:pos], '\n')) + 1
+	}
+	column := int(src.Offset + pos - lineStart + 1)
+
+	// the beginning of the line may be already discarded from Buf.
+	start := lineStart - src.Offset
+	hasPre := start < 0
+	if hasPre {
+		start = 0
+	}
+	if pos-start > maxSnippetContext {
+		start = pos - maxSnippetContext
+		hasPre = true
+	}
+	end := int64(len(src.Buf))
+	if idx := bytes.IndexByte(src.Buf[pos:], '\n'); idx >= 0 {
+		end = pos + int64(idx)
+	}
+	hasSuf := false
+	if end-pos > maxSnippetContext {
+		end = pos + maxSnippetContext
+		hasSuf = true
+	}
+	pre := strings.Map(replaceControlChar, strings.ToValidUTF8(string(src.Buf[start:pos]), "?"))
+	rest := strings.Map(replaceControlChar, strings.ToValidUTF8(strings.TrimRight(string(src.Buf[pos:end]), "\r"), "?"))
+	return line, column, snippet{
+		text:   pre + rest,
+		caret:  utf8.RuneCountInString(pre),
+		hasPre: hasPre,
+		hasSuf: hasSuf,
+	}
+}
+
+// replaceControlChar replaces control characters with a space so that the caret is aligned.
+func replaceControlChar(r rune) rune {
+	if r < ' ' {
+		return ' '
+	}
+	return r
+}
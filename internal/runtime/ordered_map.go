@@ -0,0 +1,46 @@
+package runtime
+
+import (
+	"reflect"
+	"strings"
+)
+
+const orderedMapPkgPath = "github.com/goccy/go-json"
+
+// OrderedMapType is the layout of json.OrderedMap[K, V] that is shared by the encoder and the decoder.
+// json.OrderedMap is defined as struct { entries []struct { key K; value V }; index map[K]int }.
+type OrderedMapType struct {
+	Entry       *Type // struct { key K; value V }
+	Key         *Type
+	Value       *Type
+	ValueOffset uintptr
+	Index       *Type // map[K]int
+	IndexOffset uintptr
+}
+
+// ToOrderedMapType returns the layout if typ is the instance of json.OrderedMap. Otherwise it returns nil.
+func ToOrderedMapType(typ *Type) *OrderedMapType {
+	if typ.Kind() != reflect.Struct || typ.PkgPath() != orderedMapPkgPath || !strings.HasPrefix(typ.Name(), "OrderedMap[") {
+		return nil
+	}
+	if typ.NumField() != 2 {
+		return nil
+	}
+	entries := typ.Field(0)
+	index := typ.Field(1)
+	if entries.Name != "entries" || entries.Type.Kind() != reflect.Slice || index.Name != "index" || index.Type.Kind() != reflect.Map {
+		return nil
+	}
+	entry := entries.Type.Elem()
+	if entry.Kind() != reflect.Struct || entry.NumField() != 2 {
+		return nil
+	}
+	return &OrderedMapType{
+		Entry:       Type2RType(entry),
+		Key:         Type2RType(entry.Field(0).Type),
+		Value:       Type2RType(entry.Field(1).Type),
+		ValueOffset: entry.Field(1).Offset,
+		Index:       Type2RType(index.Type),
+		IndexOffset: index.Offset,
+	}
+}
@@ -3,6 +3,7 @@ package runtime
 import (
 	"reflect"
 	"strings"
+	"time"
 	"unicode"
 )
 
@@ -30,11 +31,16 @@ func IsIgnoredStructField(field reflect.StructField) bool {
 }
 
 type StructTag struct {
-	Key         string
-	IsTaggedKey bool
-	IsOmitEmpty bool
-	IsString    bool
-	Field       reflect.StructField
+	Key          string
+	IsTaggedKey  bool
+	IsOmitEmpty  bool
+	IsOmitZero   bool
+	IsString     bool
+	IsStrictCase bool
+	IsUnknown    bool
+	IsInline     bool
+	Format       string
+	Field        reflect.StructField
 }
 
 type StructTags []*StructTag
@@ -82,10 +88,93 @@ func StructTagFromField(field reflect.StructField) *StructTag {
 			switch opt {
 			case "omitempty":
 				st.IsOmitEmpty = true
+			case "omitzero":
+				st.IsOmitZero = true
 			case "string":
 				st.IsString = true
+			case "strictcase":
+				st.IsStrictCase = true
+			case "unknown":
+				st.IsUnknown = true
+			case "inline":
+				st.IsInline = true
+			default:
+				if strings.HasPrefix(opt, "format:") {
+					st.Format = strings.TrimPrefix(opt, "format:")
+				}
 			}
 		}
 	}
 	return st
 }
+
+// IsInlineMap reports whether the entries of the map field are the members of the struct.
+// It is true for the field that has the unknown option, or the map field that has the inline option.
+func (t *StructTag) IsInlineMap() bool {
+	return t.IsUnknown || (t.IsInline && t.Field.Type.Kind() == reflect.Map)
+}
+
+// IsInlineStruct reports whether the fields of the struct field are the fields of the parent struct
+// like the embedded struct.
+func (t *StructTag) IsInlineStruct() bool {
+	if !t.IsInline || t.IsUnknown {
+		return false
+	}
+	typ := t.Field.Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct
+}
+
+// IsUnknownFieldsType reports whether typ can be the type of the field that has the unknown option.
+// The field must be the map that has string keys.
+func IsUnknownFieldsType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Map && typ.Key().Kind() == reflect.String
+}
+
+// FieldFormat is the format of the value that is specified by the format option such as `json:",format:unix"`.
+type FieldFormat int
+
+const (
+	FormatNone FieldFormat = iota
+	FormatUnix
+	FormatUnixMilli
+	FormatHex
+	FormatBase64URL
+	FormatUnits
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// FieldFormat returns the format of the field. The field can be the pointer to the type of the format.
+// It returns false if the format is unknown or can't be used for the type of the field.
+func (t *StructTag) FieldFormat() (FieldFormat, bool) {
+	if t.Format == "" {
+		return FormatNone, true
+	}
+	typ := t.Field.Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch t.Format {
+	case "unix":
+		return FormatUnix, typ == timeType
+	case "unixmilli":
+		return FormatUnixMilli, typ == timeType
+	case "hex":
+		return FormatHex, isBytesType(typ)
+	case "base64url":
+		return FormatBase64URL, isBytesType(typ)
+	case "units":
+		return FormatUnits, typ == durationType
+	}
+	return FormatNone, false
+}
+
+func isBytesType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8
+}
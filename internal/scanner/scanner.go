@@ -0,0 +1,369 @@
+// Package scanner implements the validating scanner of JSON that backs Valid and Compact,
+// and the non-validating escape mode of it that backs HTMLEscape.
+// The scanner works on the input as is, so the input doesn't need the terminating nul character,
+// and it doesn't allocate except for the error.
+// The bytes of the input are copied to the output as they are except for the intended transformations.
+package scanner
+
+import (
+	"fmt"
+
+	"github.com/goccy/go-json/internal/errors"
+)
+
+// maxNestingDepth is the same as the max nesting depth of the decoder.
+const maxNestingDepth = 10000
+
+const hex = "0123456789abcdef"
+
+var (
+	isWhiteSpace = [256]bool{
+		' ':  true,
+		'\n': true,
+		'\t': true,
+		'\r': true,
+	}
+	isHTMLEscapeChar = [256]bool{
+		'<': true,
+		'>': true,
+		'&': true,
+	}
+	isHexChar = [256]bool{
+		'0': true, '1': true, '2': true, '3': true, '4': true,
+		'5': true, '6': true, '7': true, '8': true, '9': true,
+		'a': true, 'b': true, 'c': true, 'd': true, 'e': true, 'f': true,
+		'A': true, 'B': true, 'C': true, 'D': true, 'E': true, 'F': true,
+	}
+)
+
+type scanner struct {
+	src    []byte
+	dst    []byte
+	cursor int
+	start  int  // the start of the bytes that are not written to dst yet
+	write  bool // write the input to dst
+	trim   bool // remove the spaces outside of the values
+	escape bool // escape the HTML characters in the strings
+}
+
+// Valid reports whether src is a valid JSON encoding.
+func Valid(src []byte) bool {
+	return Validate(src) == nil
+}
+
+// Validate returns the syntax error if src is not a valid JSON encoding.
+func Validate(src []byte) error {
+	s := scanner{src: src}
+	return s.scan()
+}
+
+// Compact appends to dst the src without the insignificant spaces.
+// If escapeHTML is true, the HTML characters in the strings are escaped as same as HTMLEscape.
+// If src is invalid, it returns the error and dst is not changed.
+func Compact(dst, src []byte, escapeHTML bool) ([]byte, error) {
+	s := scanner{src: src, dst: dst, write: true, trim: true, escape: escapeHTML}
+	if err := s.scan(); err != nil {
+		return dst, err
+	}
+	return s.dst, nil
+}
+
+// HTMLEscape appends to dst the src with <, >, &, U+2028 and U+2029 characters
+// changed to \u003c, \u003e, \u0026, \u2028 and \u2029.
+// src is not validated, and the characters are escaped wherever they are as same as encoding/json.
+// In a valid JSON, they appear only in the strings. The other bytes are kept.
+func HTMLEscape(dst, src []byte) []byte {
+	s := scanner{src: src, dst: dst, write: true, escape: true}
+	for s.cursor < len(src) {
+		if n := s.escapeHTML(s.cursor); n > 0 {
+			s.cursor += n
+			continue
+		}
+		s.cursor++
+	}
+	s.flush(len(src))
+	return s.dst
+}
+
+func (s *scanner) scan() error {
+	s.skipWhiteSpace()
+	if s.cursor >= len(s.src) {
+		return errors.ErrUnexpectedEndOfJSON("", int64(s.cursor))
+	}
+	if err := s.scanValue(0); err != nil {
+		return err
+	}
+	s.skipWhiteSpace()
+	if s.cursor < len(s.src) {
+		return errors.ErrSyntax(
+			fmt.Sprintf("invalid character '%c' after top-level value", s.src[s.cursor]),
+			int64(s.cursor+1),
+		)
+	}
+	s.flush(s.cursor)
+	return nil
+}
+
+// flush writes the bytes from start to end.
+func (s *scanner) flush(end int) {
+	if s.write {
+		s.dst = append(s.dst, s.src[s.start:end]...)
+	}
+	s.start = end
+}
+
+// char returns the current character. It returns nul at the end of the input.
+func (s *scanner) char() byte {
+	if s.cursor < len(s.src) {
+		return s.src[s.cursor]
+	}
+	return 0
+}
+
+func (s *scanner) skipWhiteSpace() {
+	start := s.cursor
+	for s.cursor < len(s.src) && isWhiteSpace[s.src[s.cursor]] {
+		s.cursor++
+	}
+	if s.trim && s.cursor != start {
+		s.flush(start)
+		s.start = s.cursor
+	}
+}
+
+func (s *scanner) errUnexpectedEnd(msg string) error {
+	return errors.ErrUnexpectedEndOfJSON(msg, int64(len(s.src)))
+}
+
+func (s *scanner) scanValue(depth int) error {
+	switch c := s.char(); c {
+	case '{':
+		return s.scanObject(depth + 1)
+	case '[':
+		return s.scanArray(depth + 1)
+	case '"':
+		return s.scanString()
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return s.scanNumber()
+	case 't':
+		return s.scanLiteral("true")
+	case 'f':
+		return s.scanLiteral("false")
+	case 'n':
+		return s.scanLiteral("null")
+	case 0:
+		if s.cursor >= len(s.src) {
+			return s.errUnexpectedEnd("value")
+		}
+		return errors.ErrInvalidBeginningOfValue(c, int64(s.cursor))
+	default:
+		return errors.ErrInvalidBeginningOfValue(c, int64(s.cursor))
+	}
+}
+
+func (s *scanner) scanObject(depth int) error {
+	if depth > maxNestingDepth {
+		return errors.ErrExceededMaxDepth(s.char(), int64(s.cursor))
+	}
+	s.cursor++
+	s.skipWhiteSpace()
+	if s.char() == '}' {
+		s.cursor++
+		return nil
+	}
+	for {
+		if s.char() != '"' {
+			if s.cursor >= len(s.src) {
+				return s.errUnexpectedEnd("object key")
+			}
+			return errors.ErrInvalidCharacter(s.char(), "object key", int64(s.cursor))
+		}
+		if err := s.scanString(); err != nil {
+			return err
+		}
+		s.skipWhiteSpace()
+		if s.char() != ':' {
+			return errors.ErrExpected("colon after object key", int64(s.cursor))
+		}
+		s.cursor++
+		s.skipWhiteSpace()
+		if err := s.scanValue(depth); err != nil {
+			return err
+		}
+		s.skipWhiteSpace()
+		switch s.char() {
+		case '}':
+			s.cursor++
+			return nil
+		case ',':
+			s.cursor++
+			s.skipWhiteSpace()
+		default:
+			return errors.ErrExpected("comma after object value", int64(s.cursor))
+		}
+	}
+}
+
+func (s *scanner) scanArray(depth int) error {
+	if depth > maxNestingDepth {
+		return errors.ErrExceededMaxDepth(s.char(), int64(s.cursor))
+	}
+	s.cursor++
+	s.skipWhiteSpace()
+	if s.char() == ']' {
+		s.cursor++
+		return nil
+	}
+	for {
+		if err := s.scanValue(depth); err != nil {
+			return err
+		}
+		s.skipWhiteSpace()
+		switch s.char() {
+		case ']':
+			s.cursor++
+			return nil
+		case ',':
+			s.cursor++
+			s.skipWhiteSpace()
+		default:
+			return errors.ErrExpected("comma after array value", int64(s.cursor))
+		}
+	}
+}
+
+func (s *scanner) scanString() error {
+	src := s.src
+	cursor := s.cursor + 1
+	for cursor < len(src) {
+		c := src[cursor]
+		switch {
+		case c == '"':
+			s.cursor = cursor + 1
+			return nil
+		case c == '\\':
+			n, err := s.escapeLen(cursor)
+			if err != nil {
+				return err
+			}
+			cursor += n
+		case c < 0x20:
+			return errors.ErrInvalidCharacter(c, "string", int64(cursor))
+		default:
+			if s.escape {
+				if n := s.escapeHTML(cursor); n > 0 {
+					cursor += n
+					continue
+				}
+			}
+			cursor++
+		}
+	}
+	return s.errUnexpectedEnd("string")
+}
+
+// escapeHTML writes the escaped HTML character at cursor, and returns the length of the character.
+// It returns 0 if the character at cursor isn't escaped.
+func (s *scanner) escapeHTML(cursor int) int {
+	src := s.src
+	c := src[cursor]
+	switch {
+	case isHTMLEscapeChar[c]:
+		s.flush(cursor)
+		s.dst = append(s.dst, `\u00`...)
+		s.dst = append(s.dst, hex[c>>4], hex[c&0xF])
+		s.start = cursor + 1
+		return 1
+	case c == 0xE2 && cursor+2 < len(src) && src[cursor+1] == 0x80 && src[cursor+2]&^1 == 0xA8:
+		// U+2028 is LINE SEPARATOR and U+2029 is PARAGRAPH SEPARATOR.
+		s.flush(cursor)
+		s.dst = append(s.dst, `\u202`...)
+		s.dst = append(s.dst, hex[src[cursor+2]&0xF])
+		s.start = cursor + 3
+		return 3
+	}
+	return 0
+}
+
+// escapeLen returns the length of the escape sequence at cursor.
+func (s *scanner) escapeLen(cursor int) (int, error) {
+	src := s.src
+	if cursor+1 >= len(src) {
+		return 0, s.errUnexpectedEnd("string")
+	}
+	switch c := src[cursor+1]; c {
+	case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+		return 2, nil
+	case 'u':
+		for i := cursor + 2; i < cursor+6; i++ {
+			if i >= len(src) {
+				return 0, s.errUnexpectedEnd("string")
+			}
+			if !isHexChar[src[i]] {
+				return 0, errors.ErrInvalidCharacter(src[i], "escaped string", int64(i))
+			}
+		}
+		return 6, nil
+	default:
+		return 0, errors.ErrInvalidCharacter(c, "escaped string", int64(cursor+1))
+	}
+}
+
+func (s *scanner) scanDigits() int {
+	start := s.cursor
+	for s.cursor < len(s.src) && '0' <= s.src[s.cursor] && s.src[s.cursor] <= '9' {
+		s.cursor++
+	}
+	return s.cursor - start
+}
+
+func (s *scanner) scanNumber() error {
+	if s.char() == '-' {
+		s.cursor++
+	}
+	switch c := s.char(); {
+	case c == '0':
+		s.cursor++
+	case '1' <= c && c <= '9':
+		s.scanDigits()
+	default:
+		return s.errNumber()
+	}
+	if s.char() == '.' {
+		s.cursor++
+		if s.scanDigits() == 0 {
+			return s.errNumber()
+		}
+	}
+	if c := s.char(); c == 'e' || c == 'E' {
+		s.cursor++
+		if c := s.char(); c == '+' || c == '-' {
+			s.cursor++
+		}
+		if s.scanDigits() == 0 {
+			return s.errNumber()
+		}
+	}
+	return nil
+}
+
+func (s *scanner) errNumber() error {
+	if s.cursor >= len(s.src) {
+		return s.errUnexpectedEnd("number")
+	}
+	return errors.ErrInvalidCharacter(s.char(), "number", int64(s.cursor))
+}
+
+func (s *scanner) scanLiteral(literal string) error {
+	for i := 0; i < len(literal); i++ {
+		cursor := s.cursor + i
+		if cursor >= len(s.src) {
+			return s.errUnexpectedEnd(literal)
+		}
+		if s.src[cursor] != literal[i] {
+			return errors.ErrInvalidCharacter(s.src[cursor], literal, int64(cursor))
+		}
+	}
+	s.cursor += len(literal)
+	return nil
+}
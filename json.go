@@ -5,7 +5,9 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/goccy/go-json/internal/decoder"
 	"github.com/goccy/go-json/internal/encoder"
+	"github.com/goccy/go-json/internal/scanner"
 )
 
 // Marshaler is the interface implemented by types that
@@ -20,6 +22,16 @@ type MarshalerContext interface {
 	MarshalJSON(context.Context) ([]byte, error)
 }
 
+// MarshalerTo is the interface implemented by types that
+// can append their JSON encoding to the buffer of the encoder.
+// AppendJSON must append a valid and compact JSON value to dst and return the extended buffer.
+// Unlike the output of MarshalJSON, the appended value is neither copied nor compacted,
+// and it is validated only if ValidateAppendJSON option is given.
+// If a type implements both MarshalerTo and Marshaler, AppendJSON is used.
+type MarshalerTo interface {
+	AppendJSON(dst []byte) ([]byte, error)
+}
+
 // Unmarshaler is the interface implemented by types
 // that can unmarshal a JSON description of themselves.
 // The input can be assumed to be a valid encoding of
@@ -38,6 +50,18 @@ type UnmarshalerContext interface {
 	UnmarshalJSON(context.Context, []byte) error
 }
 
+// UnmarshalerFrom is the interface implemented by types
+// that can unmarshal themselves from the tokens of the input.
+// With Decoder, the value is read directly from the stream without buffering the whole value.
+// UnmarshalJSONFrom must read exactly one JSON value from the TokenDecoder.
+// If a type implements both UnmarshalerFrom and Unmarshaler, UnmarshalJSONFrom is used.
+type UnmarshalerFrom interface {
+	UnmarshalJSONFrom(*TokenDecoder) error
+}
+
+// TokenDecoder reads the JSON value for UnmarshalJSONFrom by Token, Decode, ReadValue and SkipValue.
+type TokenDecoder = decoder.TokenDecoder
+
 // Marshal returns the JSON encoding of v.
 //
 // Marshal traverses the value v recursively.
@@ -115,6 +139,34 @@ type UnmarshalerContext interface {
 //
 //	Int64String int64 `json:",string"`
 //
+// The "unknown" option specifies that the field keeps the members of the object
+// that do not match any other field. It applies only to fields of map type that
+// has string keys such as map[string]json.RawMessage or map[string]interface{}.
+// Unmarshal stores the unmatched members in the map, and Marshal writes the entries
+// of the map after the other fields of the struct. The name of the field is not used:
+//
+//	Extra map[string]json.RawMessage `json:",unknown"`
+//
+// The "inline" option specifies that the fields of a named struct field are
+// treated as if they were fields in the outer struct, in the same way as an
+// anonymous struct field. It applies to fields of struct type, pointer to struct
+// type, or map type that has string keys. A map field with the "inline" option is
+// treated the same as having the "unknown" option:
+//
+//	Meta Meta `json:",inline"`
+//
+// The "format:" option specifies the format of the value instead of the default
+// encoding. The "unix" and "unixmilli" formats encode time.Time as the number of
+// seconds or milliseconds since the Unix epoch, and Unmarshal decodes the number
+// that may have the fraction such as 1700000000.5 to time.Time in UTC. The "hex" and "base64url" formats encode []byte as the hex
+// string or the URL-safe base64 string. The "units" format encodes time.Duration
+// as the string such as "1h30m0s". The format can be used for the pointer to these
+// types, and the "omitempty" option omits the zero value of them:
+//
+//	CreatedAt time.Time     `json:"created_at,format:unix"`
+//	Digest    []byte        `json:"digest,format:hex"`
+//	Timeout   time.Duration `json:"timeout,format:units,omitempty"`
+//
 // The key name will be used if it's a non-empty string consisting of
 // only Unicode letters, digits, and ASCII punctuation except quotation
 // marks, backslash, and comma.
@@ -222,7 +274,7 @@ func MarshalIndentWithOption(v interface{}, prefix, indent string, optFuncs ...E
 // keys to the keys used by Marshal (either the struct field name or its tag),
 // preferring an exact match but also accepting a case-insensitive match. By
 // default, object keys which don't have a corresponding struct field are
-// ignored (see Decoder.DisallowUnknownFields for an alternative).
+// ignored (see Decoder.DisallowUnknownFields or DecodeDisallowUnknownFields for an alternative).
 //
 // To unmarshal JSON into an interface value,
 // Unmarshal stores one of these in the interface value:
@@ -337,29 +389,14 @@ func Indent(dst *bytes.Buffer, src []byte, prefix, indent string) error {
 // For historical reasons, web browsers don't honor standard HTML
 // escaping within <script> tags, so an alternative JSON encoding must
 // be used.
+// src is not validated, and the other bytes of src are written as they are.
 func HTMLEscape(dst *bytes.Buffer, src []byte) {
-	var v interface{}
-	dec := NewDecoder(bytes.NewBuffer(src))
-	dec.UseNumber()
-	if err := dec.Decode(&v); err != nil {
-		return
-	}
-	buf, _ := marshal(v)
-	dst.Write(buf)
+	encoder.HTMLEscape(dst, src)
 }
 
 // Valid reports whether data is a valid JSON encoding.
 func Valid(data []byte) bool {
-	var v interface{}
-	decoder := NewDecoder(bytes.NewReader(data))
-	err := decoder.Decode(&v)
-	if err != nil {
-		return false
-	}
-	if !decoder.More() {
-		return true
-	}
-	return decoder.InputOffset() >= int64(len(data))
+	return scanner.Valid(data)
 }
 
 func init() {
@@ -0,0 +1,242 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/decoder"
+	"github.com/goccy/go-json/internal/encoder"
+	"github.com/goccy/go-json/internal/errors"
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+// lineReader reads a single line from the underlying reader.
+// It returns io.EOF at the end of the line, and next() moves it to the next line.
+type lineReader struct {
+	r    *bufio.Reader
+	buf  []byte // unread bytes of the current line
+	done bool   // whether the end of the current line has been read from r
+	eof  bool   // whether the end of r has been reached
+	err  error  // error returned from r other than io.EOF
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *lineReader) fill() error {
+	line, err := r.r.ReadSlice('\n')
+	r.buf = line
+	switch err {
+	case nil:
+		r.done = true
+	case bufio.ErrBufferFull:
+		// the line is longer than the buffer. continue reading.
+	case io.EOF:
+		r.done = true
+		r.eof = true
+	default:
+		r.err = err
+		return err
+	}
+	return nil
+}
+
+// skip discards the rest of the current line.
+func (r *lineReader) skip() error {
+	r.buf = nil
+	for !r.done {
+		if err := r.fill(); err != nil {
+			return err
+		}
+		r.buf = nil
+	}
+	return nil
+}
+
+// next moves to the next line. It returns false if there are no more lines.
+func (r *lineReader) next() bool {
+	if r.eof || r.err != nil {
+		return false
+	}
+	r.buf = nil
+	r.done = false
+	return true
+}
+
+// A LinesDecoder reads and decodes JSON Lines ( newline delimited JSON ) from an input stream.
+// Each line must contain exactly one JSON value. Blank lines are skipped.
+type LinesDecoder struct {
+	lr          *lineReader
+	s           *decoder.Stream // reused for each line
+	line        int
+	skipInvalid bool
+	errs        []error
+}
+
+// NewLinesDecoder returns a new decoder that reads JSON Lines from r.
+func NewLinesDecoder(r io.Reader) *LinesDecoder {
+	return &LinesDecoder{
+		lr: &lineReader{r: bufio.NewReader(r), done: true},
+	}
+}
+
+// SkipInvalidLines causes the LinesDecoder to skip lines that cannot be decoded instead of returning an error.
+// The errors of the skipped lines are collected and can be retrieved by Errors.
+func (d *LinesDecoder) SkipInvalidLines() {
+	d.skipInvalid = true
+}
+
+// Errors returns the errors of the lines skipped by SkipInvalidLines.
+// Each error is a *LineError.
+func (d *LinesDecoder) Errors() []error {
+	return d.errs
+}
+
+// Line returns the 1-based line number of the last decoded line.
+func (d *LinesDecoder) Line() int {
+	return d.line
+}
+
+// Decode reads the next non-blank line and stores the decoded value in the value pointed to by v.
+// It returns io.EOF when there are no more lines. Decoding errors are returned as *LineError.
+func (d *LinesDecoder) Decode(v interface{}) error {
+	return d.DecodeWithOption(v)
+}
+
+// DecodeWithOption call Decode with DecodeOption.
+func (d *LinesDecoder) DecodeWithOption(v interface{}, optFuncs ...DecodeOptionFunc) error {
+	header := (*emptyInterface)(unsafe.Pointer(&v))
+	typ := header.typ
+	ptr := uintptr(header.ptr)
+	typeptr := uintptr(unsafe.Pointer(typ))
+	// noescape trick for header.typ ( reflect.*rtype )
+	copiedType := *(**runtime.Type)(unsafe.Pointer(&typeptr))
+
+	if err := validateType(copiedType, ptr); err != nil {
+		return err
+	}
+
+	// the options of the stream are reset for each line, so the options are applied here only to compile the decoder.
+	opt := &decoder.Option{}
+	for _, optFunc := range optFuncs {
+		optFunc(opt)
+	}
+	dec, err := decoder.CompileToGetDecoderWithOption(typ, opt)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := d.lr.skip(); err != nil {
+			return err
+		}
+		if !d.lr.next() {
+			if d.lr.err != nil {
+				return d.lr.err
+			}
+			return io.EOF
+		}
+		d.line++
+		err := d.decodeLine(dec, header.ptr, optFuncs...)
+		if err == io.EOF {
+			// blank line
+			continue
+		}
+		if err == nil {
+			return nil
+		}
+		if d.lr.err != nil {
+			return d.lr.err
+		}
+		lineErr := &LineError{Line: d.line, Err: err}
+		if !d.skipInvalid {
+			return lineErr
+		}
+		d.errs = append(d.errs, lineErr)
+		// discard the value partially decoded from the invalid line.
+		rv := reflect.ValueOf(v).Elem()
+		rv.Set(reflect.Zero(rv.Type()))
+	}
+}
+
+func (d *LinesDecoder) decodeLine(dec decoder.Decoder, p unsafe.Pointer, optFuncs ...DecodeOptionFunc) error {
+	// the stream only reads the current line, so the value cannot span multiple lines.
+	s := d.s
+	if s == nil {
+		s = decoder.NewStream(d.lr)
+		s.SetLine(d.line)
+		d.s = s
+	} else {
+		s.ResetReader(d.lr, d.line)
+		*s.Option = decoder.Option{}
+	}
+	for _, optFunc := range optFuncs {
+		optFunc(s.Option)
+	}
+	if err := s.PrepareForDecode(); err != nil {
+		return err
+	}
+	if err := dec.DecodeStream(s, 0, p); err != nil {
+		return s.LocateError(err)
+	}
+	return s.LocateError(s.ValidateEnd())
+}
+
+// A LinesEncoder writes JSON values to an output stream as JSON Lines ( newline delimited JSON ).
+// Each value is written in compact form followed by a newline character.
+type LinesEncoder struct {
+	enc *Encoder
+}
+
+// NewLinesEncoder returns a new encoder that writes JSON Lines to w.
+func NewLinesEncoder(w io.Writer) *LinesEncoder {
+	return &LinesEncoder{enc: NewEncoder(w)}
+}
+
+// SetEscapeHTML specifies whether problematic HTML characters should be escaped inside JSON quoted strings.
+func (e *LinesEncoder) SetEscapeHTML(on bool) {
+	e.enc.SetEscapeHTML(on)
+}
+
+// Encode writes the JSON encoding of v as a single line to the stream.
+func (e *LinesEncoder) Encode(v interface{}) error {
+	return e.EncodeWithOption(v)
+}
+
+// EncodeWithOption call Encode with EncodeOption.
+func (e *LinesEncoder) EncodeWithOption(v interface{}, optFuncs ...EncodeOptionFunc) error {
+	ctx := encoder.TakeRuntimeContext()
+	ctx.Option.Flag = 0
+	e.enc.setupOption(ctx, optFuncs...)
+
+	buf, err := encode(ctx, v)
+	if err != nil {
+		encoder.ReleaseRuntimeContext(ctx)
+		return err
+	}
+	ctx.Buf = buf
+	if idx := bytes.IndexByte(buf, '\n'); idx >= 0 {
+		encoder.ReleaseRuntimeContext(ctx)
+		return &errors.UnsupportedValueError{
+			Value: reflect.ValueOf(v),
+			Str:   fmt.Sprintf("encoded value contains a newline character at %d", idx),
+		}
+	}
+	err = e.enc.write(buf)
+	encoder.ReleaseRuntimeContext(ctx)
+	return err
+}
@@ -0,0 +1,166 @@
+package json_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestLinesDecoder(t *testing.T) {
+	type T struct {
+		A int    `json:"a"`
+		B string `json:"b"`
+	}
+	t.Run("decode", func(t *testing.T) {
+		dec := json.NewLinesDecoder(strings.NewReader("{\"a\":1,\"b\":\"x\"}\n\n  {\"a\":2}  \r\n{\"a\":3,\"b\":\"z\"}"))
+		var got []T
+		var lines []int
+		for {
+			var v T
+			err := dec.Decode(&v)
+			if err == io.EOF {
+				break
+			}
+			assertErr(t, err)
+			got = append(got, v)
+			lines = append(lines, dec.Line())
+		}
+		assertEq(t, "length", 3, len(got))
+		assertEq(t, "first", T{A: 1, B: "x"}, got[0])
+		assertEq(t, "second", T{A: 2}, got[1])
+		assertEq(t, "third", T{A: 3, B: "z"}, got[2])
+		assertEq(t, "lines", "[1 3 4]", fmt.Sprint(lines))
+	})
+	t.Run("long line", func(t *testing.T) {
+		long := strings.Repeat("a", 10000)
+		dec := json.NewLinesDecoder(strings.NewReader(`{"b":"` + long + `"}` + "\n" + `{"b":"c"}`))
+		var v T
+		assertErr(t, dec.Decode(&v))
+		assertEq(t, "long", long, v.B)
+		assertErr(t, dec.Decode(&v))
+		assertEq(t, "short", "c", v.B)
+		assertEq(t, "eof", io.EOF, dec.Decode(&v))
+	})
+	t.Run("many lines", func(t *testing.T) {
+		// the stream is reused for each line, so the decoded strings must not be overwritten by the following lines.
+		var src strings.Builder
+		for i := 0; i < 200; i++ {
+			fmt.Fprintf(&src, "{\"a\":%d,\"b\":\"v%d\"}\n", i, i)
+		}
+		dec := json.NewLinesDecoder(strings.NewReader(src.String()))
+		var got []T
+		for {
+			var v T
+			err := dec.Decode(&v)
+			if err == io.EOF {
+				break
+			}
+			assertErr(t, err)
+			got = append(got, v)
+		}
+		assertEq(t, "length", 200, len(got))
+		for i, v := range got {
+			assertEq(t, "value", T{A: i, B: fmt.Sprintf("v%d", i)}, v)
+		}
+	})
+	t.Run("value spanning lines", func(t *testing.T) {
+		dec := json.NewLinesDecoder(strings.NewReader("{\"a\":\n1}\n"))
+		var v T
+		err := dec.Decode(&v)
+		var lineErr *json.LineError
+		if !errors.As(err, &lineErr) {
+			t.Fatalf("expected LineError but got %v", err)
+		}
+		assertEq(t, "line", 1, lineErr.Line)
+	})
+	t.Run("multiple values in a line", func(t *testing.T) {
+		dec := json.NewLinesDecoder(strings.NewReader("{\"a\":1}\n{\"a\":2} {\"a\":3}\n"))
+		var v T
+		assertErr(t, dec.Decode(&v))
+		err := dec.Decode(&v)
+		var lineErr *json.LineError
+		if !errors.As(err, &lineErr) {
+			t.Fatalf("expected LineError but got %v", err)
+		}
+		assertEq(t, "line", 2, lineErr.Line)
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("expected SyntaxError but got %T", lineErr.Err)
+		}
+		assertEq(t, "eof", io.EOF, dec.Decode(&v))
+	})
+	t.Run("skip invalid lines", func(t *testing.T) {
+		dec := json.NewLinesDecoder(strings.NewReader("{\"a\":1}\n{\"a\":\"x\",\"b\":\"y\"}\n{\"a\":\n{\"a\":4}\n"))
+		dec.SkipInvalidLines()
+		var got []T
+		for {
+			var v T
+			err := dec.Decode(&v)
+			if err == io.EOF {
+				break
+			}
+			assertErr(t, err)
+			got = append(got, v)
+		}
+		assertEq(t, "length", 2, len(got))
+		assertEq(t, "first", T{A: 1}, got[0])
+		assertEq(t, "second", T{A: 4}, got[1])
+		errs := dec.Errors()
+		assertEq(t, "errors", 2, len(errs))
+		for i, line := range []int{2, 3} {
+			var lineErr *json.LineError
+			if !errors.As(errs[i], &lineErr) {
+				t.Fatalf("expected LineError but got %v", errs[i])
+			}
+			assertEq(t, "line", line, lineErr.Line)
+		}
+	})
+	t.Run("option", func(t *testing.T) {
+		dec := json.NewLinesDecoder(strings.NewReader("{\"a\":1,\"c\":2}\n"))
+		var v T
+		err := dec.DecodeWithOption(&v, json.DecodeDisallowUnknownFields())
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		assertEq(t, "error", `json: line 1: json: unknown field "c"`, err.Error())
+	})
+}
+
+func TestLinesEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := json.NewLinesEncoder(&buf)
+	assertErr(t, enc.Encode(map[string]interface{}{"a": []int{1, 2}}))
+	assertErr(t, enc.Encode("line1\nline2"))
+	assertErr(t, enc.Encode(json.RawMessage("{\n  \"b\": true\n}")))
+	assertErr(t, enc.Encode(nil))
+	expected := `{"a":[1,2]}` + "\n" + `"line1\nline2"` + "\n" + `{"b":true}` + "\n" + "null\n"
+	assertEq(t, "lines", expected, buf.String())
+
+	dec := json.NewLinesDecoder(&buf)
+	var count int
+	for {
+		var v interface{}
+		if err := dec.Decode(&v); err == io.EOF {
+			break
+		} else {
+			assertErr(t, err)
+		}
+		count++
+	}
+	assertEq(t, "count", 4, count)
+
+	t.Run("newline in encoded value", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := json.NewLinesEncoder(&buf).Encode(appendJSONRaw("[1,\n2]"))
+		var unsupportedErr *json.UnsupportedValueError
+		if !errors.As(err, &unsupportedErr) {
+			t.Fatalf("expected UnsupportedValueError but got %v", err)
+		}
+		assertEq(t, "output", "", buf.String())
+	})
+}
@@ -0,0 +1,72 @@
+package json
+
+import (
+	"github.com/goccy/go-json/internal/dom"
+)
+
+// Node is the mutable node of the ordered document tree.
+// Decoding to Node keeps the order and the duplicate names of the members of object,
+// and keeps the literals of numbers without converting them to float64,
+// so Marshal of the decoded Node reproduces the document except for whitespace and string escapes.
+// The zero value is null, and the nil *Node is encoded as null.
+//
+//	var doc json.Node
+//	if err := json.Unmarshal(src, &doc); err != nil { ... }
+//	doc.Set("openapi", json.NewStringNode("3.1.0"))
+//	b, err := json.MarshalIndent(&doc, "", "  ")
+//
+// The mutation methods return *NodeError if the operation is not applicable to the kind of node.
+// A Node must not be inserted into itself.
+type Node = dom.Node
+
+// NodeMember is the member of object node.
+type NodeMember = dom.Member
+
+// NodeKind is the kind of JSON value that the Node represents.
+type NodeKind = dom.Kind
+
+const (
+	NullNode   NodeKind = dom.Null
+	BoolNode   NodeKind = dom.Bool
+	NumberNode NodeKind = dom.Number
+	StringNode NodeKind = dom.String
+	ObjectNode NodeKind = dom.Object
+	ArrayNode  NodeKind = dom.Array
+)
+
+// NewNullNode returns the null node.
+func NewNullNode() *Node {
+	return &Node{}
+}
+
+// NewBoolNode returns the bool node.
+func NewBoolNode(v bool) *Node {
+	n := dom.BoolOf(v)
+	return &n
+}
+
+// NewNumberNode returns the number node that has the literal of n.
+// The literal is kept as is, so it can represent the number that float64 can't represent.
+// Marshal returns error if the literal is not valid.
+func NewNumberNode(n Number) *Node {
+	v := dom.NumberOf(string(n))
+	return &v
+}
+
+// NewStringNode returns the string node.
+func NewStringNode(v string) *Node {
+	n := dom.StringOf(v)
+	return &n
+}
+
+// NewObjectNode returns the object node that has the members in order.
+func NewObjectNode(members ...NodeMember) *Node {
+	n := dom.ObjectOf(append([]NodeMember{}, members...))
+	return &n
+}
+
+// NewArrayNode returns the array node that has the elements.
+func NewArrayNode(elems ...*Node) *Node {
+	n := dom.ArrayOf(append([]*Node{}, elems...))
+	return &n
+}
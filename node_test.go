@@ -0,0 +1,260 @@
+package json_test
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+const nodeTestDocument = `{
+  "openapi": "3.1.0",
+  "info": {"title": "a \"quoted\" title", "version": "1.0"},
+  "paths": {
+    "/users": {"get": {"responses": {"200": {"description": "ok"}}}},
+    "/groups": {"post": {"deprecated": false, "tags": []}}
+  },
+  "x-big": 123456789012345678901234567890,
+  "x-float": 1.000000000000000000001,
+  "x-exp": -1.5e+300,
+  "x-dup": 1,
+  "x-dup": 2,
+  "x-list": [null, true, {}, [], "é"]
+}`
+
+func nodeTestExpected(t *testing.T, indent bool) string {
+	t.Helper()
+	var b bytes.Buffer
+	if indent {
+		if err := stdjson.Indent(&b, []byte(nodeTestDocument), "", "  "); err != nil {
+			t.Fatal(err)
+		}
+		// re-indent the compacted document so that the inline objects are expanded.
+		var compacted bytes.Buffer
+		if err := stdjson.Compact(&compacted, b.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+		b.Reset()
+		if err := stdjson.Indent(&b, compacted.Bytes(), "", "  "); err != nil {
+			t.Fatal(err)
+		}
+		return b.String()
+	}
+	if err := stdjson.Compact(&b, []byte(nodeTestDocument)); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestNode(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		var doc json.Node
+		if err := json.Unmarshal([]byte(nodeTestDocument), &doc); err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(&doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "compact", nodeTestExpected(t, false), string(got))
+
+		got, err = json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "indent", nodeTestExpected(t, true), string(got))
+	})
+	t.Run("stream", func(t *testing.T) {
+		var doc json.Node
+		dec := json.NewDecoder(strings.NewReader(nodeTestDocument + "\n" + nodeTestDocument))
+		for i := 0; i < 2; i++ {
+			if err := dec.Decode(&doc); err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(&doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEq(t, "stream", nodeTestExpected(t, false), string(got))
+		}
+	})
+	t.Run("accessors", func(t *testing.T) {
+		var doc json.Node
+		if err := json.Unmarshal([]byte(nodeTestDocument), &doc); err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "kind", json.ObjectNode, doc.Kind())
+		assertEq(t, "keys", "openapi info paths x-big x-float x-exp x-dup x-dup x-list", strings.Join(doc.Keys(), " "))
+		title, err := doc.Get("info").Get("title").String()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "string", `a "quoted" title`, title)
+		big, err := doc.Get("x-big").Number()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "number", json.Number("123456789012345678901234567890"), big)
+		dup, err := doc.Get("x-dup").Number()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "first duplicate", json.Number("1"), dup)
+		list := doc.Get("x-list")
+		assertEq(t, "len", 5, list.Len())
+		assertEq(t, "null", json.NullNode, list.Index(0).Kind())
+		b, err := list.Index(1).Bool()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "bool", true, b)
+		if doc.Get("missing") != nil || list.Index(5) != nil {
+			t.Fatal("expected nil")
+		}
+		var nodeErr *json.NodeError
+		if _, err := list.String(); !errors.As(err, &nodeErr) {
+			t.Fatalf("expected NodeError but got %v", err)
+		}
+	})
+	t.Run("mutation", func(t *testing.T) {
+		var doc json.Node
+		if err := json.Unmarshal([]byte(`{"b":1,"a":2,"c":3,"b":4}`), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.Set("a", json.NewStringNode("x")); err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.Set("d", json.NewArrayNode(json.NewBoolNode(false))); err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.Delete("b"); err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.InsertMember(0, "first", json.NewNullNode()); err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.Move(doc.IndexOf("d"), 1); err != nil {
+			t.Fatal(err)
+		}
+		arr := doc.Get("d")
+		if err := arr.Append(json.NewNumberNode("1e1000")); err != nil {
+			t.Fatal(err)
+		}
+		if err := arr.Insert(0, json.NewObjectNode(json.NodeMember{Key: "k", Value: json.NewStringNode("<v>")})); err != nil {
+			t.Fatal(err)
+		}
+		if err := arr.Move(0, 2); err != nil {
+			t.Fatal(err)
+		}
+		if err := arr.SetIndex(0, nil); err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(&doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "mutated", `{"first":null,"d":[null,1e1000,{"k":"\u003cv\u003e"}],"a":"x","c":3}`, string(got))
+		if err := doc.Remove(0); err != nil {
+			t.Fatal(err)
+		}
+		got, err = json.MarshalWithOption(&doc, json.DisableHTMLEscape())
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "removed", `{"d":[null,1e1000,{"k":"<v>"}],"a":"x","c":3}`, string(got))
+	})
+	t.Run("mutation error", func(t *testing.T) {
+		arr := json.NewArrayNode()
+		str := json.NewStringNode("s")
+		for _, err := range []error{
+			arr.Set("a", nil),
+			arr.Delete("a"),
+			arr.InsertMember(0, "a", nil),
+			arr.Insert(1, nil),
+			arr.Remove(0),
+			arr.Move(0, 0),
+			str.Append(nil),
+			str.SetIndex(0, nil),
+		} {
+			var nodeErr *json.NodeError
+			if !errors.As(err, &nodeErr) {
+				t.Fatalf("expected NodeError but got %v", err)
+			}
+		}
+	})
+	t.Run("field", func(t *testing.T) {
+		type T struct {
+			A json.Node            `json:"a"`
+			B *json.Node           `json:"b"`
+			C *json.Node           `json:"c,omitempty"`
+			D []*json.Node         `json:"d"`
+			E map[string]json.Node `json:"e"`
+			F interface{}          `json:"f"`
+		}
+		var v T
+		src := `{"a":{"z":1,"y":2},"b":[1.50,"x"],"d":[{"b":1,"a":2},null],"e":{"k":{"z":0,"a":0}},"f":null}`
+		if err := json.Unmarshal([]byte(src), &v); err != nil {
+			t.Fatal(err)
+		}
+		if v.D[1].Kind() != json.NullNode {
+			t.Fatalf("expected null node but got %v", v.D[1].Kind())
+		}
+		v.F = v.B
+		got, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "struct", `{"a":{"z":1,"y":2},"b":[1.50,"x"],"d":[{"b":1,"a":2},null],"e":{"k":{"z":0,"a":0}},"f":[1.50,"x"]}`, string(got))
+		got, err = json.Marshal(&T{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "zero", `{"a":null,"b":null,"d":null,"e":null,"f":null}`, string(got))
+	})
+	t.Run("colorize", func(t *testing.T) {
+		scheme := &json.ColorScheme{
+			Int:       json.ColorFormat{Header: "<i>", Footer: "</i>"},
+			String:    json.ColorFormat{Header: "<s>", Footer: "</s>"},
+			Bool:      json.ColorFormat{Header: "<b>", Footer: "</b>"},
+			ObjectKey: json.ColorFormat{Header: "<k>", Footer: "</k>"},
+			Null:      json.ColorFormat{Header: "<n>", Footer: "</n>"},
+		}
+		var doc json.Node
+		if err := json.Unmarshal([]byte(`{"a": [1, "x", true, null]}`), &doc); err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.MarshalWithOption(&doc, json.Colorize(scheme))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "compact", `{<k>"a"</k>:[<i>1</i>,<s>"x"</s>,<b>true</b>,<n>null</n>]}`, string(got))
+
+		got, err = json.MarshalIndentWithOption(&doc, "", " ", json.Colorize(scheme))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "indent", "{\n <k>\"a\"</k>: [\n  <i>1</i>,\n  <s>\"x\"</s>,\n  <b>true</b>,\n  <n>null</n>\n ]\n}", string(got))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, src := range []string{`01`, `1.`, `-`, `1e`, `{"a" 1}`, `[1 2]`, `{1:2}`, `tru`} {
+			var doc json.Node
+			if err := json.Unmarshal([]byte(src), &doc); err == nil {
+				t.Fatalf("expected error for %q", src)
+			}
+		}
+		if _, err := json.Marshal(json.NewNumberNode("0x10")); err == nil {
+			t.Fatal("expected error for invalid number literal")
+		}
+		self := json.NewArrayNode()
+		if err := self.Append(self); err != nil {
+			t.Fatal(err)
+		}
+		var unsupportedErr *json.UnsupportedValueError
+		if _, err := json.Marshal(self); !errors.As(err, &unsupportedErr) {
+			t.Fatalf("expected UnsupportedValueError but got %v", err)
+		}
+	})
+}
@@ -63,6 +63,42 @@ func Colorize(scheme *ColorScheme) EncodeOptionFunc {
 	}
 }
 
+// MarshalFunc encodes the values of type T by fn instead of the default encoding of T,
+// similar to the MarshalJSON method but without declaring it on T.
+// The result of fn must be a valid JSON value, and it is compacted like the result of MarshalJSON.
+// If T is not a pointer type, the pointer to T is encoded by fn too unless it is nil.
+// The function takes precedence over the MarshalJSON and MarshalText methods and the functions registered by RegisterMarshalFunc,
+// and if more than one function is given for the same type, the last one wins.
+// The keys of maps are not encoded by the function.
+func MarshalFunc[T any](fn func(T) ([]byte, error)) EncodeOptionFunc {
+	f := &encoder.TypeMarshalFunc{
+		Type: typeOf[T](),
+		Func: func(v interface{}) ([]byte, error) {
+			return fn(v.(T))
+		},
+	}
+	return func(opt *EncodeOption) {
+		opt.AddMarshalFunc(f)
+	}
+}
+
+// RegisterMarshalFunc registers fn to encode the values of type T for all the encodings,
+// as if MarshalFunc(fn) is always given as the option.
+// It is intended to be called in the init function, because the compiled encoders are discarded every time it is called.
+func RegisterMarshalFunc[T any](fn func(T) ([]byte, error)) {
+	encoder.RegisterMarshalFunc(typeOf[T](), func(v interface{}) ([]byte, error) {
+		return fn(v.(T))
+	})
+}
+
+// ValidateAppendJSON validates the values appended by the AppendJSON method of MarshalerTo.
+// By default, they are trusted to be valid and compact JSON values to avoid scanning them again.
+func ValidateAppendJSON() EncodeOptionFunc {
+	return func(opt *EncodeOption) {
+		opt.Flag |= encoder.ValidateAppendJSONOption
+	}
+}
+
 type DecodeOption = decoder.Option
 type DecodeOptionFunc func(*DecodeOption)
 
@@ -77,3 +113,94 @@ func DecodeFieldPriorityFirstWin() DecodeOptionFunc {
 		opt.Flags |= decoder.FirstWinOption
 	}
 }
+
+// DecodeDisallowUnknownFields causes an error to be returned when the destination
+// is a struct and the input contains object keys which do not match any
+// non-ignored, exported fields in the destination.
+// Unlike Decoder.DisallowUnknownFields, this option can be used with Unmarshal and UnmarshalWithOption.
+func DecodeDisallowUnknownFields() DecodeOptionFunc {
+	return func(opt *DecodeOption) {
+		opt.Flags |= decoder.DisallowUnknownFieldsOption
+	}
+}
+
+// DecodeUseNumber causes a number to be unmarshaled into an interface{} as a
+// Number instead of as a float64.
+// Unlike Decoder.UseNumber, this option can be used with Unmarshal and UnmarshalWithOption.
+func DecodeUseNumber() DecodeOptionFunc {
+	return func(opt *DecodeOption) {
+		opt.Flags |= decoder.UseNumberOption
+	}
+}
+
+// DecodeCollectErrors causes Unmarshal to continue decoding after a JSON value
+// is not appropriate for the Go type. Such values are skipped, and Unmarshal returns
+// *UnmarshalErrors listing every UnmarshalTypeError with the JSON Pointer to the value.
+// Syntax errors still stop the decoding, and are also listed in *UnmarshalErrors.
+// This option is only supported by Unmarshal and UnmarshalWithOption, not by Decoder.
+func DecodeCollectErrors() DecodeOptionFunc {
+	return func(opt *DecodeOption) {
+		opt.Flags |= decoder.CollectErrorsOption
+	}
+}
+
+// DecodeCaseSensitive causes the object keys to be matched to the struct fields case-sensitively.
+// In the default behavior, like encoding/json, a key matches the field whose name is equal to it ignoring the case,
+// so {"ID":1,"id":2} sets the same field twice.
+// To match only some fields case-sensitively, use the strictcase option of the struct tag ( e.g. `json:"id,strictcase"` ).
+func DecodeCaseSensitive() DecodeOptionFunc {
+	return func(opt *DecodeOption) {
+		opt.Flags |= decoder.CaseSensitiveOption
+	}
+}
+
+// DecodeDisallowDuplicateKeys causes the decoding to return *DuplicateKeyError
+// when an object that is decoded to struct, map or interface{} has the same key more than once.
+// For struct, the keys that match the same field are duplicates, and the unknown keys are not checked.
+// DecodeFieldPriorityFirstWin only changes which value wins, so this option takes precedence over it.
+func DecodeDisallowDuplicateKeys() DecodeOptionFunc {
+	return func(opt *DecodeOption) {
+		opt.Flags |= decoder.DisallowDuplicateKeysOption
+	}
+}
+
+// UnmarshalFunc decodes the JSON values into type T by fn instead of the default decoding of T,
+// similar to the UnmarshalJSON method but without declaring it on T.
+// fn receives the JSON value as it is, including null.
+// If T is not a pointer type, the pointer to T is allocated for the non-null value and decoded by fn too.
+// The function takes precedence over the UnmarshalJSON and UnmarshalText methods and the functions registered by RegisterUnmarshalFunc,
+// and if more than one function is given for the same type, the last one wins.
+// The keys of maps are not decoded by the function.
+func UnmarshalFunc[T any](fn func([]byte, *T) error) DecodeOptionFunc {
+	f := &decoder.TypeUnmarshalFunc{
+		Type: typeOf[T](),
+		Func: func(data []byte, v interface{}) error {
+			return fn(data, v.(*T))
+		},
+	}
+	return func(opt *DecodeOption) {
+		opt.AddUnmarshalFunc(f)
+	}
+}
+
+// RegisterUnmarshalFunc registers fn to decode the JSON values into type T for all the decodings,
+// as if UnmarshalFunc(fn) is always given as the option.
+// It is intended to be called in the init function, because the compiled decoders are discarded every time it is called.
+func RegisterUnmarshalFunc[T any](fn func([]byte, *T) error) {
+	decoder.RegisterUnmarshalFunc(typeOf[T](), func(data []byte, v interface{}) error {
+		return fn(data, v.(*T))
+	})
+}
+
+type PathOption = decoder.PathBuildOption
+type PathOptionFunc func(*PathOption)
+
+// PathRFC9535 causes CreatePath to build JSON Path that follows RFC 9535 strictly.
+// In addition to the selectors and the filter expression of RFC 9535, the function extensions
+// length(), count(), match(), search() and value() can be used.
+// The path that doesn't conform to RFC 9535 grammar or is not well-typed is rejected by CreatePath.
+func PathRFC9535() PathOptionFunc {
+	return func(opt *PathOption) {
+		opt.RFC9535 = true
+	}
+}
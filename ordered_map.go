@@ -0,0 +1,90 @@
+package json
+
+// OrderedMap is the map that keeps the insertion order of keys.
+// Marshal encodes the entries in insertion order, and Unmarshal decodes the members of object in document order.
+// If the object has duplicate keys, the key keeps the position of the first member and takes the value of the last member.
+// Unmarshal merges the members into the existing entries in the same way as Set.
+// The key type must be the type that can be the key of JSON object ( string, integer or encoding.TextMarshaler ).
+// The zero value is the empty map that is ready to use, and the nil *OrderedMap is encoded as null.
+// A copy of OrderedMap shares the entries with the original like the built-in map, so only one of them should be modified.
+//
+//	var m json.OrderedMap[string, int]
+//	m.Set("b", 1)
+//	m.Set("a", 2)
+//	b, err := json.Marshal(&m) // {"b":1,"a":2}
+//
+// The layout of OrderedMap is referred by the encoder and the decoder, so the fields must not be changed without them.
+type OrderedMap[K comparable, V any] struct {
+	entries []orderedMapEntry[K, V]
+	index   map[K]int // key => position in entries
+}
+
+type orderedMapEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// Len returns the number of entries.
+func (m *OrderedMap[K, V]) Len() int {
+	return len(m.entries)
+}
+
+// Get returns the value of the key.
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	if i, exists := m.index[key]; exists {
+		return m.entries[i].value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Set sets the value of the key. If the key already exists, it keeps the position and replaces the value.
+// Otherwise it appends the entry to the end.
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	if i, exists := m.index[key]; exists {
+		m.entries[i].value = value
+		return
+	}
+	if m.index == nil {
+		m.index = map[K]int{}
+	}
+	m.index[key] = len(m.entries)
+	m.entries = append(m.entries, orderedMapEntry[K, V]{key: key, value: value})
+}
+
+// Delete removes the entry of the key and reports whether the key existed.
+// The entries after the removed entry keep their order.
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	i, exists := m.index[key]
+	if !exists {
+		return false
+	}
+	delete(m.index, key)
+	copy(m.entries[i:], m.entries[i+1:])
+	// release the references from the removed entry.
+	m.entries[len(m.entries)-1] = orderedMapEntry[K, V]{}
+	m.entries = m.entries[:len(m.entries)-1]
+	for j := i; j < len(m.entries); j++ {
+		m.index[m.entries[j].key] = j
+	}
+	return true
+}
+
+// Keys returns the keys in order.
+func (m *OrderedMap[K, V]) Keys() []K {
+	keys := make([]K, 0, len(m.entries))
+	for _, e := range m.entries {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// Range calls f for each entry in order. If f returns false, Range stops the iteration.
+// f must not modify the map.
+func (m *OrderedMap[K, V]) Range(f func(key K, value V) bool) {
+	for _, e := range m.entries {
+		if !f(e.key, e.value) {
+			return
+		}
+	}
+}
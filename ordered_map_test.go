@@ -0,0 +1,183 @@
+package json_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestOrderedMap(t *testing.T) {
+	t.Run("insertion order", func(t *testing.T) {
+		var m json.OrderedMap[string, int]
+		m.Set("z", 1)
+		m.Set("a", 2)
+		m.Set("m", 3)
+		m.Set("z", 4)
+		got, err := json.Marshal(&m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "marshal", `{"z":4,"a":2,"m":3}`, string(got))
+
+		got, err = json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "indent", "{\n  \"z\": 4,\n  \"a\": 2,\n  \"m\": 3\n}", string(got))
+	})
+	t.Run("empty and nil", func(t *testing.T) {
+		got, err := json.Marshal(json.OrderedMap[string, int]{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "empty", `{}`, string(got))
+
+		got, err = json.Marshal((*json.OrderedMap[string, int])(nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "nil", `null`, string(got))
+	})
+	t.Run("delete", func(t *testing.T) {
+		var m json.OrderedMap[string, int]
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		assertEq(t, "deleted", true, m.Delete("a"))
+		assertEq(t, "not found", false, m.Delete("a"))
+		m.Set("a", 4)
+		v, ok := m.Get("c")
+		assertEq(t, "found", true, ok)
+		assertEq(t, "value", 3, v)
+		assertEq(t, "keys", "b,c,a", strings.Join(m.Keys(), ","))
+	})
+	t.Run("decode order", func(t *testing.T) {
+		var m json.OrderedMap[string, int]
+		if err := json.Unmarshal([]byte(`{"z":1,"a":2,"z":3,"m":4}`), &m); err != nil {
+			t.Fatal(err)
+		}
+		// the duplicate key keeps the first position and takes the last value.
+		assertEq(t, "keys", "z,a,m", strings.Join(m.Keys(), ","))
+		v, _ := m.Get("z")
+		assertEq(t, "value", 3, v)
+
+		if err := json.Unmarshal([]byte(`{"b":5,"a":6}`), &m); err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(&m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "merged", `{"z":3,"a":6,"m":4,"b":5}`, string(got))
+
+		if err := json.Unmarshal([]byte(`null`), &m); err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "null", 0, m.Len())
+	})
+	t.Run("field", func(t *testing.T) {
+		type T struct {
+			A json.OrderedMap[int, []string]
+			B *json.OrderedMap[string, json.OrderedMap[string, bool]]
+			C []json.OrderedMap[string, struct{ X int }]
+			D *json.OrderedMap[string, int] `json:",omitempty"`
+		}
+		src := `{"A":{"3":["x"],"1":null,"2":[]},"B":{"k":{"q":true,"p":false},"j":{}},"C":[{"s":{"X":5},"r":{"X":6}},{}]}`
+		var v T
+		if err := json.Unmarshal([]byte(src), &v); err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "unmarshal", src, string(got))
+
+		var stream T
+		if err := json.NewDecoder(strings.NewReader(src)).Decode(&stream); err != nil {
+			t.Fatal(err)
+		}
+		got, err = json.Marshal(&stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "stream", src, string(got))
+
+		var iface interface{} = v
+		got, err = json.Marshal(&iface)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "interface", src, string(got))
+	})
+	t.Run("omitempty", func(t *testing.T) {
+		type T struct {
+			A json.OrderedMap[string, int]  `json:"a,omitempty"`
+			B *json.OrderedMap[string, int] `json:"b,omitempty"`
+			C json.OrderedMap[string, int]  `json:"c,omitempty,omitzero"`
+			D int                           `json:"d"`
+		}
+		var v T
+		got, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "zero", `{"d":0}`, string(got))
+
+		// the map that had entries is also empty.
+		v.A.Set("x", 1)
+		v.A.Delete("x")
+		v.B = &json.OrderedMap[string, int]{}
+		got, err = json.MarshalIndent(v, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "empty", "{\n\"b\": {},\n\"d\": 0\n}", string(got))
+
+		v.A.Set("y", 2)
+		v.C.Set("z", 3)
+		got, err = json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "entries", `{"a":{"y":2},"b":{},"c":{"z":3},"d":0}`, string(got))
+	})
+	t.Run("indent", func(t *testing.T) {
+		var inner json.OrderedMap[string, []int]
+		inner.Set("b", []int{1})
+		inner.Set("a", nil)
+		v := struct {
+			M json.OrderedMap[string, json.OrderedMap[string, []int]]
+		}{}
+		v.M.Set("x", inner)
+		got, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := `{
+  "M": {
+    "x": {
+      "b": [
+        1
+      ],
+      "a": null
+    }
+  }
+}`
+		assertEq(t, "indent", expected, string(got))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var m json.OrderedMap[string, int]
+		if err := json.Unmarshal([]byte(`[1]`), &m); err == nil {
+			t.Fatal("expected error")
+		}
+		if err := json.Unmarshal([]byte(`{"a":"x"}`), &m); err == nil {
+			t.Fatal("expected error")
+		}
+		assertEq(t, "len", 0, m.Len())
+		if err := json.NewDecoder(strings.NewReader(`{"a":1,}`)).Decode(&m); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
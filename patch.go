@@ -0,0 +1,34 @@
+package json
+
+import (
+	"github.com/goccy/go-json/internal/decoder"
+)
+
+// ApplyPatch applies JSON Patch ( RFC 6902 ) to doc and returns the patched document.
+//
+// The operations are applied on the raw JSON bytes, so the untouched part of doc
+// ( including the key order, the whitespace and the number literals ) is preserved byte for byte.
+// If an operation can not be applied, ApplyPatch returns *PatchError that wraps *PathError.
+func ApplyPatch(doc, patch []byte) ([]byte, error) {
+	return patchDocuments(doc, patch, decoder.ApplyPatch)
+}
+
+// CreatePatch creates JSON Patch ( RFC 6902 ) that transforms a into b.
+// The values in the created patch are copied from b byte for byte.
+func CreatePatch(a, b []byte) ([]byte, error) {
+	return patchDocuments(a, b, decoder.CreatePatch)
+}
+
+// MergePatch applies JSON Merge Patch ( RFC 7386 ) to doc and returns the merged document.
+// The untouched part of doc is preserved byte for byte.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	return patchDocuments(doc, patch, decoder.MergePatch)
+}
+
+func patchDocuments(a, b []byte, patch func(a, b []byte) ([]byte, error)) ([]byte, error) {
+	srcA := make([]byte, len(a)+1) // append nul byte to the end
+	copy(srcA, a)
+	srcB := make([]byte, len(b)+1) // append nul byte to the end
+	copy(srcB, b)
+	return patch(srcA, srcB)
+}
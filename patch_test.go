@@ -0,0 +1,285 @@
+package json_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+	}{
+		// RFC 6902 Appendix A
+		{
+			name:     "add object member",
+			doc:      `{"foo": "bar"}`,
+			patch:    `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			expected: `{"foo": "bar","baz":"qux"}`,
+		},
+		{
+			name:     "add array element",
+			doc:      `{"foo": ["bar", "baz"]}`,
+			patch:    `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			expected: `{"foo": ["bar", "qux","baz"]}`,
+		},
+		{
+			name:     "remove object member",
+			doc:      `{"baz": "qux", "foo": "bar"}`,
+			patch:    `[{"op": "remove", "path": "/baz"}]`,
+			expected: `{"foo": "bar"}`,
+		},
+		{
+			name:     "remove array element",
+			doc:      `{"foo": ["bar", "qux", "baz"]}`,
+			patch:    `[{"op": "remove", "path": "/foo/1"}]`,
+			expected: `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:     "replace value",
+			doc:      `{"baz": "qux", "foo": "bar"}`,
+			patch:    `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			expected: `{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			name:     "move value",
+			doc:      `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch:    `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			expected: `{"foo": {"bar": "baz"}, "qux": {"corge": "grault","thud":"fred"}}`,
+		},
+		{
+			name:     "move array element",
+			doc:      `{"foo": ["all", "grass", "cows", "eat"]}`,
+			patch:    `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			expected: `{"foo": ["all", "cows", "eat","grass"]}`,
+		},
+		{
+			name:     "test value",
+			doc:      `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			patch:    `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2.0}]`,
+			expected: `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{
+			name:     "add nested member object",
+			doc:      `{"foo": "bar"}`,
+			patch:    `[{"op": "add", "path": "/child", "value": { "grandchild": { } }}]`,
+			expected: `{"foo": "bar","child":{ "grandchild": { } }}`,
+		},
+		{
+			name:     "ignore unrecognized elements",
+			doc:      `{"foo": "bar"}`,
+			patch:    `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+			expected: `{"foo": "bar","baz":"qux"}`,
+		},
+		{
+			name:     "escape ~ and /",
+			doc:      `{"/": 9, "~1": 10}`,
+			patch:    `[{"op": "test", "path": "/~01", "value": 10}]`,
+			expected: `{"/": 9, "~1": 10}`,
+		},
+		{
+			name:     "add array value",
+			doc:      `{"foo": ["bar"]}`,
+			patch:    `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			expected: `{"foo": ["bar",["abc", "def"]]}`,
+		},
+		// additional cases
+		{
+			name:     "preserve number literals and formatting",
+			doc:      "{\n  \"id\": 12345678901234567890,\n  \"price\": 1.50,\n  \"tags\": []\n}",
+			patch:    `[{"op": "add", "path": "/tags/0", "value": "new"}, {"op": "replace", "path": "/price", "value": 1.5e0}]`,
+			expected: "{\n  \"id\": 12345678901234567890,\n  \"price\": 1.5e0,\n  \"tags\": [\"new\"]\n}",
+		},
+		{
+			name:     "replace root",
+			doc:      ` {"a": 1} `,
+			patch:    `[{"op": "replace", "path": "", "value": [1]}]`,
+			expected: ` [1] `,
+		},
+		{
+			name:     "copy value",
+			doc:      `{"a": {"b": [1, 2]}, "c": {}}`,
+			patch:    `[{"op": "copy", "from": "/a/b", "path": "/c/d"}]`,
+			expected: `{"a": {"b": [1, 2]}, "c": {"d":[1, 2]}}`,
+		},
+		{
+			name:     "remove last members",
+			doc:      `{"a": 1, "b": 2}`,
+			patch:    `[{"op": "remove", "path": "/b"}, {"op": "remove", "path": "/a"}, {"op": "add", "path": "/c", "value": 3}]`,
+			expected: `{"c":3}`,
+		},
+		{
+			name:     "test large integers",
+			doc:      `{"a": 9007199254740993}`,
+			patch:    `[{"op": "test", "path": "/a", "value": 9007199254740993.0}]`,
+			expected: `{"a": 9007199254740993}`,
+		},
+		{
+			name:     "test object ignores member order",
+			doc:      `{"a": {"x": 1, "y": [true, null]}}`,
+			patch:    `[{"op": "test", "path": "/a", "value": {"y": [true, null], "x": 1}}]`,
+			expected: `{"a": {"x": 1, "y": [true, null]}}`,
+		},
+		{
+			name:     "preserve escaped strings",
+			doc:      `{"a\"b": "x\ny", "c": 1}`,
+			patch:    `[{"op": "test", "path": "/a\"b", "value": "x\u000ay"}, {"op": "replace", "path": "/c", "value": 2}]`,
+			expected: `{"a\"b": "x\ny", "c": 2}`,
+		},
+		{
+			name:     "empty patch",
+			doc:      `{"a": 1}`,
+			patch:    `[]`,
+			expected: `{"a": 1}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patched, err := json.ApplyPatch([]byte(test.doc), []byte(test.patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEq(t, "patched", test.expected, string(patched))
+		})
+	}
+}
+
+func TestApplyPatchError(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		index int
+		op    string
+	}{
+		{name: "add to nonexistent target", doc: `{"foo": "bar"}`, patch: `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`, op: "add"},
+		{name: "test failed", doc: `{"baz": "qux", "foo": ["a", 2, "c"]}`, patch: `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/baz", "value": "bar"}]`, index: 1, op: "test"},
+		{name: "test string and number", doc: `{"/": 9}`, patch: `[{"op": "test", "path": "/~1", "value": "9"}]`, op: "test"},
+		{name: "index out of range", doc: `[1, 2]`, patch: `[{"op": "add", "path": "/3", "value": 3}]`, op: "add"},
+		{name: "invalid index", doc: `[1, 2]`, patch: `[{"op": "replace", "path": "/01", "value": 3}]`, op: "replace"},
+		{name: "remove nonexistent member", doc: `{"a": 1}`, patch: `[{"op": "remove", "path": "/b"}]`, op: "remove"},
+		{name: "remove root", doc: `{"a": 1}`, patch: `[{"op": "remove", "path": ""}]`, op: "remove"},
+		{name: "move into child", doc: `{"a": {"b": 1}}`, patch: `[{"op": "move", "from": "/a", "path": "/a/c"}]`, op: "move"},
+		{name: "missing value", doc: `{}`, patch: `[{"op": "add", "path": "/a"}]`, op: "add"},
+		{name: "unknown operation", doc: `{}`, patch: `[{"op": "add", "path": "/a", "value": 1}, {"op": "merge", "path": "/a"}]`, index: 1, op: "merge"},
+		{name: "invalid pointer", doc: `{}`, patch: `[{"op": "add", "path": "a", "value": 1}]`, op: "add"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := json.ApplyPatch([]byte(test.doc), []byte(test.patch))
+			var patchErr *json.PatchError
+			if !errors.As(err, &patchErr) {
+				t.Fatalf("expected PatchError but got %v", err)
+			}
+			assertEq(t, "index", test.index, patchErr.Index)
+			assertEq(t, "op", test.op, patchErr.Op)
+			var pathErr *json.PathError
+			if !errors.As(err, &pathErr) {
+				t.Fatalf("expected PathError but got %v", err)
+			}
+		})
+	}
+	t.Run("invalid json", func(t *testing.T) {
+		if _, err := json.ApplyPatch([]byte(`{"a": 1`), []byte(`[]`)); err == nil {
+			t.Fatal("expected error")
+		}
+		if _, err := json.ApplyPatch([]byte(`{}`), []byte(`[] []`)); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestCreatePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected string
+	}{
+		{name: "equal", a: `{"a": [1, {"b": 1.0}]}`, b: `{"a":[1,{"b":1}]}`, expected: `[]`},
+		{name: "replace root", a: `{"a": 1}`, b: `[1]`, expected: `[{"op":"replace","path":"","value":[1]}]`},
+		{
+			name:     "object",
+			a:        `{"a": 1, "b": {"c": "x", "d/e": true}, "f": null}`,
+			b:        `{"b": {"c": "y", "d/e": true, "g": 12345678901234567890}, "f": null, "h": [ 1 ]}`,
+			expected: `[{"op":"remove","path":"/a"},{"op":"replace","path":"/b/c","value":"y"},{"op":"add","path":"/b/g","value":12345678901234567890},{"op":"add","path":"/h","value":[ 1 ]}]`,
+		},
+		{
+			name:     "shorten array",
+			a:        `[1, 2, 3, 4]`,
+			b:        `[1, 5]`,
+			expected: `[{"op":"replace","path":"/1","value":5},{"op":"remove","path":"/3"},{"op":"remove","path":"/2"}]`,
+		},
+		{
+			name:     "extend array",
+			a:        `[1]`,
+			b:        `[1, 2, 3]`,
+			expected: `[{"op":"add","path":"/1","value":2},{"op":"add","path":"/2","value":3}]`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patch, err := json.CreatePatch([]byte(test.a), []byte(test.b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEq(t, "patch", test.expected, string(patch))
+			patched, err := json.ApplyPatch([]byte(test.a), patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := json.CreatePatch(patched, []byte(test.b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEq(t, "patch after applied", `[]`, string(again))
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		doc      string
+		patch    string
+		expected string
+	}{
+		// RFC 7386 Appendix A
+		{doc: `{"a":"b"}`, patch: `{"a":"c"}`, expected: `{"a":"c"}`},
+		{doc: `{"a":"b"}`, patch: `{"b":"c"}`, expected: `{"a":"b","b":"c"}`},
+		{doc: `{"a":"b"}`, patch: `{"a":null}`, expected: `{}`},
+		{doc: `{"a":"b","b":"c"}`, patch: `{"a":null}`, expected: `{"b":"c"}`},
+		{doc: `{"a":["b"]}`, patch: `{"a":"c"}`, expected: `{"a":"c"}`},
+		{doc: `{"a":"c"}`, patch: `{"a":["b"]}`, expected: `{"a":["b"]}`},
+		{doc: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, expected: `{"a":{"b":"d"}}`},
+		{doc: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, expected: `{"a":[1]}`},
+		{doc: `["a","b"]`, patch: `["c","d"]`, expected: `["c","d"]`},
+		{doc: `{"a":"b"}`, patch: `["c"]`, expected: `["c"]`},
+		{doc: `{"a":"foo"}`, patch: `null`, expected: `null`},
+		{doc: `{"a":"foo"}`, patch: `"bar"`, expected: `"bar"`},
+		{doc: `{"e":null}`, patch: `{"a":1}`, expected: `{"e":null,"a":1}`},
+		{doc: `[1,2]`, patch: `{"a":"b","c":null}`, expected: `{"a":"b"}`},
+		{doc: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, expected: `{"a":{"bb":{}}}`},
+		// additional cases
+		{
+			doc:      "{\n  \"title\": \"Goodbye!\",\n  \"author\": {\"givenName\": \"John\", \"familyName\": \"Doe\"},\n  \"price\": 1.50\n}",
+			patch:    `{"title": "Hello!", "phoneNumber": "+01-123-456-7890", "author": {"familyName": null}}`,
+			expected: "{\n  \"title\": \"Hello!\",\n  \"author\": {\"givenName\": \"John\"},\n  \"price\": 1.50,\"phoneNumber\":\"+01-123-456-7890\"\n}",
+		},
+		{doc: `{"a": 1, "b": 2}`, patch: `{"a": null, "b": null, "c": 3}`, expected: `{"c":3}`},
+		{doc: `{"a": 1, "b": 2}`, patch: `{"b": null, "c": 3}`, expected: `{"a": 1,"c":3}`},
+		{doc: `{"a\"b": "x\ny", "c": 1}`, patch: `{"c": 2, "a\u0022b": "x\ny"}`, expected: `{"a\"b": "x\ny", "c": 2}`},
+	}
+	for _, test := range tests {
+		t.Run(test.doc+" "+test.patch, func(t *testing.T) {
+			merged, err := json.MergePatch([]byte(test.doc), []byte(test.patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEq(t, "merged", test.expected, string(merged))
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package json
 
 import (
+	"io"
 	"reflect"
 
 	"github.com/goccy/go-json/internal/decoder"
+	"github.com/goccy/go-json/internal/scanner"
 )
 
 // CreatePath creates JSON Path.
@@ -14,14 +16,32 @@ import (
 // ..  : recursive descent.
 // []  : subscript operator. If the JSON object is an array, you can use brackets to specify the array index.
 // [*] : all objects/elements for array.
+// [-1]: negative index counts from the end of array.
+// [start:end:step] : array slice. Each part can be omitted, and negative step selects in reverse order. e.g.) `$.a[1:5:2]`, `$.a[::-1]`
+// [,]  : union of selectors. e.g.) `$['a','b']`, `$.a[0,2]`
+// [?()] : filter expression. `@` refers to the current element. e.g.) `$.items[?(@.price < 10 && @.tag == 'x')]`
 //
 // Reserved words must be properly escaped when included in Path.
 //
 // Escape Rule
 // single quote style escape: e.g.) `$['a.b'].c`
 // double quote style escape: e.g.) `$."a.b".c`
-func CreatePath(p string) (*Path, error) {
-	path, err := decoder.PathString(p).Build()
+//
+// If PathRFC9535 option is specified, the JSON Path is parsed and evaluated as defined by RFC 9535.
+func CreatePath(p string, optFuncs ...PathOptionFunc) (*Path, error) {
+	opt := &PathOption{}
+	for _, optFunc := range optFuncs {
+		optFunc(opt)
+	}
+	var (
+		path *decoder.Path
+		err  error
+	)
+	if opt.RFC9535 {
+		path, err = decoder.PathString(p).BuildRFC9535()
+	} else {
+		path, err = decoder.PathString(p).Build()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -49,8 +69,69 @@ func (p *Path) UsedDoubleQuotePathSelector() bool {
 }
 
 // Extract extracts a specific JSON string.
+// The path created with PathRFC9535 option returns an error if any decode option is specified.
 func (p *Path) Extract(data []byte, optFuncs ...DecodeOptionFunc) ([][]byte, error) {
-	return extractFromPath(p, data, optFuncs...)
+	return extractFromPath(p.path, data, optFuncs...)
+}
+
+// ExtractStream extracts the JSON values from r and calls fn with each of them in document order.
+// The values are found while r is read, so the whole input is not kept in memory:
+// only the selected values are buffered, and the other values are skipped by parts.
+// e.g.) `$.records[*]` delivers each record of a large export with the memory for one record.
+// The value passed to fn is not reused, so fn can retain it. If fn returns an error, ExtractStream stops and returns it.
+//
+// Negative indexes, slices with negative bounds or step, and filters that refer to the root ( $ ) need
+// the whole array or document, so the array or document is buffered for them.
+func (p *Path) ExtractStream(r io.Reader, fn func([]byte) error) error {
+	return extractStreamFromPath(p.path, r, fn)
+}
+
+// PathMatch is the JSON value selected by JSON Path.
+type PathMatch struct {
+	// Path is the normalized path of the value ( RFC 9535 section 2.7 ). e.g.) $['store']['book'][0]
+	Path  string
+	Value []byte
+}
+
+// ExtractWithPaths extracts the JSON values with their normalized paths.
+// The values are returned in the order of RFC 9535 evaluation even if the path is not built with PathRFC9535.
+func (p *Path) ExtractWithPaths(data []byte) ([]PathMatch, error) {
+	values, paths, err := extractWithPaths(p.path, data)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]PathMatch, len(values))
+	for i := range values {
+		matches[i] = PathMatch{Path: paths[i], Value: values[i]}
+	}
+	return matches, nil
+}
+
+// Set replaces the values selected by JSON Path in data with the JSON encoding of value,
+// and returns the edited copy of data. The rest of data is preserved byte for byte.
+// If the path selects a single member that doesn't exist, such as `$.user.ssn`,
+// the member is appended to the parent object.
+func (p *Path) Set(data []byte, value interface{}) ([]byte, error) {
+	encoded, err := Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if p.path.RootSelectorOnly {
+		// the whole document is replaced without scanning it, so it is validated here.
+		if err := scanner.Validate(data); err != nil {
+			return nil, err
+		}
+		return encoded, nil
+	}
+	return editFromPath(data, func(src []byte) ([]byte, int64, error) {
+		return p.path.Set(src, encoded)
+	})
+}
+
+// Delete removes the members or elements selected by JSON Path from data,
+// and returns the edited copy of data. The rest of data is preserved byte for byte.
+func (p *Path) Delete(data []byte) ([]byte, error) {
+	return editFromPath(data, p.path.Delete)
 }
 
 // PathString returns original JSON Path string.
@@ -60,7 +141,7 @@ func (p *Path) PathString() string {
 
 // Unmarshal extract and decode the value of the part corresponding to JSON Path from the input data.
 func (p *Path) Unmarshal(data []byte, v interface{}, optFuncs ...DecodeOptionFunc) error {
-	contents, err := extractFromPath(p, data, optFuncs...)
+	contents, err := extractFromPath(p.path, data, optFuncs...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,226 @@
+package json_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+type complianceTest struct {
+	Name            string          `json:"name"`
+	Selector        string          `json:"selector"`
+	Document        json.RawMessage `json:"document"`
+	Result          []interface{}   `json:"result"`
+	Results         [][]interface{} `json:"results"`
+	ResultPaths     []string        `json:"result_paths"`
+	ResultsPaths    [][]string      `json:"results_paths"`
+	InvalidSelector bool            `json:"invalid_selector"`
+}
+
+func TestPathRFC9535Compliance(t *testing.T) {
+	t.Run("subset", func(t *testing.T) {
+		runComplianceTests(t, "testdata/jsonpath-compliance/subset.json")
+	})
+	t.Run("cts", func(t *testing.T) {
+		// JSONPATH_CTS is the path to cts.json of the upstream suite to run it instead of the vendored one.
+		file := os.Getenv("JSONPATH_CTS")
+		if file == "" {
+			file = "testdata/jsonpath-compliance/cts.json"
+			if _, err := os.Stat(file); os.IsNotExist(err) {
+				t.Skip("the upstream suite is not vendored. run `make jsonpath-cts` to vendor it")
+			}
+		}
+		runComplianceTests(t, file)
+	})
+}
+
+func runComplianceTests(t *testing.T, file string) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suite struct {
+		Tests []complianceTest `json:"tests"`
+	}
+	if err := json.Unmarshal(content, &suite); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range suite.Tests {
+		test := test
+		t.Run(test.Name, func(t *testing.T) {
+			path, err := json.CreatePath(test.Selector, json.PathRFC9535())
+			if test.InvalidSelector {
+				if err == nil {
+					t.Fatalf("expected error for %q", test.Selector)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			matches, err := path.ExtractWithPaths(test.Document)
+			if err != nil {
+				t.Fatal(err)
+			}
+			values := []interface{}{}
+			paths := []string{}
+			for _, match := range matches {
+				var v interface{}
+				if err := json.Unmarshal(match.Value, &v); err != nil {
+					t.Fatal(err)
+				}
+				values = append(values, v)
+				paths = append(paths, match.Path)
+			}
+			expectedValues := test.Results
+			expectedPaths := test.ResultsPaths
+			if expectedValues == nil {
+				expectedValues = [][]interface{}{test.Result}
+				expectedPaths = [][]string{test.ResultPaths}
+			}
+			for i, expected := range expectedValues {
+				if !reflect.DeepEqual(values, expected) {
+					continue
+				}
+				if expectedPaths != nil && expectedPaths[i] != nil && !reflect.DeepEqual(paths, expectedPaths[i]) {
+					t.Fatalf("expected paths %q but got %q", expectedPaths[i], paths)
+				}
+				return
+			}
+			t.Fatalf("unexpected result %v for %q", values, test.Selector)
+		})
+	}
+}
+
+func TestPathExtractWithPaths(t *testing.T) {
+	src := []byte(`{"store":{"book":[{"title":"a","price":8},{"title":"b","price":12}]},"it's":1}`)
+	tests := []struct {
+		path     string
+		opts     []json.PathOptionFunc
+		expected []json.PathMatch
+	}{
+		{
+			path: "$.store.book[*].title",
+			expected: []json.PathMatch{
+				{Path: "$['store']['book'][0]['title']", Value: []byte(`"a"`)},
+				{Path: "$['store']['book'][1]['title']", Value: []byte(`"b"`)},
+			},
+		},
+		{
+			path: "$..price",
+			expected: []json.PathMatch{
+				{Path: "$['store']['book'][0]['price']", Value: []byte(`8`)},
+				{Path: "$['store']['book'][1]['price']", Value: []byte(`12`)},
+			},
+		},
+		{
+			path: "$.store.book[?(@.price > 10)]",
+			expected: []json.PathMatch{
+				{Path: "$['store']['book'][1]", Value: []byte(`{"title":"b","price":12}`)},
+			},
+		},
+		{
+			path: `$["it's"]`,
+			opts: []json.PathOptionFunc{json.PathRFC9535()},
+			expected: []json.PathMatch{
+				{Path: `$['it\'s']`, Value: []byte(`1`)},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			path, err := json.CreatePath(test.path, test.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			matches, err := path.ExtractWithPaths(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(matches, test.expected) {
+				t.Fatalf("expected %q but got %q", test.expected, matches)
+			}
+		})
+	}
+}
+
+func TestPathRFC9535(t *testing.T) {
+	type Book struct {
+		Title  string   `json:"title"`
+		Price  float64  `json:"price"`
+		Tags   []string `json:"tags"`
+		Hidden string   `json:"-"`
+	}
+	books := []Book{
+		{Title: "a", Price: 8, Tags: []string{"x"}},
+		{Title: "b", Price: 12, Tags: []string{"x", "y"}},
+		{Title: "c", Price: 9},
+	}
+	t.Run("get", func(t *testing.T) {
+		path, err := json.CreatePath("$[?count(@.tags[*]) > 0 && @.price < 10].title", json.PathRFC9535())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var titles []string
+		if err := path.Get(books, &titles); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(titles, []string{"a"}) {
+			t.Fatalf("unexpected titles: %q", titles)
+		}
+	})
+	t.Run("unmarshal", func(t *testing.T) {
+		path, err := json.CreatePath("$[?length(@.tags) == 2].title", json.PathRFC9535())
+		if err != nil {
+			t.Fatal(err)
+		}
+		src, err := json.Marshal(books)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var titles []string
+		if err := path.Unmarshal(src, &titles); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(titles, []string{"b"}) {
+			t.Fatalf("unexpected titles: %q", titles)
+		}
+	})
+	t.Run("escaped string", func(t *testing.T) {
+		path, err := json.CreatePath(`$[?@["a\"b"] == "x\ny"]`, json.PathRFC9535())
+		if err != nil {
+			t.Fatal(err)
+		}
+		src := []byte(`[{"a\"b": "x\ny"}, {"a\"b": "y"}]`)
+		contents, err := path.Extract(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(contents) != 1 {
+			t.Fatalf("failed to extract: %q", contents)
+		}
+		assertEq(t, "extracted", `{"a\"b": "x\ny"}`, string(contents[0]))
+		assertEq(t, "source", `[{"a\"b": "x\ny"}, {"a\"b": "y"}]`, string(src))
+	})
+	t.Run("decode options", func(t *testing.T) {
+		for _, p := range []string{"$", "$[0]"} {
+			path, err := json.CreatePath(p, json.PathRFC9535())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := path.Extract([]byte(`[1]`), json.DecodeUseNumber()); err == nil {
+				t.Fatalf("expected error for decode option with %q", p)
+			}
+		}
+	})
+	t.Run("strict", func(t *testing.T) {
+		// accepted by the default JSON Path builder, but not by RFC 9535.
+		for _, p := range []string{`$."a.b"`, "$.a[01]"} {
+			if _, err := json.CreatePath(p, json.PathRFC9535()); err == nil {
+				t.Fatalf("expected error for %q", p)
+			}
+		}
+	})
+}
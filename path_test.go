@@ -2,9 +2,15 @@ package json_test
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"reflect"
 	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/goccy/go-json"
 )
@@ -232,3 +238,539 @@ func TestGetPath(t *testing.T) {
 		}
 	})
 }
+
+func TestExtractPathSelectors(t *testing.T) {
+	src := []byte(`{
+  "store": {
+    "book": [
+      {"title": "a", "price": 8.95, "tag": "x"},
+      {"title": "b", "price": 12.99, "tag": "x"},
+      {"title": "c", "price": 8.99, "tag": "y", "isbn": "0-553"},
+      {"title": "d", "price": 22.99, "tag": "x", "isbn": "0-395"}
+    ],
+    "bicycle": {"color": "red", "price": 19.95}
+  },
+  "nums": [0, 1, 2, 3, 4, 5, 6, 7, 8, 9]
+}`)
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{path: "$.nums[-1]", expected: []string{"9"}},
+		{path: "$.nums[-11]", expected: []string{}},
+		{path: "$.nums[1:5:2]", expected: []string{"1", "3"}},
+		{path: "$.nums[:3]", expected: []string{"0", "1", "2"}},
+		{path: "$.nums[7:]", expected: []string{"7", "8", "9"}},
+		{path: "$.nums[-2:]", expected: []string{"8", "9"}},
+		{path: "$.nums[::-3]", expected: []string{"9", "6", "3", "0"}},
+		{path: "$.nums[5:1:-2]", expected: []string{"5", "3"}},
+		{path: "$.nums[::0]", expected: []string{}},
+		{path: "$.nums[0,2,-1]", expected: []string{"0", "2", "9"}},
+		{path: "$.nums[2,0]", expected: []string{"2", "0"}},
+		{path: "$.store.book[0,-1]['title','tag']", expected: []string{`"a"`, `"x"`, `"d"`, `"x"`}},
+		{path: `$.store.bicycle["price","color"]`, expected: []string{"19.95", `"red"`}},
+		{path: "$.store.book[?(@.price < 10)].title", expected: []string{`"a"`, `"c"`}},
+		{path: "$.store.book[?(@.price < 10 && @.tag == 'x')].title", expected: []string{`"a"`}},
+		{path: "$.store.book[?(@.price > 20 || @.tag == 'y')].title", expected: []string{`"c"`, `"d"`}},
+		{path: "$.store.book[?(!(@.tag == 'x'))].title", expected: []string{`"c"`}},
+		{path: "$.store.book[?(@.isbn)].title", expected: []string{`"c"`, `"d"`}},
+		{path: "$.store.book[?@.title != 'a'][-1].title", expected: []string{}},
+		{path: "$.store[?(@.color == 'red')].price", expected: []string{"19.95"}},
+		{path: "$.store.book[?(@.price >= 12.99)]['title']", expected: []string{`"b"`, `"d"`}},
+		{path: "$.nums[?(@ >= 8)]", expected: []string{"8", "9"}},
+		{path: "$.store['bicycle','book'].price", expected: []string{"19.95"}},
+		{path: "$.store['bicycle','book'][0].title", expected: []string{`"a"`}},
+		{path: "$.nums[0,1].price", expected: []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			path, err := json.CreatePath(test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			contents, err := path.Extract(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := []string{}
+			for _, content := range contents {
+				actual = append(actual, string(bytes.TrimSpace(content)))
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Fatalf("expected %q but got %q", test.expected, actual)
+			}
+		})
+	}
+	t.Run("selector for the other kind", func(t *testing.T) {
+		for _, p := range []string{"$.store.book.price", "$.store[0]"} {
+			path, err := json.CreatePath(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = path.Extract(src)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if strings.HasSuffix(err.Error(), ": ") {
+				t.Fatalf("expected the reason of the error: %q", err.Error())
+			}
+		}
+	})
+	for _, p := range []string{"$.nums[1:2:3:4]", "$.nums[a:b]", "$.nums[0,]", "$.nums[?(@.a ==)]", "$.nums[?(@.a < 1]", "$.nums['a]"} {
+		t.Run("invalid "+p, func(t *testing.T) {
+			if _, err := json.CreatePath(p); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestExtractPathEscapedString(t *testing.T) {
+	src := []byte(`[{"a\"b": "x\ny", "c": 1}]`)
+	for _, p := range []string{"$[?(@.c)]", `$[?(@.c == 1 && @["a\"b"] == "x\ny")]`} {
+		t.Run(p, func(t *testing.T) {
+			path, err := json.CreatePath(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			contents, err := path.Extract(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(contents) != 1 {
+				t.Fatalf("failed to extract: %q", contents)
+			}
+			assertEq(t, "extracted", `{"a\"b": "x\ny", "c": 1}`, string(contents[0]))
+			assertEq(t, "source", `[{"a\"b": "x\ny", "c": 1}]`, string(src))
+		})
+	}
+}
+
+func TestGetPathSelectors(t *testing.T) {
+	type Book struct {
+		Title string  `json:"title"`
+		Price float64 `json:"price"`
+		Tag   string  `json:"tag"`
+	}
+	type Store struct {
+		Books []Book         `json:"book"`
+		Stock map[string]int `json:"stock"`
+	}
+	src := &Store{
+		Books: []Book{
+			{Title: "a", Price: 8.95, Tag: "x"},
+			{Title: "b", Price: 12.99, Tag: "x"},
+			{Title: "c", Price: 8.99, Tag: "y"},
+		},
+		Stock: map[string]int{"a": 1, "b": 0, "c": 5},
+	}
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{path: "$.book[-1].title", expected: []string{"c"}},
+		{path: "$.book[0:2].title", expected: []string{"a", "b"}},
+		{path: "$.book[::-1].title", expected: []string{"c", "b", "a"}},
+		{path: "$.book[2,0].title", expected: []string{"c", "a"}},
+		{path: "$.book[?(@.price < 10 && @.tag == 'x')].title", expected: []string{"a"}},
+		{path: "$.book[0]['title','tag']", expected: []string{"a", "x"}},
+		{path: "$['book','stock'][1].title", expected: []string{"b"}},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			path, err := json.CreatePath(test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var v []string
+			if err := path.Get(src, &v); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(v, test.expected) {
+				t.Fatalf("expected %q but got %q", test.expected, v)
+			}
+		})
+	}
+	t.Run("map", func(t *testing.T) {
+		path, err := json.CreatePath("$.stock[?(@ > 0)]")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v []int
+		if err := path.Get(src, &v); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(v, []int{1, 5}) {
+			t.Fatalf("unexpected value: %v", v)
+		}
+	})
+	t.Run("union of the different kinds", func(t *testing.T) {
+		path, err := json.CreatePath("$['book','stock'].c")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v []int
+		if err := path.Get(src, &v); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(v, []int{5}) {
+			t.Fatalf("unexpected value: %v", v)
+		}
+	})
+	t.Run("interface", func(t *testing.T) {
+		var iface interface{}
+		if err := json.Unmarshal([]byte(`{"a":[{"b":1},{"b":2},{"b":3}]}`), &iface); err != nil {
+			t.Fatal(err)
+		}
+		path, err := json.CreatePath("$.a[?(@.b != 2)].b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v []int
+		if err := path.Get(iface, &v); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(v, []int{1, 3}) {
+			t.Fatalf("unexpected value: %v", v)
+		}
+	})
+}
+
+func TestSetPath(t *testing.T) {
+	src := []byte(`{
+  "user": {"name": "a", "ssn": "123-45-6789"},
+  "items": [ {"id": 1, "ssn": "x"}, {"id": 2} ],
+  "empty": {}
+}`)
+	tests := []struct {
+		path     string
+		value    interface{}
+		expected string
+	}{
+		{
+			path:  "$.user.ssn",
+			value: "***",
+			expected: `{
+  "user": {"name": "a", "ssn": "***"},
+  "items": [ {"id": 1, "ssn": "x"}, {"id": 2} ],
+  "empty": {}
+}`,
+		},
+		{
+			path:  "$..ssn",
+			value: nil,
+			expected: `{
+  "user": {"name": "a", "ssn": null},
+  "items": [ {"id": 1, "ssn": null}, {"id": 2} ],
+  "empty": {}
+}`,
+		},
+		{
+			path:  "$.items[-1]",
+			value: map[string]int{"id": 3},
+			expected: `{
+  "user": {"name": "a", "ssn": "123-45-6789"},
+  "items": [ {"id": 1, "ssn": "x"}, {"id":3} ],
+  "empty": {}
+}`,
+		},
+		{
+			path:  "$.user.age",
+			value: 20,
+			expected: `{
+  "user": {"name": "a", "ssn": "123-45-6789","age":20},
+  "items": [ {"id": 1, "ssn": "x"}, {"id": 2} ],
+  "empty": {}
+}`,
+		},
+		{
+			path:  `$.empty["a\"b"]`,
+			value: true,
+			expected: `{
+  "user": {"name": "a", "ssn": "123-45-6789"},
+  "items": [ {"id": 1, "ssn": "x"}, {"id": 2} ],
+  "empty": {"a\"b":true}
+}`,
+		},
+		{
+			path:  "$.items",
+			value: []int{},
+			expected: `{
+  "user": {"name": "a", "ssn": "123-45-6789"},
+  "items": [],
+  "empty": {}
+}`,
+		},
+		{
+			path:     "$",
+			value:    1,
+			expected: `1`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			path, err := json.CreatePath(test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			edited, err := path.Set(src, test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEq(t, "set", test.expected, string(edited))
+		})
+	}
+	for _, p := range []string{"$.missing.ssn", "$.items[5]", "$.user.name.first"} {
+		t.Run("error "+p, func(t *testing.T) {
+			path, err := json.CreatePath(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := path.Set(src, 1); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+	t.Run("rfc9535", func(t *testing.T) {
+		path, err := json.CreatePath("$..*", json.PathRFC9535())
+		if err != nil {
+			t.Fatal(err)
+		}
+		edited, err := path.Set(src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "set", `{
+  "user": 0,
+  "items": 0,
+  "empty": 0
+}`, string(edited))
+	})
+	t.Run("invalid json", func(t *testing.T) {
+		path, err := json.CreatePath("$.a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := path.Set([]byte(`{"a":1} x`), 2); err == nil {
+			t.Fatal("expected error")
+		}
+		root, err := json.CreatePath("$")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := root.Set([]byte(`{"a":1`), 2); err == nil {
+			t.Fatal("expected error for root")
+		}
+	})
+	t.Run("escaped strings", func(t *testing.T) {
+		path, err := json.CreatePath("$.c")
+		if err != nil {
+			t.Fatal(err)
+		}
+		edited, err := path.Set([]byte(`{"a\"b":"x\ny","c":1}`), 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "set", `{"a\"b":"x\ny","c":2}`, string(edited))
+	})
+}
+
+func TestDeletePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		src      string
+		expected string
+	}{
+		{path: "$.user.ssn", src: `{"user": {"name": "a", "ssn": "1"}}`, expected: `{"user": {"name": "a"}}`},
+		{path: "$.user.name", src: `{"user": {"name": "a", "ssn": "1"}}`, expected: `{"user": {"ssn": "1"}}`},
+		{path: "$.user.name", src: `{"user": { "name": "a" }}`, expected: `{"user": {  }}`},
+		{path: "$.user.missing", src: `{"user": {"name": "a"}}`, expected: `{"user": {"name": "a"}}`},
+		{path: "$..ssn", src: `[{"ssn": 1, "id": 1}, {"id": 2, "ssn": {"ssn": 3}}]`, expected: `[{"id": 1}, {"id": 2}]`},
+		{path: "$[1,2]", src: `[0, 1, 2]`, expected: `[0]`},
+		{path: "$[0,2]", src: `[0, 1, 2]`, expected: `[1]`},
+		{path: "$[0,1]", src: `[0, 1, 2]`, expected: `[2]`},
+		{path: "$[*]", src: `[0, 1, 2]`, expected: `[]`},
+		{path: "$[?(@ > 0)]", src: "[0,\n 1,\n 2,\n 0]", expected: "[0,\n 0]"},
+	}
+	for _, test := range tests {
+		t.Run(test.path+" "+test.src, func(t *testing.T) {
+			path, err := json.CreatePath(test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			edited, err := path.Delete([]byte(test.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEq(t, "delete", test.expected, string(edited))
+		})
+	}
+	t.Run("root", func(t *testing.T) {
+		path, err := json.CreatePath("$")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := path.Delete([]byte(`{}`)); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestExtractStreamPath(t *testing.T) {
+	src := `{
+  "store": {
+    "book": [
+      {"title": "a", "price": 8.95, "tag": "x"},
+      {"title": "b", "price": 12.99, "tag": "x"},
+      {"title": "c", "price": 8.99, "tag": "y", "isbn": "0-553"},
+      {"title": "d\"", "price": 22.99, "tag": "x", "isbn": "0-395"}
+    ],
+    "bicycle": {"color": "red", "price": 19.95}
+  },
+  "nums": [0, 1, 2, 3, 4, 5, 6, 7, 8, 9]
+}`
+	tests := []struct {
+		path     string
+		rfc9535  bool
+		expected []string
+	}{
+		{path: "$", expected: []string{src}},
+		{path: "$.nums", expected: []string{"[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]"}},
+		{path: "$.nums[*]", expected: []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}},
+		{path: "$.nums[3]", expected: []string{"3"}},
+		{path: "$.nums[1:5:2]", expected: []string{"1", "3"}},
+		{path: "$.nums[7:]", expected: []string{"7", "8", "9"}},
+		{path: "$.nums[2,0]", expected: []string{"0", "2"}},
+		{path: "$.nums[-1]", expected: []string{"9"}},
+		{path: "$.nums[::-3]", expected: []string{"0", "3", "6", "9"}},
+		{path: "$.store.book[*].title", expected: []string{`"a"`, `"b"`, `"c"`, `"d\""`}},
+		{path: "$.store.book[?(@.price < 10)].title", expected: []string{`"a"`, `"c"`}},
+		{path: "$..price", expected: []string{"8.95", "12.99", "8.99", "22.99", "19.95"}},
+		{path: "$.missing", expected: nil},
+		{path: "$..*[?@.isbn]", rfc9535: true, expected: []string{
+			`{"title": "c", "price": 8.99, "tag": "y", "isbn": "0-553"}`,
+			`{"title": "d\"", "price": 22.99, "tag": "x", "isbn": "0-395"}`,
+		}},
+		{path: "$..book[?@.price > $.store.bicycle.price].title", rfc9535: true, expected: []string{`"d\""`}},
+		{path: "$.store..[?@ == 'x']", rfc9535: true, expected: []string{`"x"`, `"x"`, `"x"`}},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			var opts []json.PathOptionFunc
+			if test.rfc9535 {
+				opts = append(opts, json.PathRFC9535())
+			}
+			path, err := json.CreatePath(test.path, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			if err := path.ExtractStream(iotest.OneByteReader(strings.NewReader(src)), func(v []byte) error {
+				got = append(got, string(v))
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(test.expected, got) {
+				t.Fatalf("failed to extract values. expected %q but got %q", test.expected, got)
+			}
+		})
+	}
+	t.Run("stop by callback error", func(t *testing.T) {
+		path, err := json.CreatePath("$.nums[*]")
+		if err != nil {
+			t.Fatal(err)
+		}
+		stop := errors.New("stop")
+		var count int
+		err = path.ExtractStream(strings.NewReader(src), func([]byte) error {
+			count++
+			if count == 2 {
+				return stop
+			}
+			return nil
+		})
+		if err != stop {
+			t.Fatalf("expected stop error but got %v", err)
+		}
+		assertEq(t, "count", 2, count)
+	})
+	t.Run("invalid json", func(t *testing.T) {
+		path, err := json.CreatePath("$.a[*]")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, src := range []string{`{"a": [1, 2}`, `{"b": {"c" 1}}`, `{"a": [1]} x`, `{"a": [1`} {
+			if err := path.ExtractStream(strings.NewReader(src), func([]byte) error { return nil }); err == nil {
+				t.Fatalf("expected error for %s", src)
+			}
+		}
+	})
+	t.Run("large input", func(t *testing.T) {
+		const n = 100000
+		r, w := io.Pipe()
+		go func() {
+			fmt.Fprint(w, `{"skipped": [`)
+			for i := 0; i < n; i++ {
+				if i > 0 {
+					fmt.Fprint(w, ",")
+				}
+				fmt.Fprintf(w, `{"id": %d, "tags": ["a", "b"]}`, i)
+			}
+			fmt.Fprint(w, `], "records": [`)
+			for i := 0; i < n; i++ {
+				if i > 0 {
+					fmt.Fprint(w, ",")
+				}
+				fmt.Fprintf(w, `{"id": %d}`, i)
+			}
+			fmt.Fprint(w, `]}`)
+			w.Close()
+		}()
+		path, err := json.CreatePath("$.records[*].id")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sum int
+		if err := path.ExtractStream(r, func(v []byte) error {
+			id, err := strconv.Atoi(string(v))
+			if err != nil {
+				return err
+			}
+			sum += id
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "sum", n*(n-1)/2, sum)
+	})
+	t.Run("skip without allocation", func(t *testing.T) {
+		var b strings.Builder
+		b.WriteString(`{"skipped": [`)
+		for i := 0; i < 10000; i++ {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(&b, `{"id": %d, "name": "item\"%d"}`, i, i)
+		}
+		b.WriteString(`], "value": 1}`)
+		large := b.String()
+		path, err := json.CreatePath("$.missing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		allocs := func(src string) float64 {
+			r := strings.NewReader(src)
+			return testing.AllocsPerRun(10, func() {
+				r.Reset(src)
+				if err := path.ExtractStream(r, func([]byte) error { return nil }); err != nil {
+					t.Fatal(err)
+				}
+			})
+		}
+		small := allocs(`{"skipped": [], "value": 1}`)
+		if got := allocs(large); got > small {
+			t.Fatalf("skipping large input allocates %v times, but small input allocates %v times", got, small)
+		}
+	})
+}
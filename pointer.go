@@ -0,0 +1,77 @@
+package json
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/goccy/go-json/internal/decoder"
+	"github.com/goccy/go-json/internal/errors"
+)
+
+// CreatePointer creates JSON Pointer ( RFC 6901 ).
+//
+// JSON Pointer rule
+// ""  : the whole document.
+// /   : prefix of each reference token. e.g.) `/items/3/price`
+// ~0  : escaped ~ character in reference token.
+// ~1  : escaped / character in reference token.
+//
+// A reference token selects the member of object by name, or the element of array by index.
+func CreatePointer(p string) (*Pointer, error) {
+	tokens, err := decoder.ParsePointer(p)
+	if err != nil {
+		return nil, err
+	}
+	return &Pointer{
+		raw:    p,
+		tokens: tokens,
+		path:   decoder.NewPointerPath(tokens),
+	}, nil
+}
+
+// Pointer represents JSON Pointer.
+type Pointer struct {
+	raw    string
+	tokens []string
+	path   *decoder.Path
+}
+
+// String returns original JSON Pointer string.
+func (p *Pointer) String() string {
+	return p.raw
+}
+
+// Tokens returns the unescaped reference tokens.
+func (p *Pointer) Tokens() []string {
+	return append([]string(nil), p.tokens...)
+}
+
+// Extract extracts the JSON string referenced by JSON Pointer.
+// If the object has duplicate names, the first one is used as same as Value.Get.
+func (p *Pointer) Extract(data []byte, optFuncs ...DecodeOptionFunc) ([]byte, error) {
+	contents, err := extractFromPath(p.path, data, optFuncs...)
+	if err != nil {
+		return nil, err
+	}
+	if len(contents) == 0 {
+		return nil, errors.ErrPointerNotFound(p.raw)
+	}
+	return bytes.TrimLeft(contents[0], " \t\r\n"), nil
+}
+
+// Unmarshal extract and decode the value referenced by JSON Pointer from the input data.
+func (p *Pointer) Unmarshal(data []byte, v interface{}, optFuncs ...DecodeOptionFunc) error {
+	content, err := p.Extract(data, optFuncs...)
+	if err != nil {
+		return err
+	}
+	return UnmarshalWithOption(content, v, optFuncs...)
+}
+
+// Get extract and substitute the value referenced by JSON Pointer from the input value.
+func (p *Pointer) Get(src, dst interface{}) error {
+	if p.path.RootSelectorOnly {
+		return decoder.AssignValue(reflect.ValueOf(src), reflect.ValueOf(dst))
+	}
+	return p.path.Get(reflect.ValueOf(src), reflect.ValueOf(dst))
+}
@@ -0,0 +1,263 @@
+package json_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestCreatePointer(t *testing.T) {
+	tests := []struct {
+		ptr    string
+		tokens []string
+	}{
+		{ptr: "", tokens: nil},
+		{ptr: "/", tokens: []string{""}},
+		{ptr: "/a/0/b", tokens: []string{"a", "0", "b"}},
+		{ptr: "/a~1b/m~0n", tokens: []string{"a/b", "m~n"}},
+		{ptr: "/~01", tokens: []string{"~1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.ptr, func(t *testing.T) {
+			p, err := json.CreatePointer(test.ptr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(p.Tokens(), test.tokens) {
+				t.Fatalf("unexpected tokens: %q", p.Tokens())
+			}
+			assertEq(t, "string", test.ptr, p.String())
+		})
+	}
+	for _, ptr := range []string{"a", "/a~", "/a~2"} {
+		t.Run("invalid "+ptr, func(t *testing.T) {
+			_, err := json.CreatePointer(ptr)
+			var ptrErr *json.PointerError
+			if !errors.As(err, &ptrErr) {
+				t.Fatalf("expected PointerError but got %v", err)
+			}
+		})
+	}
+}
+
+func TestPointerExtract(t *testing.T) {
+	// examples from RFC 6901 section 5.
+	src := []byte(`{
+  "foo": ["bar", "baz"],
+  "": 0,
+  "a/b": 1,
+  "c%d": 2,
+  "e^f": 3,
+  "g|h": 4,
+  "i\\j": 5,
+  "k\"l": 6,
+  " ": 7,
+  "m~n": 8
+}`)
+	tests := []struct {
+		ptr      string
+		expected string
+	}{
+		{ptr: "", expected: string(src)},
+		{ptr: "/foo", expected: `["bar", "baz"]`},
+		{ptr: "/foo/0", expected: `"bar"`},
+		{ptr: "/", expected: `0`},
+		{ptr: "/a~1b", expected: `1`},
+		{ptr: "/c%d", expected: `2`},
+		{ptr: "/e^f", expected: `3`},
+		{ptr: "/g|h", expected: `4`},
+		{ptr: "/i\\j", expected: `5`},
+		{ptr: "/k\"l", expected: `6`},
+		{ptr: "/ ", expected: `7`},
+		{ptr: "/m~0n", expected: `8`},
+	}
+	for _, test := range tests {
+		t.Run(test.ptr, func(t *testing.T) {
+			p, err := json.CreatePointer(test.ptr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err := p.Extract(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEq(t, "content", test.expected, string(content))
+		})
+	}
+	for _, ptr := range []string{"/bar", "/foo/2", "/foo/01", "/foo/-"} {
+		t.Run("not found "+ptr, func(t *testing.T) {
+			p, err := json.CreatePointer(ptr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = p.Extract(src)
+			var ptrErr *json.PointerError
+			if !errors.As(err, &ptrErr) {
+				t.Fatalf("expected PointerError but got %v", err)
+			}
+		})
+	}
+	t.Run("whole document", func(t *testing.T) {
+		p, err := json.CreatePointer("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := p.Extract([]byte(" \n{\"a\": 1}\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "content", `{"a": 1}`, string(content))
+		if _, err := p.Extract([]byte(`{"a":1`)); err == nil {
+			t.Fatal("expected error for invalid document")
+		}
+	})
+	t.Run("duplicate names", func(t *testing.T) {
+		p, err := json.CreatePointer("/a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := p.Extract([]byte(`{"a": 1, "a": 2}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "content", "1", string(content))
+	})
+	t.Run("unmarshal", func(t *testing.T) {
+		p, err := json.CreatePointer("/foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v []string
+		if err := p.Unmarshal(src, &v); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(v, []string{"bar", "baz"}) {
+			t.Fatalf("unexpected value: %q", v)
+		}
+	})
+}
+
+func TestPointerGet(t *testing.T) {
+	type Item struct {
+		Name  string `json:"name"`
+		Price int    `json:"price"`
+	}
+	type Order struct {
+		Items []Item          `json:"items"`
+		Meta  map[string]bool `json:"meta"`
+	}
+	src := &Order{
+		Items: []Item{{Name: "a", Price: 10}, {Name: "b", Price: 20}},
+		Meta:  map[string]bool{"x/y": true},
+	}
+	t.Run("struct", func(t *testing.T) {
+		p, err := json.CreatePointer("/items/1/price")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v int
+		if err := p.Get(src, &v); err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "price", 20, v)
+	})
+	t.Run("map", func(t *testing.T) {
+		p, err := json.CreatePointer("/meta/x~1y")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v bool
+		if err := p.Get(src, &v); err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "meta", true, v)
+	})
+	t.Run("interface", func(t *testing.T) {
+		var iface interface{}
+		if err := json.Unmarshal([]byte(`{"items":[{"name":"a"}]}`), &iface); err != nil {
+			t.Fatal(err)
+		}
+		p, err := json.CreatePointer("/items/0/name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v string
+		if err := p.Get(iface, &v); err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "name", "a", v)
+	})
+	t.Run("not found", func(t *testing.T) {
+		p, err := json.CreatePointer("/items/2/price")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v int
+		if err := p.Get(src, &v); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestUnmarshalTypeErrorPointer(t *testing.T) {
+	type Item struct {
+		Price int `json:"price"`
+	}
+	type T struct {
+		Items  []Item            `json:"items"`
+		Array  [2]Item           `json:"array"`
+		Map    map[string][]Item `json:"map"`
+		IntMap map[int]Item      `json:"intMap"`
+		Nested map[string]*[]int `json:"nested"`
+	}
+	tests := []struct {
+		src     string
+		pointer string
+	}{
+		{src: `{"items":[{"price":1},{"price":2},{"price":3},{"price":"x"}]}`, pointer: "/items/3/price"},
+		{src: `{"array":[{},{"price":true}]}`, pointer: "/array/1/price"},
+		{src: `{"map":{"a/b":[{"price":{}}]}}`, pointer: "/map/a~1b/0/price"},
+		{src: `{"intMap":{"5":{"price":[]}}}`, pointer: "/intMap/5/price"},
+		{src: `{"nested":{"x":[1,"a"]}}`, pointer: "/nested/x/1"},
+		// the reference tokens are the keys in the document.
+		{src: `{"ITEMS":[{"Price":"x"}]}`, pointer: "/ITEMS/0/Price"},
+		{src: `{"items":[{"pr\u0069ce":"x"}]}`, pointer: "/items/0/price"},
+		{src: `{"items":[{"PR\u0049CE":"x"}]}`, pointer: "/items/0/PRICE"},
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			check := func(t *testing.T, err error) {
+				t.Helper()
+				var typeErr *json.UnmarshalTypeError
+				if !errors.As(err, &typeErr) {
+					t.Fatalf("expected UnmarshalTypeError but got %v", err)
+				}
+				assertEq(t, "pointer", test.pointer, typeErr.Pointer)
+			}
+			var v T
+			check(t, json.Unmarshal([]byte(test.src), &v))
+			var sv T
+			check(t, json.NewDecoder(strings.NewReader(test.src)).Decode(&sv))
+		})
+	}
+	t.Run("keys conflicting in case", func(t *testing.T) {
+		// the keys are decoded without the bitmap of the keys.
+		type T struct {
+			Items []Item `json:"items"`
+			ITEMS int    `json:"ITEMS"`
+		}
+		for _, src := range []string{`{"items":[{"PRICE":"x"}]}`, `{"it\u0065ms":[{"PR\u0049CE":"x"}]}`} {
+			var v T
+			err := json.Unmarshal([]byte(src), &v)
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				t.Fatalf("expected UnmarshalTypeError but got %v", err)
+			}
+			assertEq(t, "pointer", "/items/0/PRICE", typeErr.Pointer)
+			assertEq(t, "error", `json: cannot unmarshal number " into Go struct field Item.Price of type int`, err.Error())
+		}
+	})
+}
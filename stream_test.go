@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"runtime"
 	"strconv"
 	"strings"
 	"testing"
@@ -174,6 +175,139 @@ func TestEncoderSetEscapeHTML(t *testing.T) {
 	}
 }
 
+type recordWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *recordWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+type errWriter struct{}
+
+func (errWriter) Write(p []byte) (int, error) {
+	return 0, fmt.Errorf("write error")
+}
+
+func TestEncoderSetFlushThreshold(t *testing.T) {
+	type elem struct {
+		A     int               `json:"a"`
+		B     string            `json:"b,omitempty"`
+		C     []int             `json:"c"`
+		Extra map[string]string `json:",inline"`
+	}
+	elems := make([]elem, 300)
+	for i := range elems {
+		elems[i] = elem{A: i, C: []int{i, i + 1}}
+		if i%3 == 0 {
+			elems[i].B = "b"
+		}
+		if i%5 == 0 {
+			elems[i].Extra = map[string]string{"x": "y", "z": "w"}
+		}
+	}
+	m := map[string][]int{}
+	for i := 0; i < 100; i++ {
+		m[strconv.Itoa(i)] = []int{i, i, i}
+	}
+	var om json.OrderedMap[string, []elem]
+	om.Set("x", elems[:100])
+	om.Set("y", elems[100:])
+	values := []struct {
+		name string
+		v    interface{}
+	}{
+		{"slice", elems},
+		{"array", [3][]elem{elems[:100], elems[100:200], elems[200:]}},
+		{"map", m},
+		{"ordered map", &om},
+		{"interface", []interface{}{elems, m, nil}},
+	}
+	options := []struct {
+		name      string
+		indent    bool
+		unordered bool
+		optFunc   []json.EncodeOptionFunc
+	}{
+		{name: "compact"},
+		{name: "indent", indent: true},
+		{name: "unordered", unordered: true, optFunc: []json.EncodeOptionFunc{json.UnorderedMap()}},
+		{name: "unordered indent", indent: true, unordered: true, optFunc: []json.EncodeOptionFunc{json.UnorderedMap()}},
+		{name: "colorize", optFunc: []json.EncodeOptionFunc{json.Colorize(json.DefaultColorScheme)}},
+	}
+	for _, opt := range options {
+		for _, tc := range values {
+			t.Run(opt.name+"/"+tc.name, func(t *testing.T) {
+				var expected bytes.Buffer
+				enc := json.NewEncoder(&expected)
+				if opt.indent {
+					enc.SetIndent("", "  ")
+				}
+				if err := enc.EncodeWithOption(tc.v, opt.optFunc...); err != nil {
+					t.Fatal(err)
+				}
+
+				var got recordWriter
+				enc = json.NewEncoder(&got)
+				if opt.indent {
+					enc.SetIndent("", "  ")
+				}
+				enc.SetFlushThreshold(256)
+				if err := enc.EncodeWithOption(tc.v, opt.optFunc...); err != nil {
+					t.Fatal(err)
+				}
+				if opt.unordered {
+					// the order of the map keys is different for each encoding.
+					var exp, act interface{}
+					assertErr(t, json.Unmarshal(expected.Bytes(), &exp))
+					assertErr(t, json.Unmarshal(got.Bytes(), &act))
+					if !reflect.DeepEqual(exp, act) {
+						t.Fatalf("failed to encode: expected %s but got %s", expected.String(), got.String())
+					}
+				} else {
+					assertEq(t, "encoded", expected.String(), got.String())
+				}
+				// the sorted map is written after all of its entries are encoded.
+				if tc.name != "map" || opt.unordered {
+					if got.writes < 2 {
+						t.Fatalf("expected to be flushed, but written %d times", got.writes)
+					}
+				}
+			})
+		}
+	}
+	t.Run("small value", func(t *testing.T) {
+		var got recordWriter
+		enc := json.NewEncoder(&got)
+		enc.SetFlushThreshold(256)
+		if err := enc.Encode([]int{1, 2, 3}); err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "encoded", "[1,2,3]\n", got.String())
+		assertEq(t, "writes", 1, got.writes)
+	})
+	t.Run("write error", func(t *testing.T) {
+		enc := json.NewEncoder(errWriter{})
+		enc.SetFlushThreshold(256)
+		if err := enc.Encode(elems); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+	t.Run("marshal after flushing", func(t *testing.T) {
+		var got recordWriter
+		enc := json.NewEncoder(&got)
+		enc.SetFlushThreshold(1)
+		if err := enc.Encode(elems); err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(elems)
+		assertErr(t, err)
+		assertEq(t, "marshal", strings.TrimSpace(got.String()), string(b))
+	})
+}
+
 func nlines(s string, n int) string {
 	if n <= 0 {
 		return ""
@@ -538,3 +672,69 @@ func TestIssue278(t *testing.T) {
 		t.Errorf("string %q; want = %q", got, want)
 	}
 }
+
+// repeatReader reads the record n times by small chunks like a network connection.
+type repeatReader struct {
+	record []byte
+	n      int
+	off    int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	if len(p) > 4096 {
+		p = p[:4096]
+	}
+	read := 0
+	for read < len(p) && r.n > 0 {
+		c := copy(p[read:], r.record[r.off:])
+		read += c
+		r.off += c
+		if r.off == len(r.record) {
+			r.off = 0
+			r.n--
+		}
+	}
+	if read == 0 {
+		return 0, io.EOF
+	}
+	return read, nil
+}
+
+func TestDecodeStreamMemory(t *testing.T) {
+	type T struct {
+		A int
+		B string
+	}
+	const n = 200000
+	record := []byte(`{"A":1,"B":"` + strings.Repeat("x", 100) + `"}` + "\n")
+	dec := json.NewDecoder(&repeatReader{record: record, n: n})
+
+	// the first decoding initializes the cache of the decoders.
+	var first T
+	if err := dec.Decode(&first); err != nil {
+		t.Fatal(err)
+	}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for i := 1; i < n; i++ {
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+
+	// the input is about 24MB, and the decoder must not keep the bytes that are already decoded.
+	if after.HeapAlloc > before.HeapAlloc && after.HeapAlloc-before.HeapAlloc > 4<<20 {
+		t.Fatalf("decoder keeps %d bytes after decoding", after.HeapAlloc-before.HeapAlloc)
+	}
+
+	var v T
+	err := dec.Decode(&v)
+	if err != io.EOF {
+		t.Fatalf("expected io.EOF but got %v", err)
+	}
+	runtime.KeepAlive(dec)
+}
@@ -0,0 +1,217 @@
+package json
+
+import (
+	"io"
+	"reflect"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/decoder"
+	"github.com/goccy/go-json/internal/encoder"
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+func typeOf[T any]() *runtime.Type {
+	return runtime.Type2RType(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+func ptrTypeOf[T any]() *runtime.Type {
+	return runtime.Type2RType(reflect.TypeOf((*T)(nil)))
+}
+
+// UnmarshalAs parses the JSON-encoded data and returns the result as a value of type T.
+// Unlike Unmarshal, it doesn't need to validate the destination value at runtime
+// because the destination type is decided at compile time.
+func UnmarshalAs[T any](data []byte, optFuncs ...DecodeOptionFunc) (T, error) {
+	var v T
+	src := make([]byte, len(data)+1) // append nul byte to the end
+	copy(src, data)
+
+	ctx := decoder.TakeRuntimeContext()
+	ctx.Buf = src
+	ctx.Option.Flags = 0
+	for _, optFunc := range optFuncs {
+		optFunc(ctx.Option)
+	}
+	dec, err := decoder.CompileToGetDecoderWithOption(ptrTypeOf[T](), ctx.Option)
+	if err != nil {
+		decoder.ReleaseRuntimeContext(ctx)
+		return v, err
+	}
+	cursor, err := dec.Decode(ctx, 0, 0, unsafe.Pointer(&v))
+	if err == nil {
+		err = validateEndBuf(src, cursor)
+	}
+	err = takeErrors(ctx, src, err)
+	decoder.ReleaseRuntimeContext(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
+
+// MarshalTo appends the JSON encoding of v to dst and returns the extended buffer.
+// Unlike Marshal, the encoding result is directly written to dst without copying.
+func MarshalTo[T any](dst []byte, v T, optFuncs ...EncodeOptionFunc) ([]byte, error) {
+	ctx := encoder.TakeRuntimeContext()
+
+	ctx.Option.Flag = 0
+	ctx.Option.Flag |= (encoder.HTMLEscapeOption | encoder.NormalizeUTF8Option)
+	for _, optFunc := range optFuncs {
+		optFunc(ctx.Option)
+	}
+
+	buf, err := encodeTyped(ctx, dst, &v)
+	encoder.ReleaseRuntimeContext(ctx)
+	if err != nil {
+		return dst, err
+	}
+	return buf[:len(buf)-1], nil
+}
+
+// EncodeTyped writes the JSON encoding of v to the stream of e, followed by a newline character.
+// This is the generic version of Encoder.EncodeWithOption, because methods cannot have type parameters.
+func EncodeTyped[T any](e *Encoder, v T, optFuncs ...EncodeOptionFunc) error {
+	ctx := encoder.TakeRuntimeContext()
+	ctx.Option.Flag = 0
+	e.setupOption(ctx, optFuncs...)
+
+	var (
+		buf []byte
+		err error
+	)
+	if e.enabledIndent {
+		buf, err = encodeTypedIndent(ctx, ctx.Buf[:0], &v, e.prefix, e.indentStr)
+	} else {
+		buf, err = encodeTyped(ctx, ctx.Buf[:0], &v)
+	}
+	if err != nil {
+		encoder.ReleaseRuntimeContext(ctx)
+		return err
+	}
+	ctx.Buf = buf
+	err = e.write(buf)
+	encoder.ReleaseRuntimeContext(ctx)
+	return err
+}
+
+// typedCodeSet returns the opcode set and the pointer to pass to the VM for v.
+// If T is an interface type, the dynamic type is used. If the value is nil interface, returns nil opcode set.
+func typedCodeSet[T any](ctx *encoder.RuntimeContext, v *T) (*encoder.OpcodeSet, unsafe.Pointer, error) {
+	typ := typeOf[T]()
+	var p unsafe.Pointer
+	switch {
+	case typ.Kind() == reflect.Interface:
+		iface := interface{}(*v)
+		if iface == nil {
+			return nil, nil, nil
+		}
+		header := (*emptyInterface)(unsafe.Pointer(&iface))
+		typ = header.typ
+		p = header.ptr
+	case runtime.IfaceIndir(typ):
+		p = unsafe.Pointer(v)
+	default:
+		// pointer shaped type is stored directly.
+		p = *(*unsafe.Pointer)(unsafe.Pointer(v))
+	}
+	codeSet, err := encoder.CompileToGetCodeSet(ctx, uintptr(unsafe.Pointer(typ)))
+	if err != nil {
+		return nil, nil, err
+	}
+	return codeSet, p, nil
+}
+
+func encodeTyped[T any](ctx *encoder.RuntimeContext, b []byte, v *T) ([]byte, error) {
+	codeSet, p, err := typedCodeSet(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	if codeSet == nil {
+		b = encoder.AppendNull(ctx, b)
+		b = encoder.AppendComma(ctx, b)
+		return b, nil
+	}
+	ctx.Init(uintptr(p), codeSet.CodeLength)
+	ctx.KeepRefs = append(ctx.KeepRefs, p)
+	return encodeRunCode(ctx, b, codeSet)
+}
+
+func encodeTypedIndent[T any](ctx *encoder.RuntimeContext, b []byte, v *T, prefix, indent string) ([]byte, error) {
+	codeSet, p, err := typedCodeSet(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	if codeSet == nil {
+		b = encoder.AppendNull(ctx, b)
+		b = encoder.AppendCommaIndent(ctx, b)
+		return b, nil
+	}
+	ctx.Init(uintptr(p), codeSet.CodeLength)
+	ctx.KeepRefs = append(ctx.KeepRefs, p)
+	return encodeRunIndentCode(ctx, b, codeSet, prefix, indent)
+}
+
+// DecodeIter reads a stream of JSON values of type T from an input stream.
+//
+//	iter := json.NewDecodeIter[T](r)
+//	for iter.Next() {
+//		v := iter.Value()
+//		...
+//	}
+//	if err := iter.Err(); err != nil {
+//		...
+//	}
+type DecodeIter[T any] struct {
+	s     *decoder.Stream
+	dec   decoder.Decoder
+	value T
+	err   error
+}
+
+// NewDecodeIter returns a new iterator that reads values of type T from r.
+// The decoder for T is compiled only once when the iterator is created.
+func NewDecodeIter[T any](r io.Reader, optFuncs ...DecodeOptionFunc) *DecodeIter[T] {
+	s := decoder.NewStream(r)
+	for _, optFunc := range optFuncs {
+		optFunc(s.Option)
+	}
+	dec, err := decoder.CompileToGetDecoderWithOption(ptrTypeOf[T](), s.Option)
+	return &DecodeIter[T]{
+		s:   s,
+		dec: dec,
+		err: err,
+	}
+}
+
+// Next decodes the next value from the stream.
+// It returns false when the stream reaches EOF or an error occurs.
+func (it *DecodeIter[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if err := it.s.PrepareForDecode(); err != nil {
+		if err != io.EOF {
+			it.err = err
+		}
+		return false
+	}
+	var v T
+	if err := it.dec.DecodeStream(it.s, 0, unsafe.Pointer(&v)); err != nil {
+		it.err = it.s.LocateError(err)
+		return false
+	}
+	it.s.Reset()
+	it.value = v
+	return true
+}
+
+// Value returns the value decoded by the last call of Next.
+func (it *DecodeIter[T]) Value() T {
+	return it.value
+}
+
+// Err returns the first error that occurred during the iteration. EOF of the stream is not reported.
+func (it *DecodeIter[T]) Err() error {
+	return it.err
+}
@@ -0,0 +1,170 @@
+package json_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestUnmarshalAs(t *testing.T) {
+	type T struct {
+		A int    `json:"a"`
+		B string `json:"b"`
+	}
+	t.Run("struct", func(t *testing.T) {
+		v, err := json.UnmarshalAs[T]([]byte(`{"a": 1, "b": "hello"}`))
+		assertErr(t, err)
+		assertEq(t, "a", 1, v.A)
+		assertEq(t, "b", "hello", v.B)
+	})
+	t.Run("pointer", func(t *testing.T) {
+		v, err := json.UnmarshalAs[*T]([]byte(`{"a": 1}`))
+		assertErr(t, err)
+		assertEq(t, "a", 1, v.A)
+
+		v, err = json.UnmarshalAs[*T]([]byte(`null`))
+		assertErr(t, err)
+		if v != nil {
+			t.Fatal("expected nil")
+		}
+	})
+	t.Run("map", func(t *testing.T) {
+		v, err := json.UnmarshalAs[map[string]int]([]byte(`{"a": 1, "b": 2}`))
+		assertErr(t, err)
+		assertEq(t, "map", fmt.Sprint(map[string]int{"a": 1, "b": 2}), fmt.Sprint(v))
+	})
+	t.Run("interface", func(t *testing.T) {
+		v, err := json.UnmarshalAs[interface{}]([]byte(`[1, "a"]`), json.DecodeUseNumber())
+		assertErr(t, err)
+		assertEq(t, "interface", fmt.Sprintf("%#v", []interface{}{json.Number("1"), "a"}), fmt.Sprintf("%#v", v))
+	})
+	t.Run("error", func(t *testing.T) {
+		v, err := json.UnmarshalAs[T]([]byte(`{"a": "1"}`))
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		assertEq(t, "zero value", T{}, v)
+
+		if _, err := json.UnmarshalAs[T]([]byte(`{"a": 1} 1`)); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestMarshalTo(t *testing.T) {
+	type T struct {
+		A int    `json:"a"`
+		B string `json:"b"`
+	}
+	tests := []struct {
+		name     string
+		marshal  func([]byte) ([]byte, error)
+		expected string
+	}{
+		{
+			name:     "struct",
+			marshal:  func(b []byte) ([]byte, error) { return json.MarshalTo(b, T{A: 1, B: "<b>"}) },
+			expected: `{"a":1,"b":"\u003cb\u003e"}`,
+		},
+		{
+			name:     "pointer",
+			marshal:  func(b []byte) ([]byte, error) { return json.MarshalTo(b, &T{A: 1}) },
+			expected: `{"a":1,"b":""}`,
+		},
+		{
+			name:     "nil pointer",
+			marshal:  func(b []byte) ([]byte, error) { return json.MarshalTo[*T](b, nil) },
+			expected: `null`,
+		},
+		{
+			name:     "map",
+			marshal:  func(b []byte) ([]byte, error) { return json.MarshalTo(b, map[string]int{"b": 2, "a": 1}) },
+			expected: `{"a":1,"b":2}`,
+		},
+		{
+			name:     "interface",
+			marshal:  func(b []byte) ([]byte, error) { return json.MarshalTo[interface{}](b, []int{1, 2}) },
+			expected: `[1,2]`,
+		},
+		{
+			name:     "nil interface",
+			marshal:  func(b []byte) ([]byte, error) { return json.MarshalTo[interface{}](b, nil) },
+			expected: `null`,
+		},
+		{
+			name: "option",
+			marshal: func(b []byte) ([]byte, error) {
+				return json.MarshalTo(b, "<b>", json.DisableHTMLEscape())
+			},
+			expected: `"<b>"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.marshal([]byte("prefix:"))
+			assertErr(t, err)
+			assertEq(t, "marshal", "prefix:"+test.expected, string(got))
+		})
+	}
+}
+
+func TestEncodeTyped(t *testing.T) {
+	type T struct {
+		A int `json:"a"`
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	assertErr(t, json.EncodeTyped(enc, T{A: 1}))
+	assertErr(t, json.EncodeTyped[interface{}](enc, nil))
+	enc.SetIndent("", "  ")
+	assertErr(t, json.EncodeTyped(enc, &T{A: 2}))
+	assertEq(t, "encode", "{\"a\":1}\nnull\n{\n  \"a\": 2\n}\n", buf.String())
+}
+
+func TestDecodeIter(t *testing.T) {
+	type T struct {
+		A int `json:"a"`
+	}
+	t.Run("values", func(t *testing.T) {
+		iter := json.NewDecodeIter[T](strings.NewReader(`{"a": 1} {"a": 2}
+{"a": 3}
+`))
+		var got []int
+		for iter.Next() {
+			got = append(got, iter.Value().A)
+		}
+		assertErr(t, iter.Err())
+		assertEq(t, "values", fmt.Sprint([]int{1, 2, 3}), fmt.Sprint(got))
+	})
+	t.Run("fresh value", func(t *testing.T) {
+		iter := json.NewDecodeIter[map[string]int](strings.NewReader(`{"a": 1} {"b": 2}`))
+		var got []map[string]int
+		for iter.Next() {
+			got = append(got, iter.Value())
+		}
+		assertErr(t, iter.Err())
+		assertEq(t, "values", fmt.Sprint([]map[string]int{{"a": 1}, {"b": 2}}), fmt.Sprint(got))
+	})
+	t.Run("error", func(t *testing.T) {
+		iter := json.NewDecodeIter[T](strings.NewReader(`{"a": 1} {"a": "2"} {"a": 3}`))
+		var got []int
+		for iter.Next() {
+			got = append(got, iter.Value().A)
+		}
+		if iter.Err() == nil {
+			t.Fatal("expected error")
+		}
+		assertEq(t, "values", fmt.Sprint([]int{1}), fmt.Sprint(got))
+	})
+	t.Run("option", func(t *testing.T) {
+		iter := json.NewDecodeIter[T](strings.NewReader(`{"a": 1, "b": 2}`), json.DecodeDisallowUnknownFields())
+		for iter.Next() {
+		}
+		if iter.Err() == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
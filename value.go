@@ -0,0 +1,292 @@
+package json
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json/internal/decoder"
+	"github.com/goccy/go-json/internal/errors"
+	"github.com/goccy/go-json/internal/scanner"
+)
+
+// Value is a read-only view of a JSON value over raw bytes.
+// The document is validated once by ParseValue, and the members and elements are scanned on demand when they are accessed,
+// so the parts of the document that are not accessed are never decoded.
+// e.g.) extract a few fields from a large document without decoding the whole document.
+//
+// The values derived from a Value by Get, Index and iterators refer to the bytes passed to ParseValue without copying.
+type Value struct {
+	v decoder.Value
+}
+
+// ParseValue validates data and returns the view of the JSON value.
+// The whole data is validated up front, so the members and elements never fail to be scanned when they are accessed.
+//
+// ParseValue doesn't copy data, and the returned Value and the values derived from it refer to data.
+// So data must not be modified while they are used.
+// Only the number, boolean or null value at the top level is copied, because it isn't terminated by the closing character.
+func ParseValue(data []byte) (Value, error) {
+	if err := scanner.Validate(data); err != nil {
+		return Value{}, (&errors.Source{Buf: data, Line: 1}).Locate(err)
+	}
+	src := data
+	switch bytes.TrimLeft(data, " \t\r\n")[0] {
+	case '{', '[', '"':
+	default:
+		src = make([]byte, len(data)+1) // append nul byte to the end
+		copy(src, data)
+	}
+	v, _, err := decoder.NewValue(src)
+	if err != nil {
+		return Value{}, locateValueError(src, err)
+	}
+	return Value{v: v}, nil
+}
+
+// locateValueError sets the line and column of err in src that is data passed to ParseValue or its copy that ends with nul byte.
+func locateValueError(src []byte, err error) error {
+	if err == nil {
+		return nil
+	}
+	if len(src) > 0 && src[len(src)-1] == nul {
+		src = src[:len(src)-1]
+	}
+	return (&errors.Source{Buf: src, Line: 1}).Locate(err)
+}
+
+func (v Value) valid() error {
+	if !v.v.IsValid() {
+		return errors.ErrUnexpectedEndOfJSON("value", 0)
+	}
+	return nil
+}
+
+func (v Value) locate(err error) error {
+	return locateValueError(v.v.Buf(), err)
+}
+
+// Raw returns the raw bytes of the value. The returned bytes must not be modified.
+func (v Value) Raw() []byte {
+	if !v.v.IsValid() {
+		return nil
+	}
+	return v.v.Raw()
+}
+
+// Get returns the value by following the keys from the value.
+// A key selects the member of object by name, or the element of array by index.
+// If the object has duplicate names, the first one is used.
+// If the keys don't reference any value, Get returns *PointerError.
+func (v Value) Get(keys ...string) (Value, error) {
+	if err := v.valid(); err != nil {
+		return Value{}, err
+	}
+	cur := v.v
+	for i, key := range keys {
+		var (
+			child decoder.Value
+			found bool
+			err   error
+		)
+		if raw := cur.Raw(); raw[0] == '[' {
+			if idx, convErr := strconv.Atoi(key); convErr == nil {
+				child, found, err = cur.Index(idx)
+			}
+		} else {
+			child, found, err = cur.Member(key)
+		}
+		if err != nil {
+			return Value{}, v.locate(err)
+		}
+		if !found {
+			return Value{}, errors.ErrPointerNotFound(valuePointer(keys[:i+1]))
+		}
+		cur = child
+	}
+	return Value{v: cur}, nil
+}
+
+func valuePointer(keys []string) string {
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteByte('/')
+		b.WriteString(decoder.EscapePointerToken(key))
+	}
+	return b.String()
+}
+
+// Index returns the element of array by index.
+// If the array doesn't have the element, Index returns *PointerError.
+func (v Value) Index(i int) (Value, error) {
+	if err := v.valid(); err != nil {
+		return Value{}, err
+	}
+	child, found, err := v.v.Index(i)
+	if err != nil {
+		return Value{}, v.locate(err)
+	}
+	if !found {
+		return Value{}, errors.ErrPointerNotFound(valuePointer([]string{strconv.Itoa(i)}))
+	}
+	return Value{v: child}, nil
+}
+
+// String decodes the value as string. If the value is null, String returns the empty string.
+func (v Value) String() (string, error) {
+	if err := v.valid(); err != nil {
+		return "", err
+	}
+	s, err := v.v.DecodeString()
+	if err != nil {
+		return "", v.locate(err)
+	}
+	return s, nil
+}
+
+// Int decodes the value as int64. If the value is null, Int returns 0.
+func (v Value) Int() (int64, error) {
+	if err := v.valid(); err != nil {
+		return 0, err
+	}
+	i, err := v.v.DecodeInt()
+	if err != nil {
+		return 0, v.locate(err)
+	}
+	return i, nil
+}
+
+// Float decodes the value as float64. If the value is null, Float returns 0.
+func (v Value) Float() (float64, error) {
+	if err := v.valid(); err != nil {
+		return 0, err
+	}
+	f, err := v.v.DecodeFloat()
+	if err != nil {
+		return 0, v.locate(err)
+	}
+	return f, nil
+}
+
+// Bool decodes the value as bool. If the value is null, Bool returns false.
+func (v Value) Bool() (bool, error) {
+	if err := v.valid(); err != nil {
+		return false, err
+	}
+	b, err := v.v.DecodeBool()
+	if err != nil {
+		return false, v.locate(err)
+	}
+	return b, nil
+}
+
+// Unmarshal decodes the value into dst like Unmarshal.
+func (v Value) Unmarshal(dst interface{}, optFuncs ...DecodeOptionFunc) error {
+	if err := v.valid(); err != nil {
+		return err
+	}
+	return UnmarshalWithOption(v.v.Raw(), dst, optFuncs...)
+}
+
+// MarshalJSON returns the raw bytes of the value.
+func (v Value) MarshalJSON() ([]byte, error) {
+	if !v.v.IsValid() {
+		return []byte("null"), nil
+	}
+	return v.v.Raw(), nil
+}
+
+// UnmarshalJSON sets the view of data to the value.
+// It allows to decode the part of document lazily by the field of Value type.
+// data is copied because it may be reused by the decoder after UnmarshalJSON returns.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	parsed, err := ParseValue(append([]byte(nil), data...))
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// ObjectIter returns the iterator of the members of object.
+func (v Value) ObjectIter() (*ObjectIter, error) {
+	if err := v.valid(); err != nil {
+		return nil, err
+	}
+	iter, err := v.v.ObjectIter()
+	if err != nil {
+		return nil, v.locate(err)
+	}
+	return &ObjectIter{iter: iter, buf: v.v.Buf()}, nil
+}
+
+// ArrayIter returns the iterator of the elements of array.
+func (v Value) ArrayIter() (*ArrayIter, error) {
+	if err := v.valid(); err != nil {
+		return nil, err
+	}
+	iter, err := v.v.ArrayIter()
+	if err != nil {
+		return nil, v.locate(err)
+	}
+	return &ArrayIter{iter: iter, buf: v.v.Buf()}, nil
+}
+
+// ObjectIter iterates the members of object in document order.
+//
+//	iter, err := v.ObjectIter()
+//	if err != nil { ... }
+//	for iter.Next() {
+//		key, value := iter.Key(), iter.Value()
+//	}
+//	if err := iter.Err(); err != nil { ... }
+type ObjectIter struct {
+	iter *decoder.ValueIter
+	buf  []byte
+}
+
+// Next advances the iterator to the next member. It returns false at the end of the object or on error.
+func (it *ObjectIter) Next() bool {
+	return it.iter.Next()
+}
+
+// Key returns the name of the current member.
+func (it *ObjectIter) Key() string {
+	return string(it.iter.Key())
+}
+
+// Value returns the value of the current member.
+func (it *ObjectIter) Value() Value {
+	return Value{v: it.iter.Value()}
+}
+
+// Err returns the error that occurred while scanning the object.
+func (it *ObjectIter) Err() error {
+	return locateValueError(it.buf, it.iter.Err())
+}
+
+// ArrayIter iterates the elements of array in order.
+type ArrayIter struct {
+	iter *decoder.ValueIter
+	buf  []byte
+}
+
+// Next advances the iterator to the next element. It returns false at the end of the array or on error.
+func (it *ArrayIter) Next() bool {
+	return it.iter.Next()
+}
+
+// Index returns the index of the current element.
+func (it *ArrayIter) Index() int {
+	return it.iter.Index()
+}
+
+// Value returns the current element.
+func (it *ArrayIter) Value() Value {
+	return Value{v: it.iter.Value()}
+}
+
+// Err returns the error that occurred while scanning the array.
+func (it *ArrayIter) Err() error {
+	return locateValueError(it.buf, it.iter.Err())
+}
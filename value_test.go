@@ -0,0 +1,296 @@
+package json_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestValue(t *testing.T) {
+	src := []byte(`{
+  "id": 12345,
+  "name": "go\"json",
+  "price": 1.5,
+  "active": true,
+  "nothing": null,
+  "tags": ["a", "b", "c"],
+  "owner": {"id": 1, "name": "x"},
+  "id": 0
+}`)
+	v, err := json.ParseValue(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("get", func(t *testing.T) {
+		id, err := v.Get("id")
+		if err != nil {
+			t.Fatal(err)
+		}
+		i, err := id.Int()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "first duplicate", int64(12345), i)
+
+		name, err := v.Get("name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := name.String()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "string", `go"json`, s)
+		assertEq(t, "raw", `"go\"json"`, string(name.Raw()))
+
+		price, err := v.Get("price")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := price.Float()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "float", 1.5, f)
+
+		active, err := v.Get("active")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := active.Bool()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "bool", true, b)
+
+		tag, err := v.Get("tags", "2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err = tag.String()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "array element", "c", s)
+
+		ownerName, err := v.Get("owner", "name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "nested", `"x"`, string(ownerName.Raw()))
+
+		root, err := v.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "root", string(src), string(root.Raw()))
+	})
+	t.Run("index", func(t *testing.T) {
+		tags, err := v.Get("tags")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag, err := tags.Index(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "element", `"b"`, string(tag.Raw()))
+		var pointerErr *json.PointerError
+		if _, err := tags.Index(3); !errors.As(err, &pointerErr) {
+			t.Fatalf("expected PointerError but got %v", err)
+		}
+	})
+	t.Run("not found", func(t *testing.T) {
+		for _, keys := range [][]string{{"missing"}, {"tags", "3"}, {"tags", "x"}, {"owner", "missing"}} {
+			_, err := v.Get(keys...)
+			var pointerErr *json.PointerError
+			if !errors.As(err, &pointerErr) {
+				t.Fatalf("expected PointerError for %v but got %v", keys, err)
+			}
+		}
+		var typeErr *json.UnmarshalTypeError
+		if _, err := v.Get("owner", "id", "x"); !errors.As(err, &typeErr) {
+			t.Fatalf("expected UnmarshalTypeError but got %v", err)
+		}
+	})
+	t.Run("type error", func(t *testing.T) {
+		name, err := v.Get("name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var typeErr *json.UnmarshalTypeError
+		if _, err := name.Int(); !errors.As(err, &typeErr) {
+			t.Fatalf("expected UnmarshalTypeError but got %v", err)
+		}
+		if _, err := name.Bool(); !errors.As(err, &typeErr) {
+			t.Fatalf("expected UnmarshalTypeError but got %v", err)
+		}
+		price, err := v.Get("price")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := price.Int(); !errors.As(err, &typeErr) {
+			t.Fatalf("expected UnmarshalTypeError but got %v", err)
+		}
+		if _, err := price.ObjectIter(); !errors.As(err, &typeErr) {
+			t.Fatalf("expected UnmarshalTypeError but got %v", err)
+		}
+		nothing, err := v.Get("nothing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := nothing.String()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "null string", "", s)
+	})
+	t.Run("object iterator", func(t *testing.T) {
+		iter, err := v.ObjectIter()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for iter.Next() {
+			keys = append(keys, iter.Key())
+		}
+		if err := iter.Err(); err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "keys", `[id name price active nothing tags owner id]`, fmt.Sprint(keys))
+	})
+	t.Run("array iterator", func(t *testing.T) {
+		tags, err := v.Get("tags")
+		if err != nil {
+			t.Fatal(err)
+		}
+		iter, err := tags.ArrayIter()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var elems []string
+		for iter.Next() {
+			s, err := iter.Value().String()
+			if err != nil {
+				t.Fatal(err)
+			}
+			elems = append(elems, fmt.Sprintf("%d:%s", iter.Index(), s))
+		}
+		if err := iter.Err(); err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "elements", `[0:a 1:b 2:c]`, fmt.Sprint(elems))
+	})
+	t.Run("unmarshal subtree", func(t *testing.T) {
+		owner, err := v.Get("owner")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var dst struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}
+		if err := owner.Unmarshal(&dst); err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "id", 1, dst.ID)
+		assertEq(t, "name", "x", dst.Name)
+		// the view is not modified by decoding.
+		name, err := v.Get("name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := name.String()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "string after decoding", `go"json`, s)
+	})
+	t.Run("field", func(t *testing.T) {
+		var dst struct {
+			ID    int        `json:"id"`
+			Owner json.Value `json:"owner"`
+		}
+		if err := json.Unmarshal(src, &dst); err != nil {
+			t.Fatal(err)
+		}
+		name, err := dst.Owner.Get("name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "lazy field", `"x"`, string(name.Raw()))
+		encoded, err := json.Marshal(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "marshal", `{"id":0,"owner":{"id":1,"name":"x"}}`, string(encoded))
+	})
+}
+
+func TestValueError(t *testing.T) {
+	for _, src := range []string{``, `{"a": 1`, `{"a": 1} x`, `[1, 2`, `{"a":[1,2,,3]}`, `{"a":tru}`, `[1 2]`} {
+		if _, err := json.ParseValue([]byte(src)); err == nil {
+			t.Fatalf("expected error for %q", src)
+		}
+	}
+	t.Run("invalid member", func(t *testing.T) {
+		_, err := json.ParseValue([]byte("{\"a\": 1,\n \"b\" 2}"))
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("expected SyntaxError but got %v", err)
+		}
+		assertEq(t, "line", 2, syntaxErr.Line)
+	})
+	t.Run("zero value", func(t *testing.T) {
+		var v json.Value
+		if _, err := v.Get("a"); err == nil {
+			t.Fatal("expected error")
+		}
+		if v.Raw() != nil {
+			t.Fatal("expected nil")
+		}
+	})
+}
+
+func TestValueNoCopy(t *testing.T) {
+	for _, src := range []string{`{"a": [1, {"b": "c"}], "d": 2.5}`, ` ["x", true, null] `, `"s"`, `12`, `true`, ` null `} {
+		// the capacity is the same as the length to detect reading beyond data.
+		data := append(make([]byte, 0, len(src)), src...)
+		v, err := json.ParseValue(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "raw", strings.TrimSpace(src), string(v.Raw()))
+		if v.Raw()[0] == '{' {
+			d, err := v.Get("d")
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := d.Float()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEq(t, "last member", 2.5, f)
+		}
+	}
+	t.Run("alias", func(t *testing.T) {
+		data := []byte(`{"a": "b"}`)
+		v, err := json.ParseValue(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, err := v.Get("a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data[7] = 'c'
+		s, err := a.String()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "refers to data", "c", s)
+	})
+}